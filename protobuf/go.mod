@@ -1,9 +1,14 @@
 module github.com/golang/protobuf/v2
 
+go 1.21.6
+
 require (
 	github.com/golang/protobuf v1.2.0
 	github.com/google/go-cmp v0.2.0
+	golang.org/x/tools v0.0.0-20180904205237-0aa4b8830f48
+)
+
+require (
 	golang.org/x/net v0.0.0-20180821023952-922f4815f713 // indirect
 	golang.org/x/sync v0.0.0-20180314180146-1d60e4601c6f // indirect
-	golang.org/x/tools v0.0.0-20180904205237-0aa4b8830f48
 )
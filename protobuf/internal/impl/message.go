@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/golang/protobuf/v2/internal/encoding/wire"
 	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
 	ptype "github.com/golang/protobuf/v2/reflect/prototype"
 )
@@ -34,6 +35,14 @@ type MessageType struct {
 	// TODO: Split fields into dense and sparse maps similar to the current
 	// table-driven implementation in v1?
 	fields map[pref.FieldNumber]*fieldInfo
+
+	// extensionMap returns the extension field storage of a message.
+	// It is nil if the message does not support extension fields.
+	extensionMap func(pointer) *ExtensionFields
+
+	// unknownBytes returns the unknown field storage of a message.
+	// It is nil if the message does not preserve unknown fields.
+	unknownBytes func(pointer) *[]byte
 }
 
 // init lazily initializes the MessageType upon first use and
@@ -52,7 +61,8 @@ func (mi *MessageType) init(p interface{}) {
 		// Derive the message descriptor if unspecified.
 		md := mi.Desc
 		if md == nil {
-			// TODO: derive the message type from the Go struct type
+			md = deriveMessageDescriptor(t.Elem())
+			mi.Desc = md
 		}
 
 		// Initialize the Go message type wrapper if the Go type does not
@@ -155,6 +165,30 @@ fieldLoop:
 		}
 		mi.fields[fd.Number()] = &fi
 	}
+
+	if fs, ok := special["XXX_InternalExtensions"]; ok {
+		mi.generateExtensionFuncs(fs)
+	} else if fs, ok := special["XXX_extensions"]; ok {
+		mi.generateExtensionFuncs(fs)
+	}
+	if fs, ok := special["XXX_unrecognized"]; ok {
+		mi.generateUnknownFuncs(fs)
+	}
+}
+
+var extensionFieldsType = reflect.TypeOf(ExtensionFields(nil))
+
+// generateExtensionFuncs generates the accessor for the extension field
+// storage. It takes in a reflect.StructField for the struct field holding
+// the extension fields, which must be of type ExtensionFields.
+func (mi *MessageType) generateExtensionFuncs(fs reflect.StructField) {
+	if fs.Type != extensionFieldsType {
+		panic(fmt.Sprintf("invalid type: got %v, want %v", fs.Type, extensionFieldsType))
+	}
+	fieldOffset := offsetOf(fs)
+	mi.extensionMap = func(p pointer) *ExtensionFields {
+		return p.apply(fieldOffset).asType(fs.Type).Interface().(*ExtensionFields)
+	}
 }
 
 func (mi *MessageType) MessageOf(p interface{}) pref.Message {
@@ -228,27 +262,51 @@ func (m *message) ProtoMutable() {}
 
 type knownFields messageDataType
 
+// extensions returns the extension field storage of the message,
+// or nil if the message does not support extension fields.
+func (fs *knownFields) extensions() *ExtensionFields {
+	if fs.mi.extensionMap == nil {
+		return nil
+	}
+	return fs.mi.extensionMap(fs.p)
+}
+
 func (fs *knownFields) Len() (cnt int) {
 	for _, fi := range fs.mi.fields {
 		if fi.has(fs.p) {
 			cnt++
 		}
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		for _, x := range *m {
+			if x.isPopulated() {
+				cnt++
+			}
+		}
+	}
 	return cnt
 }
 func (fs *knownFields) Has(n pref.FieldNumber) bool {
 	if fi := fs.mi.fields[n]; fi != nil {
 		return fi.has(fs.p)
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		return (*m)[n].isPopulated()
+	}
 	return false
 }
 func (fs *knownFields) Get(n pref.FieldNumber) pref.Value {
 	if fi := fs.mi.fields[n]; fi != nil {
 		return fi.get(fs.p)
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		if x, ok := (*m)[n]; ok {
+			if x.V.IsValid() {
+				return x.V
+			}
+			return extensionDefault(x.T)
+		}
+	}
 	return pref.Value{}
 }
 func (fs *knownFields) Set(n pref.FieldNumber, v pref.Value) {
@@ -256,7 +314,13 @@ func (fs *knownFields) Set(n pref.FieldNumber, v pref.Value) {
 		fi.set(fs.p, v)
 		return
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		if x, ok := (*m)[n]; ok {
+			x.V = v
+			(*m)[n] = x
+			return
+		}
+	}
 	panic(fmt.Sprintf("invalid field: %d", n))
 }
 func (fs *knownFields) Clear(n pref.FieldNumber) {
@@ -264,14 +328,35 @@ func (fs *knownFields) Clear(n pref.FieldNumber) {
 		fi.clear(fs.p)
 		return
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		if x, ok := (*m)[n]; ok {
+			x.V = pref.Value{}
+			(*m)[n] = x
+			return
+		}
+	}
 	panic(fmt.Sprintf("invalid field: %d", n))
 }
 func (fs *knownFields) Mutable(n pref.FieldNumber) pref.Mutable {
 	if fi := fs.mi.fields[n]; fi != nil {
 		return fi.mutable(fs.p)
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		if x, ok := (*m)[n]; ok {
+			if !x.V.IsValid() {
+				x.V = extensionNew(x.T)
+				(*m)[n] = x
+			}
+			switch {
+			case x.T.Cardinality() == pref.Repeated:
+				return x.V.Vector()
+			case x.T.Kind() == pref.MessageKind || x.T.Kind() == pref.GroupKind:
+				return x.V.Message()
+			default:
+				panic(fmt.Sprintf("invalid mutable call: %d", n))
+			}
+		}
+	}
 	panic(fmt.Sprintf("invalid field: %d", n))
 }
 func (fs *knownFields) Range(f func(pref.FieldNumber, pref.Value) bool) {
@@ -282,25 +367,207 @@ func (fs *knownFields) Range(f func(pref.FieldNumber, pref.Value) bool) {
 			}
 		}
 	}
-	// TODO: Handle extension fields.
+	if m := fs.extensions(); m != nil {
+		for n, x := range *m {
+			if x.isPopulated() {
+				if !f(n, x.V) {
+					return
+				}
+			}
+		}
+	}
 }
 func (fs *knownFields) ExtensionTypes() pref.ExtensionFieldTypes {
 	return (*extensionFieldTypes)(fs)
 }
 
-type extensionFieldTypes messageDataType // TODO
+type extensionFieldTypes messageDataType
+
+// extensions returns the extension field storage of the message,
+// or nil if the message does not support extension fields.
+func (fs *extensionFieldTypes) extensions() *ExtensionFields {
+	if fs.mi.extensionMap == nil {
+		return nil
+	}
+	return fs.mi.extensionMap(fs.p)
+}
+
+func (fs *extensionFieldTypes) Len() int {
+	if m := fs.extensions(); m != nil {
+		return len(*m)
+	}
+	return 0
+}
+func (fs *extensionFieldTypes) Register(xt pref.ExtensionType) {
+	m := fs.extensions()
+	if m == nil {
+		panic(fmt.Sprintf("message %v does not support extension fields", fs.mi.Desc.FullName()))
+	}
+	if got, want := xt.ExtendedType().FullName(), fs.mi.Desc.FullName(); got != want {
+		panic(fmt.Sprintf("extended type mismatch: got %v, want %v", got, want))
+	}
+	if !fs.mi.Desc.ExtensionRanges().Has(xt.Number()) {
+		panic(fmt.Sprintf("invalid extension field number: %d", xt.Number()))
+	}
+	if _, ok := (*m)[xt.Number()]; ok || fs.ByName(xt.FullName()) != nil {
+		panic(fmt.Sprintf("extension already registered: %v", xt.FullName()))
+	}
+	if *m == nil {
+		*m = make(ExtensionFields)
+	}
+	(*m)[xt.Number()] = ExtensionField{T: xt}
+}
+func (fs *extensionFieldTypes) Remove(xt pref.ExtensionType) {
+	m := fs.extensions()
+	if m == nil {
+		return
+	}
+	x, ok := (*m)[xt.Number()]
+	if !ok || x.T != xt {
+		return
+	}
+	if x.V.IsValid() {
+		panic(fmt.Sprintf("value for extension still populated: %v", xt.FullName()))
+	}
+	delete(*m, xt.Number())
+}
+func (fs *extensionFieldTypes) ByNumber(n pref.FieldNumber) pref.ExtensionType {
+	if m := fs.extensions(); m != nil {
+		if x, ok := (*m)[n]; ok {
+			return x.T
+		}
+	}
+	return nil
+}
+func (fs *extensionFieldTypes) ByName(s pref.FullName) pref.ExtensionType {
+	if m := fs.extensions(); m != nil {
+		for _, x := range *m {
+			if x.T.FullName() == s {
+				return x.T
+			}
+		}
+	}
+	return nil
+}
+func (fs *extensionFieldTypes) Range(f func(pref.ExtensionType) bool) {
+	if m := fs.extensions(); m != nil {
+		for _, x := range *m {
+			if !f(x.T) {
+				return
+			}
+		}
+	}
+}
+
+// generateUnknownFuncs generates the accessor for the unknown field storage.
+// It takes in a reflect.StructField for the struct field holding the raw
+// bytes of the unknown fields, which must be of type []byte.
+func (mi *MessageType) generateUnknownFuncs(fs reflect.StructField) {
+	if fs.Type != bytesType {
+		panic(fmt.Sprintf("invalid type: got %v, want %v", fs.Type, bytesType))
+	}
+	fieldOffset := offsetOf(fs)
+	mi.unknownBytes = func(p pointer) *[]byte {
+		return p.apply(fieldOffset).asType(fs.Type).Interface().(*[]byte)
+	}
+}
 
-func (fs *extensionFieldTypes) Len() int                                     { return 0 }
-func (fs *extensionFieldTypes) Register(pref.ExtensionType)                  { return }
-func (fs *extensionFieldTypes) Remove(pref.ExtensionType)                    { return }
-func (fs *extensionFieldTypes) ByNumber(pref.FieldNumber) pref.ExtensionType { return nil }
-func (fs *extensionFieldTypes) ByName(pref.FullName) pref.ExtensionType      { return nil }
-func (fs *extensionFieldTypes) Range(f func(pref.ExtensionType) bool)        { return }
+type unknownFields messageDataType
 
-type unknownFields messageDataType // TODO
+// bytes returns the raw bytes of the unknown fields of the message,
+// or nil if the message does not preserve unknown fields.
+func (fs *unknownFields) bytes() *[]byte {
+	if fs.mi.unknownBytes == nil {
+		return nil
+	}
+	return fs.mi.unknownBytes(fs.p)
+}
 
-func (fs *unknownFields) Len() int                                            { return 0 }
-func (fs *unknownFields) Get(n pref.FieldNumber) pref.RawFields               { return nil }
-func (fs *unknownFields) Set(n pref.FieldNumber, b pref.RawFields)            { return }
-func (fs *unknownFields) Range(f func(pref.FieldNumber, pref.RawFields) bool) { return }
-func (fs *unknownFields) IsSupported() bool                                   { return false }
+func (fs *unknownFields) Len() (cnt int) {
+	b := fs.bytes()
+	if b == nil {
+		return 0
+	}
+	seen := map[pref.FieldNumber]bool{}
+	forEachUnknownField(*b, func(n pref.FieldNumber, raw pref.RawFields) {
+		if !seen[n] {
+			seen[n] = true
+			cnt++
+		}
+	})
+	return cnt
+}
+func (fs *unknownFields) Get(n pref.FieldNumber) (raw pref.RawFields) {
+	b := fs.bytes()
+	if b == nil {
+		return nil
+	}
+	forEachUnknownField(*b, func(n2 pref.FieldNumber, raw2 pref.RawFields) {
+		if n2 == n {
+			raw = append(raw, raw2...)
+		}
+	})
+	return raw
+}
+func (fs *unknownFields) Set(n pref.FieldNumber, raw pref.RawFields) {
+	b := fs.bytes()
+	if b == nil {
+		return
+	}
+	if len(raw) > 0 {
+		if !raw.IsValid() {
+			panic(fmt.Sprintf("invalid raw fields for field number: %d", n))
+		}
+		forEachUnknownField(raw, func(n2 pref.FieldNumber, _ pref.RawFields) {
+			if n2 != n {
+				panic(fmt.Sprintf("mismatching field number: got %d, want %d", n2, n))
+			}
+		})
+	}
+	// Preserve the relative ordering of the other fields.
+	var out []byte
+	forEachUnknownField(*b, func(n2 pref.FieldNumber, raw2 pref.RawFields) {
+		if n2 != n {
+			out = append(out, raw2...)
+		}
+	})
+	out = append(out, raw...)
+	*b = out
+}
+func (fs *unknownFields) Range(f func(pref.FieldNumber, pref.RawFields) bool) {
+	b := fs.bytes()
+	if b == nil {
+		return
+	}
+	// Coalesce fields with the same number in order of first occurrence.
+	var nums []pref.FieldNumber
+	raws := map[pref.FieldNumber]pref.RawFields{}
+	forEachUnknownField(*b, func(n pref.FieldNumber, raw pref.RawFields) {
+		if _, ok := raws[n]; !ok {
+			nums = append(nums, n)
+		}
+		raws[n] = append(raws[n], raw...)
+	})
+	for _, n := range nums {
+		if !f(n, raws[n]) {
+			return
+		}
+	}
+}
+func (fs *unknownFields) IsSupported() bool {
+	return fs.mi.unknownBytes != nil
+}
+
+// forEachUnknownField calls f for every field in b, where each call is
+// given the field number and the raw bytes of that single field.
+// It stops iterating upon encountering malformed wire data.
+func forEachUnknownField(b []byte, f func(pref.FieldNumber, pref.RawFields)) {
+	for len(b) > 0 {
+		num, _, n := wire.ConsumeField(b)
+		if n < 0 {
+			return
+		}
+		f(pref.FieldNumber(num), pref.RawFields(b[:n:n]))
+		b = b[n:]
+	}
+}
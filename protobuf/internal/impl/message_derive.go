@@ -0,0 +1,335 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package impl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+	ptype "github.com/golang/protobuf/v2/reflect/prototype"
+)
+
+// deriveMessageDescriptor derives a MessageDescriptor for a Go struct type
+// from the protobuf struct tags alone, so that hand-written Go structs can
+// be used with the reflection API without manually building a descriptor.
+//
+// The derivation is necessarily lossy since struct tags do not carry the
+// full information of the original proto file. In particular, default values
+// for bytes fields and declaration options are not recovered.
+func deriveMessageDescriptor(t reflect.Type) pref.MessageDescriptor {
+	return newDeriver().derive(t)
+}
+
+// deriver tracks the struct types currently being derived so that
+// recursive message types resolve as placeholders instead of recursing
+// without bound.
+type deriver struct {
+	inProgress map[reflect.Type]pref.FullName
+}
+
+func newDeriver() *deriver {
+	return &deriver{inProgress: map[reflect.Type]pref.FullName{}}
+}
+
+func (d *deriver) derive(t reflect.Type) pref.MessageDescriptor {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("got %v, want struct kind", t))
+	}
+	name := pref.FullName(t.Name())
+	d.inProgress[t] = name
+	defer delete(d.inProgress, t)
+
+	m := ptype.StandaloneMessage{
+		Syntax:   deriveSyntax(t),
+		FullName: name,
+	}
+	oneofNames := map[pref.Name]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag := f.Tag.Get("protobuf"); tag != "" {
+			m.Fields = append(m.Fields, d.deriveField(name, f.Name, f.Type, tag, ""))
+			continue
+		}
+		if s := f.Tag.Get("protobuf_oneof"); s != "" {
+			oneofNames[pref.Name(s)] = true
+			m.Oneofs = append(m.Oneofs, ptype.Oneof{Name: pref.Name(s)})
+			for _, ot := range oneofWrappers(t) {
+				f := ot.Field(0)
+				if tag := f.Tag.Get("protobuf"); tag != "" {
+					m.Fields = append(m.Fields, d.deriveField(name, f.Name, f.Type, tag, pref.Name(s)))
+				}
+			}
+		}
+	}
+	md, err := ptype.NewMessage(&m)
+	if err != nil {
+		panic(fmt.Sprintf("invalid derived message %v: %v", name, err))
+	}
+	return md
+}
+
+// deriveField derives a field declaration from the Go type and struct tag of
+// a single struct field (or oneof wrapper field).
+func (d *deriver) deriveField(parent pref.FullName, goName string, t reflect.Type, tag string, oneof pref.Name) ptype.Field {
+	var f ptype.Field
+	f.OneofName = oneof
+
+	var wire, def, enum string
+	ss := strings.Split(tag, ",")
+	if strings.Trim(ss[0], "0123456789") != "" {
+		wire = ss[0]
+		ss = ss[1:]
+	}
+	if len(ss) > 0 {
+		n, _ := strconv.ParseUint(ss[0], 10, 32)
+		f.Number = pref.FieldNumber(n)
+		ss = ss[1:]
+	}
+	f.Cardinality = pref.Optional
+	for _, s := range ss {
+		switch {
+		case s == "req":
+			f.Cardinality = pref.Required
+		case s == "rep":
+			f.Cardinality = pref.Repeated
+		case s == "packed":
+			f.IsPacked = true
+		case strings.HasPrefix(s, "name="):
+			f.Name = pref.Name(strings.TrimPrefix(s, "name="))
+		case strings.HasPrefix(s, "json="):
+			f.JSONName = strings.TrimPrefix(s, "json=")
+		case strings.HasPrefix(s, "enum="):
+			enum = strings.TrimPrefix(s, "enum=")
+		case strings.HasPrefix(s, "def="):
+			def = strings.TrimPrefix(s, "def=")
+		}
+	}
+	if f.Name == "" {
+		f.Name = pref.Name(snakeCase(goName))
+	}
+
+	switch t.Kind() {
+	case reflect.Map:
+		f.Cardinality = pref.Repeated
+		f.Kind = pref.MessageKind
+		f.MessageType = d.deriveMapEntry(parent, f.Name, t)
+		return f
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			f.Cardinality = pref.Repeated
+			t = t.Elem()
+		}
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			t = t.Elem()
+		}
+	}
+
+	if enum != "" {
+		f.Kind = pref.EnumKind
+		f.EnumType = ptype.PlaceholderEnum(pref.FullName(enum))
+		return f
+	}
+	f.Kind = deriveKind(t, wire)
+	switch f.Kind {
+	case pref.MessageKind, pref.GroupKind:
+		f.MessageType = d.deriveMessageType(t)
+	default:
+		if def != "" {
+			f.Default = parseTagDefault(def, f.Kind)
+		}
+	}
+	return f
+}
+
+// deriveMessageType resolves the message descriptor for a field of type t,
+// which must be a pointer to a struct. A placeholder is used for message
+// types that are currently being derived further up the stack.
+func (d *deriver) deriveMessageType(t reflect.Type) pref.MessageDescriptor {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name, ok := d.inProgress[t]; ok {
+		return ptype.PlaceholderMessage(name)
+	}
+	return d.derive(t)
+}
+
+// deriveMapEntry derives the synthetic map entry message for a map field.
+func (d *deriver) deriveMapEntry(parent pref.FullName, name pref.Name, t reflect.Type) pref.MessageDescriptor {
+	keyField := ptype.Field{Name: "key", Number: 1, Cardinality: pref.Optional, Kind: deriveKind(t.Key(), "")}
+	valField := ptype.Field{Name: "value", Number: 2, Cardinality: pref.Optional, Kind: deriveKind(t.Elem(), "")}
+	if valField.Kind == pref.MessageKind {
+		valField.MessageType = d.deriveMessageType(t.Elem())
+	}
+	md, err := ptype.NewMessage(&ptype.StandaloneMessage{
+		Syntax:     pref.Proto2,
+		FullName:   parent.Append(pref.Name(camelCase(string(name)) + "Entry")),
+		IsMapEntry: true,
+		Fields:     []ptype.Field{keyField, valField},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("invalid derived map entry for %v: %v", name, err))
+	}
+	return md
+}
+
+// deriveSyntax determines the syntax of a message from its struct fields.
+// A message is assumed to be proto2 if any scalar field uses pointer
+// presence; otherwise it is assumed to be proto3.
+func deriveSyntax(t reflect.Type) pref.Syntax {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.Contains(f.Tag.Get("protobuf"), "proto3") {
+			return pref.Proto3
+		}
+		if f.Tag.Get("protobuf") != "" && f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() != reflect.Struct {
+			return pref.Proto2
+		}
+	}
+	return pref.Proto3
+}
+
+// deriveKind matches the Go type and the wire encoding from the struct tag
+// with the protobuf kind. The type t must already be dereferenced.
+func deriveKind(t reflect.Type, wire string) pref.Kind {
+	switch t.Kind() {
+	case reflect.Bool:
+		return pref.BoolKind
+	case reflect.Int32:
+		switch wire {
+		case "zigzag32":
+			return pref.Sint32Kind
+		case "fixed32":
+			return pref.Sfixed32Kind
+		}
+		return pref.Int32Kind
+	case reflect.Int64:
+		switch wire {
+		case "zigzag64":
+			return pref.Sint64Kind
+		case "fixed64":
+			return pref.Sfixed64Kind
+		}
+		return pref.Int64Kind
+	case reflect.Uint32:
+		if wire == "fixed32" {
+			return pref.Fixed32Kind
+		}
+		return pref.Uint32Kind
+	case reflect.Uint64:
+		if wire == "fixed64" {
+			return pref.Fixed64Kind
+		}
+		return pref.Uint64Kind
+	case reflect.Float32:
+		return pref.FloatKind
+	case reflect.Float64:
+		return pref.DoubleKind
+	case reflect.String:
+		// The bytes wire encoding cannot distinguish between the string and
+		// bytes kinds; trust the Go type instead.
+		return pref.StringKind
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return pref.BytesKind
+		}
+	case reflect.Ptr, reflect.Struct:
+		if wire == "group" {
+			return pref.GroupKind
+		}
+		return pref.MessageKind
+	}
+	panic(fmt.Sprintf("invalid Go type %v for tag %q", t, wire))
+}
+
+// parseTagDefault parses the def= tag option for scalar kinds.
+// Unsupported defaults (e.g., escaped bytes literals) are ignored.
+func parseTagDefault(s string, k pref.Kind) pref.Value {
+	switch k {
+	case pref.BoolKind:
+		if v, err := strconv.ParseBool(s); err == nil {
+			return pref.ValueOf(v)
+		}
+	case pref.Int32Kind, pref.Sint32Kind, pref.Sfixed32Kind:
+		if v, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return pref.ValueOf(int32(v))
+		}
+	case pref.Int64Kind, pref.Sint64Kind, pref.Sfixed64Kind:
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return pref.ValueOf(v)
+		}
+	case pref.Uint32Kind, pref.Fixed32Kind:
+		if v, err := strconv.ParseUint(s, 10, 32); err == nil {
+			return pref.ValueOf(uint32(v))
+		}
+	case pref.Uint64Kind, pref.Fixed64Kind:
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return pref.ValueOf(v)
+		}
+	case pref.FloatKind:
+		if v, err := strconv.ParseFloat(s, 32); err == nil {
+			return pref.ValueOf(float32(v))
+		}
+	case pref.DoubleKind:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return pref.ValueOf(v)
+		}
+	case pref.StringKind:
+		return pref.ValueOf(s)
+	}
+	return pref.Value{}
+}
+
+// oneofWrappers returns the wrapper struct types for the oneof fields of t.
+func oneofWrappers(t reflect.Type) (ots []reflect.Type) {
+	fn, ok := reflect.PtrTo(t).MethodByName("XXX_OneofFuncs")
+	if !ok {
+		return nil
+	}
+	vs := fn.Func.Call([]reflect.Value{reflect.New(fn.Type.In(0)).Elem()})[3]
+	for _, v := range vs.Interface().([]interface{}) {
+		ots = append(ots, reflect.TypeOf(v).Elem())
+	}
+	return ots
+}
+
+// snakeCase converts a CamelCase identifier to snake_case.
+func snakeCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			if i > 0 {
+				b = append(b, '_')
+			}
+			c += 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// camelCase converts a snake_case identifier to CamelCase.
+func camelCase(s string) string {
+	var b []byte
+	up := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' {
+			up = true
+			continue
+		}
+		if up && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		up = false
+		b = append(b, c)
+	}
+	return string(b)
+}
@@ -0,0 +1,113 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package impl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+type (
+	DeriveMessage struct {
+		Enabled *bool                 `protobuf:"varint,1,opt,name=enabled,def=1"`
+		Names   []string              `protobuf:"bytes,2,rep,name=names"`
+		Score   *int32                `protobuf:"zigzag32,3,opt,name=score"`
+		Attrs   map[string]int64      `protobuf:"bytes,4,rep,name=attrs"`
+		Child   *DeriveMessage        `protobuf:"bytes,5,opt,name=child"`
+		Union   isDeriveMessage_Union `protobuf_oneof:"union"`
+	}
+	isDeriveMessage_Union interface {
+		isDeriveMessage_Union()
+	}
+	DeriveMessage_Str struct {
+		Str string `protobuf:"bytes,6,opt,name=str,oneof"`
+	}
+)
+
+func (*DeriveMessage) XXX_OneofFuncs() (func(proto.Message, *proto.Buffer) error, func(proto.Message, int, int, *proto.Buffer) (bool, error), func(proto.Message) int, []interface{}) {
+	return nil, nil, nil, []interface{}{
+		(*DeriveMessage_Str)(nil),
+	}
+}
+func (*DeriveMessage_Str) isDeriveMessage_Union() {}
+
+func TestDeriveMessageDescriptor(t *testing.T) {
+	md := deriveMessageDescriptor(reflect.TypeOf(DeriveMessage{}))
+	if got, want := md.FullName(), pref.FullName("DeriveMessage"); got != want {
+		t.Errorf("FullName() = %v, want %v", got, want)
+	}
+	if got, want := md.Syntax(), pref.Proto2; got != want {
+		t.Errorf("Syntax() = %v, want %v", got, want)
+	}
+	if got, want := md.Fields().Len(), 6; got != want {
+		t.Fatalf("Fields().Len() = %d, want %d", got, want)
+	}
+
+	fd := md.Fields().ByNumber(1)
+	if fd.Name() != "enabled" || fd.Kind() != pref.BoolKind || !fd.Default().Bool() {
+		t.Errorf("field 1 = (%v, %v, %v), want (enabled, bool, default true)", fd.Name(), fd.Kind(), fd.Default())
+	}
+	fd = md.Fields().ByNumber(2)
+	if fd.Cardinality() != pref.Repeated || fd.Kind() != pref.StringKind {
+		t.Errorf("field 2 = (%v, %v), want (repeated, string)", fd.Cardinality(), fd.Kind())
+	}
+	if fd := md.Fields().ByNumber(3); fd.Kind() != pref.Sint32Kind {
+		t.Errorf("field 3 kind = %v, want sint32", fd.Kind())
+	}
+
+	fd = md.Fields().ByNumber(4)
+	if !fd.IsMap() {
+		t.Fatalf("field 4 IsMap() = false, want true")
+	}
+	e := fd.MessageType()
+	if got, want := e.FullName(), pref.FullName("DeriveMessage.AttrsEntry"); got != want {
+		t.Errorf("map entry name = %v, want %v", got, want)
+	}
+	if e.Fields().ByNumber(1).Kind() != pref.StringKind || e.Fields().ByNumber(2).Kind() != pref.Int64Kind {
+		t.Errorf("map entry kinds = (%v, %v), want (string, int64)",
+			e.Fields().ByNumber(1).Kind(), e.Fields().ByNumber(2).Kind())
+	}
+
+	// Recursive message references resolve back to the message itself.
+	fd = md.Fields().ByNumber(5)
+	if fd.Kind() != pref.MessageKind {
+		t.Errorf("field 5 kind = %v, want message", fd.Kind())
+	}
+	if got, want := fd.MessageType().FullName(), pref.FullName("DeriveMessage"); got != want {
+		t.Errorf("field 5 message type = %v, want %v", got, want)
+	}
+
+	fd = md.Fields().ByNumber(6)
+	if fd.OneofType() == nil || fd.OneofType().Name() != "union" || fd.Kind() != pref.StringKind {
+		t.Errorf("field 6 not derived as member of oneof union")
+	}
+	if md.Oneofs().Len() != 1 {
+		t.Errorf("Oneofs().Len() = %d, want 1", md.Oneofs().Len())
+	}
+}
+
+func TestDerivedMessageType(t *testing.T) {
+	type DerivedScalars struct {
+		Bool *bool   `protobuf:"1"`
+		Str  *string `protobuf:"2"`
+	}
+
+	// With no explicit descriptor, the descriptor is derived from the tags.
+	mi := MessageType{}
+	fs := mi.MessageOf(&DerivedScalars{}).KnownFields()
+	if mi.Desc == nil || mi.Desc.FullName() != "DerivedScalars" {
+		t.Fatalf("descriptor not derived from Go struct tags")
+	}
+	fs.Set(1, V(true))
+	if !fs.Has(1) || !fs.Get(1).Bool() {
+		t.Errorf("Get(1) = %v, want true", fs.Get(1))
+	}
+	if fs.Has(2) {
+		t.Errorf("Has(2) = true, want false")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package impl
+
+import (
+	"reflect"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+// ExtensionFields is the Go representation for the extension fields of a
+// message. Messages that support extensions store this map in a struct field
+// named XXX_InternalExtensions (or XXX_extensions in older generated code).
+type ExtensionFields map[pref.FieldNumber]ExtensionField
+
+// ExtensionField is a registered extension field, pairing the extension type
+// with its value. The value is invalid while the extension is registered
+// with the message but not populated.
+type ExtensionField struct {
+	T pref.ExtensionType
+	V pref.Value
+}
+
+// isPopulated reports whether the extension field has a populated value.
+// A repeated extension is populated only if it is non-empty.
+func (x ExtensionField) isPopulated() bool {
+	if !x.V.IsValid() {
+		return false
+	}
+	if x.T.Cardinality() == pref.Repeated {
+		return x.V.Vector().Len() > 0
+	}
+	return true
+}
+
+// extensionDefault returns the default value to report for an unpopulated
+// extension field. An unpopulated repeated extension reports an empty,
+// detached vector.
+func extensionDefault(xt pref.ExtensionType) pref.Value {
+	if xt.Cardinality() == pref.Repeated {
+		return extensionNew(xt)
+	}
+	return xt.Default()
+}
+
+// extensionNew returns a freshly allocated value for the extension field.
+func extensionNew(xt pref.ExtensionType) pref.Value {
+	if xt.Cardinality() == pref.Repeated {
+		// GoNew returns *[]T for repeated extension fields.
+		rv := reflect.ValueOf(xt.GoNew()).Elem()
+		conv := matchGoTypePBKind(rv.Type().Elem(), xt.Kind())
+		return pref.ValueOf(vectorReflect{rv, conv})
+	}
+	return xt.ValueOf(xt.GoNew())
+}
@@ -96,7 +96,7 @@ func fieldInfoForOneof(fd pref.FieldDescriptor, fs reflect.StructField, ot refle
 				pv := pref.ValueOf(conv.newMessage())
 				rv.Set(conv.toGo(pv))
 			}
-			return rv.Interface().(pref.Message)
+			return conv.toPB(rv).Message()
 		},
 	}
 }
@@ -180,7 +180,7 @@ func (ms mapReflect) Mutable(k pref.MapKey) pref.Mutable {
 		rv = ms.valConv.toGo(pv)
 		ms.v.SetMapIndex(rk, rv)
 	}
-	return rv.Interface().(pref.Message)
+	return ms.valConv.toPB(rv).Message()
 }
 func (ms mapReflect) Range(f func(pref.MapKey, pref.Value) bool) {
 	for _, k := range ms.v.MapKeys() {
@@ -254,13 +254,13 @@ func (vs vectorReflect) Mutable(i int) pref.Mutable {
 		pv := pref.ValueOf(vs.conv.newMessage())
 		rv.Set(vs.conv.toGo(pv))
 	}
-	return rv.Interface().(pref.Message)
+	return vs.conv.toPB(rv).Message()
 }
 func (vs vectorReflect) MutableAppend() pref.Mutable {
 	// MutableAppend is only valid for messages and panics for other kinds.
 	pv := pref.ValueOf(vs.conv.newMessage())
 	vs.v.Set(reflect.Append(vs.v, vs.conv.toGo(pv)))
-	return vs.v.Index(vs.Len() - 1).Interface().(pref.Message)
+	return vs.conv.toPB(vs.v.Index(vs.Len() - 1)).Message()
 }
 func (vs vectorReflect) Truncate(i int) {
 	vs.v.Set(vs.v.Slice(0, i))
@@ -349,8 +349,42 @@ func fieldInfoForScalar(fd pref.FieldDescriptor, fs reflect.StructField) fieldIn
 }
 
 func fieldInfoForMessage(fd pref.FieldDescriptor, fs reflect.StructField) fieldInfo {
-	// TODO: support vector fields.
-	panic(fmt.Sprintf("invalid field: %v", fd))
+	ft := fs.Type
+	if ft.Kind() != reflect.Ptr || ft.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("invalid type: got %v, want pointer to struct kind", ft))
+	}
+	conv := matchGoTypePBKind(ft, fd.Kind())
+	fieldOffset := offsetOf(fs)
+	// TODO: Implement unsafe fast path?
+	return fieldInfo{
+		has: func(p pointer) bool {
+			rv := p.apply(fieldOffset).asType(fs.Type).Elem()
+			return !rv.IsNil()
+		},
+		get: func(p pointer) pref.Value {
+			// For unpopulated fields, this returns a wrapper over a typed nil
+			// pointer of the message type to be consistent with the behavior
+			// of generated getters.
+			rv := p.apply(fieldOffset).asType(fs.Type).Elem()
+			return conv.toPB(rv)
+		},
+		set: func(p pointer, v pref.Value) {
+			rv := p.apply(fieldOffset).asType(fs.Type).Elem()
+			rv.Set(conv.toGo(v))
+		},
+		clear: func(p pointer) {
+			rv := p.apply(fieldOffset).asType(fs.Type).Elem()
+			rv.Set(reflect.Zero(rv.Type()))
+		},
+		mutable: func(p pointer) pref.Mutable {
+			rv := p.apply(fieldOffset).asType(fs.Type).Elem()
+			if rv.IsNil() {
+				pv := pref.ValueOf(conv.newMessage())
+				rv.Set(conv.toGo(pv))
+			}
+			return conv.toPB(rv).Message()
+		},
+	}
 }
 
 // messageV1 is the protoV1.Message interface.
@@ -434,12 +468,14 @@ func matchGoTypePBKind(t reflect.Type, k pref.Kind) converter {
 	case pref.MessageKind, pref.GroupKind:
 		// Handle v2 messages, which must satisfy the proto.Message interface.
 		if t.Kind() == reflect.Ptr && t.Implements(messageIfaceV2) {
-			// TODO: implement this.
+			return makeMessageConverter(t, nil)
 		}
 
-		// Handle v1 messages, which we need to wrap as a v2 message.
-		if t.Kind() == reflect.Ptr && t.Implements(messageIfaceV1) {
-			// TODO: need logic to wrap a legacy message.
+		// Handle messages that do not implement the v2 interface (including
+		// v1 messages), which we wrap with a message type derived from the
+		// Go struct tags.
+		if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct {
+			return makeMessageConverter(t, new(MessageType))
 		}
 	}
 	panic(fmt.Sprintf("invalid Go type %v for protobuf kind %v", t, k))
@@ -476,3 +512,43 @@ func makeScalarConverter(goType, pbType reflect.Type) converter {
 		},
 	}
 }
+
+// makeMessageConverter returns a converter for a message with Go type t,
+// which must be a pointer to a struct. If mi is non-nil, then values are
+// wrapped with mi rather than through the proto.Message interface, which
+// lazily derives a message descriptor from the Go struct tags.
+func makeMessageConverter(t reflect.Type, mi *MessageType) converter {
+	return converter{
+		toPB: func(v reflect.Value) pref.Value {
+			if v.Type() != t {
+				panic(fmt.Sprintf("invalid type: got %v, want %v", v.Type(), t))
+			}
+			if mi != nil {
+				return pref.ValueOf(mi.MessageOf(v.Interface()))
+			}
+			return pref.ValueOf(v.Interface().(pref.ProtoMessage).ProtoReflect())
+		},
+		toGo: func(v pref.Value) reflect.Value {
+			m := v.Message()
+			rv := reflect.ValueOf(m.Interface())
+			if rv.Type() != t {
+				// The message may be behind a reflective wrapper;
+				// unwrap to obtain the underlying Go message.
+				if u, ok := m.(interface{ Unwrap() interface{} }); ok {
+					rv = reflect.ValueOf(u.Unwrap())
+				}
+			}
+			if rv.Type() != t {
+				panic(fmt.Sprintf("invalid type: got %v, want %v", rv.Type(), t))
+			}
+			return rv
+		},
+		newMessage: func() pref.Message {
+			p := reflect.New(t.Elem()).Interface()
+			if mi != nil {
+				return mi.MessageOf(p)
+			}
+			return p.(pref.ProtoMessage).ProtoReflect()
+		},
+	}
+}
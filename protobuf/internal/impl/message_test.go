@@ -5,8 +5,10 @@
 package impl
 
 import (
+	"bytes"
 	"fmt"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -48,13 +50,13 @@ type (
 
 // List of test operations to perform on messages, vectors, or maps.
 type (
-	messageOp  interface{} // equalMessage | hasFields | getFields | setFields | clearFields | vectorFields | mapFields
+	messageOp  interface{} // equalMessage | hasFields | getFields | setFields | clearFields | vectorFields | mapFields | messageFields
 	messageOps []messageOp
 
-	vectorOp  interface{} // equalVector | lenVector | getVector | setVector | appendVector | truncVector
+	vectorOp  interface{} // equalVector | lenVector | getVector | setVector | appendVector | truncVector | mutableVector | mutableAppendVector
 	vectorOps []vectorOp
 
-	mapOp  interface{} // equalMap | lenMap | hasMap | getMap | setMap | clearMap | rangeMap
+	mapOp  interface{} // equalMap | lenMap | hasMap | getMap | setMap | clearMap | rangeMap | mutableMap
 	mapOps []mapOp
 )
 
@@ -73,25 +75,26 @@ type (
 
 // Test operations performed on a vector.
 type (
-	equalVector  pref.Vector
-	lenVector    int
-	getVector    map[int]pref.Value
-	setVector    map[int]pref.Value
-	appendVector []pref.Value
-	truncVector  int
-	// TODO: Mutable, MutableAppend
+	equalVector         pref.Vector
+	lenVector           int
+	getVector           map[int]pref.Value
+	setVector           map[int]pref.Value
+	appendVector        []pref.Value
+	truncVector         int
+	mutableVector       map[int]messageOps
+	mutableAppendVector []messageOps
 )
 
 // Test operations performed on a map.
 type (
-	equalMap pref.Map
-	lenMap   int
-	hasMap   map[interface{}]bool
-	getMap   map[interface{}]pref.Value
-	setMap   map[interface{}]pref.Value
-	clearMap map[interface{}]bool
-	rangeMap map[interface{}]pref.Value
-	// TODO: Mutable
+	equalMap   pref.Map
+	lenMap     int
+	hasMap     map[interface{}]bool
+	getMap     map[interface{}]pref.Value
+	setMap     map[interface{}]pref.Value
+	clearMap   map[interface{}]bool
+	rangeMap   map[interface{}]pref.Value
+	mutableMap map[interface{}]messageOps
 )
 
 func TestScalarProto2(t *testing.T) {
@@ -760,7 +763,321 @@ func TestOneofs(t *testing.T) {
 	})
 }
 
-// TODO: Need to test singular and repeated messages
+func TestMessages(t *testing.T) {
+	type (
+		MessageChild struct {
+			Int32 *int32 `protobuf:"1"`
+		}
+		MessageParent struct {
+			M  *MessageChild            `protobuf:"1"`
+			MV []*MessageChild          `protobuf:"2"`
+			MM map[string]*MessageChild `protobuf:"3"`
+		}
+	)
+
+	miChild := MessageType{Desc: mustMakeMessageDesc(ptype.StandaloneMessage{
+		Syntax:   pref.Proto2,
+		FullName: "MessageChild",
+		Fields: []ptype.Field{
+			{Name: "f1", Number: 1, Cardinality: pref.Optional, Kind: pref.Int32Kind},
+		},
+	})}
+	mi := MessageType{Desc: mustMakeMessageDesc(ptype.StandaloneMessage{
+		Syntax:   pref.Proto2,
+		FullName: "MessageParent",
+		Fields: []ptype.Field{
+			{Name: "f1", Number: 1, Cardinality: pref.Optional, Kind: pref.MessageKind, MessageType: ptype.PlaceholderMessage("MessageChild")},
+			{Name: "f2", Number: 2, Cardinality: pref.Repeated, Kind: pref.MessageKind, MessageType: ptype.PlaceholderMessage("MessageChild")},
+			{Name: "f3", Number: 3, Cardinality: pref.Repeated, Kind: pref.MessageKind, MessageType: mustMakeMessageDesc(ptype.StandaloneMessage{
+				Syntax:   pref.Proto2,
+				FullName: "MessageParent.F3Entry",
+				Fields: []ptype.Field{
+					{Name: "key", Number: 1, Cardinality: pref.Optional, Kind: pref.StringKind},
+					{Name: "value", Number: 2, Cardinality: pref.Optional, Kind: pref.MessageKind, MessageType: ptype.PlaceholderMessage("MessageChild")},
+				},
+				IsMapEntry: true,
+			})},
+		},
+	})}
+
+	empty := mi.MessageOf(&MessageParent{})
+	want := mi.MessageOf(&MessageParent{
+		M:  &MessageChild{Int32: proto.Int32(10)},
+		MV: []*MessageChild{{Int32: proto.Int32(20)}, {Int32: proto.Int32(30)}, {}},
+		MM: map[string]*MessageChild{"forty": {Int32: proto.Int32(40)}},
+	})
+
+	testMessage(t, nil, mi.MessageOf(&MessageParent{}), messageOps{
+		hasFields{1: false, 2: false, 3: false},
+		// An unpopulated message field is consistent with a typed nil pointer.
+		getFields{1: V(miChild.MessageOf((*MessageChild)(nil)))},
+
+		// Mutable lazily allocates the message field.
+		messageFields{1: messageOps{
+			hasFields{1: false},
+			setFields{1: V(int32(10))},
+			hasFields{1: true},
+			getFields{1: V(int32(10))},
+		}},
+		hasFields{1: true},
+		getFields{1: V(miChild.MessageOf(&MessageChild{Int32: proto.Int32(10)}))},
+
+		vectorFields{2: vectorOps{
+			lenVector(0),
+			appendVector{V(miChild.MessageOf(&MessageChild{Int32: proto.Int32(20)}))},
+			mutableAppendVector{messageOps{
+				setFields{1: V(int32(30))},
+			}},
+			lenVector(2),
+			mutableVector{0: messageOps{
+				getFields{1: V(int32(20))},
+			}},
+			getVector{1: V(miChild.MessageOf(&MessageChild{Int32: proto.Int32(30)}))},
+			// Mutable lazily allocates nil elements.
+			appendVector{V(miChild.MessageOf((*MessageChild)(nil)))},
+			mutableVector{2: messageOps{
+				hasFields{1: false},
+			}},
+		}},
+		hasFields{2: true},
+
+		mapFields{3: mapOps{
+			lenMap(0),
+			// Mutable lazily allocates the map entry.
+			mutableMap{"forty": messageOps{
+				hasFields{1: false},
+				setFields{1: V(int32(40))},
+			}},
+			lenMap(1),
+			getMap{"forty": V(miChild.MessageOf(&MessageChild{Int32: proto.Int32(40)}))},
+		}},
+		hasFields{3: true},
+		equalMessage(want),
+
+		setFields{1: V(miChild.MessageOf(&MessageChild{Int32: proto.Int32(10)}))},
+		equalMessage(want),
+		clearFields{1: true, 2: true, 3: true},
+		equalMessage(empty),
+	})
+}
+
+func mustMakeExtensionDesc(t ptype.StandaloneExtension) pref.ExtensionDescriptor {
+	xd, err := ptype.NewExtension(&t)
+	if err != nil {
+		panic(err)
+	}
+	return xd
+}
+
+// int32Extension and stringsExtension wrap an extension descriptor to
+// implement protoreflect.ExtensionType for test purposes.
+type int32Extension struct{ pref.ExtensionDescriptor }
+
+func (x int32Extension) GoNew() interface{}                   { return int32(0) }
+func (x int32Extension) GoType() reflect.Type                 { return reflect.TypeOf(int32(0)) }
+func (x int32Extension) ValueOf(v interface{}) pref.Value     { return V(v) }
+func (x int32Extension) InterfaceOf(v pref.Value) interface{} { return v.Interface() }
+
+type stringsExtension struct{ pref.ExtensionDescriptor }
+
+func (x stringsExtension) GoNew() interface{}                   { return new([]string) }
+func (x stringsExtension) GoType() reflect.Type                 { return reflect.TypeOf((*[]string)(nil)) }
+func (x stringsExtension) ValueOf(v interface{}) pref.Value     { return V(v) }
+func (x stringsExtension) InterfaceOf(v pref.Value) interface{} { return v.Interface() }
+
+func TestExtensionFields(t *testing.T) {
+	type ExtendedMessage struct {
+		Int32                  *int32 `protobuf:"1"`
+		XXX_InternalExtensions ExtensionFields
+	}
+
+	mi := MessageType{Desc: mustMakeMessageDesc(ptype.StandaloneMessage{
+		Syntax:   pref.Proto2,
+		FullName: "ExtendedMessage",
+		Fields: []ptype.Field{
+			{Name: "f1", Number: 1, Cardinality: pref.Optional, Kind: pref.Int32Kind},
+		},
+		ExtensionRanges: [][2]pref.FieldNumber{{10, 20}},
+	})}
+
+	xtInt32 := int32Extension{mustMakeExtensionDesc(ptype.StandaloneExtension{
+		Syntax:       pref.Proto2,
+		FullName:     "ext_int32",
+		Number:       10,
+		Cardinality:  pref.Optional,
+		Kind:         pref.Int32Kind,
+		Default:      V(int32(5)),
+		ExtendedType: ptype.PlaceholderMessage("ExtendedMessage"),
+	})}
+	xtStrings := stringsExtension{mustMakeExtensionDesc(ptype.StandaloneExtension{
+		Syntax:       pref.Proto2,
+		FullName:     "ext_strings",
+		Number:       11,
+		Cardinality:  pref.Repeated,
+		Kind:         pref.StringKind,
+		ExtendedType: ptype.PlaceholderMessage("ExtendedMessage"),
+	})}
+
+	fs := mi.MessageOf(&ExtendedMessage{}).KnownFields()
+	xts := fs.ExtensionTypes()
+	xts.Register(xtInt32)
+	xts.Register(xtStrings)
+	if xts.Len() != 2 || xts.ByNumber(10) == nil || xts.ByName("ext_strings") == nil {
+		t.Fatalf("extensions not registered")
+	}
+
+	// Registered but unpopulated extensions report their default value.
+	if fs.Has(10) {
+		t.Errorf("Has(ext_int32) = true, want false")
+	}
+	if v := fs.Get(10); v.Int() != 5 {
+		t.Errorf("Get(ext_int32) = %v, want default 5", v)
+	}
+	if n := fs.Get(11).Vector().Len(); n != 0 {
+		t.Errorf("Get(ext_strings).Vector().Len() = %d, want 0", n)
+	}
+
+	fs.Set(10, V(int32(7)))
+	if !fs.Has(10) {
+		t.Errorf("Has(ext_int32) = false after Set, want true")
+	}
+	if v := fs.Get(10); v.Int() != 7 {
+		t.Errorf("Get(ext_int32) = %v, want 7", v)
+	}
+	if n := fs.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+
+	// A repeated extension is populated only once it is non-empty.
+	vec := fs.Mutable(11).(pref.Vector)
+	if fs.Has(11) {
+		t.Errorf("Has(ext_strings) = true for an empty vector, want false")
+	}
+	vec.Append(V("a"))
+	if !fs.Has(11) || fs.Get(11).Vector().Len() != 1 {
+		t.Errorf("mutations through Mutable(ext_strings) not observed")
+	}
+
+	got := map[pref.FieldNumber]bool{}
+	fs.Range(func(n pref.FieldNumber, v pref.Value) bool {
+		got[n] = true
+		return true
+	})
+	if !got[10] || !got[11] || len(got) != 2 {
+		t.Errorf("Range() visited %v, want extension fields 10 and 11", got)
+	}
+
+	// Clearing keeps the extension registered.
+	fs.Clear(10)
+	if fs.Has(10) || xts.ByNumber(10) == nil {
+		t.Errorf("Clear(ext_int32) did not keep the extension registered")
+	}
+
+	// Removing an extension with a populated value panics.
+	if got := func() (v interface{}) {
+		defer func() { v = recover() }()
+		xts.Remove(xtStrings)
+		return nil
+	}(); got == nil {
+		t.Errorf("Remove() with a populated value succeeded, want panic")
+	}
+	fs.Clear(11)
+	xts.Remove(xtStrings)
+	if xts.Len() != 1 {
+		t.Errorf("Len() = %d after Remove, want 1", xts.Len())
+	}
+
+	// Registering a duplicate or out-of-range extension panics.
+	if got := func() (v interface{}) {
+		defer func() { v = recover() }()
+		xts.Register(xtInt32)
+		return nil
+	}(); got == nil {
+		t.Errorf("Register() of a duplicate extension succeeded, want panic")
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	type UnknownMessage struct {
+		Int32            *int32 `protobuf:"1"`
+		XXX_unrecognized []byte
+	}
+
+	mi := MessageType{Desc: mustMakeMessageDesc(ptype.StandaloneMessage{
+		Syntax:   pref.Proto2,
+		FullName: "UnknownMessage",
+		Fields: []ptype.Field{
+			{Name: "f1", Number: 1, Cardinality: pref.Optional, Kind: pref.Int32Kind},
+		},
+	})}
+
+	m := &UnknownMessage{}
+	us := mi.MessageOf(m).UnknownFields()
+	if !us.IsSupported() {
+		t.Fatalf("IsSupported() = false, want true")
+	}
+	if us.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", us.Len())
+	}
+
+	raw5 := pref.RawFields{0x28, 0x01}              // field 5, varint 1
+	raw6 := pref.RawFields{0x32, 0x01, 0x78}        // field 6, bytes "x"
+	raw5b := pref.RawFields{0x28, 0x01, 0x28, 0x02} // field 5, varints 1 and 2
+	us.Set(5, raw5)
+	us.Set(6, raw6)
+	if us.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", us.Len())
+	}
+	if got := us.Get(5); !bytes.Equal(got, raw5) {
+		t.Errorf("Get(5) = %x, want %x", got, raw5)
+	}
+
+	// Setting a field number replaces all fields with that number,
+	// preserving the relative ordering of the others.
+	us.Set(5, raw5b)
+	if got := us.Get(5); !bytes.Equal(got, raw5b) {
+		t.Errorf("Get(5) = %x, want %x", got, raw5b)
+	}
+	if got, want := m.XXX_unrecognized, append(append([]byte{}, raw6...), raw5b...); !bytes.Equal(got, want) {
+		t.Errorf("XXX_unrecognized = %x, want %x", got, want)
+	}
+
+	got := map[pref.FieldNumber]pref.RawFields{}
+	us.Range(func(n pref.FieldNumber, raw pref.RawFields) bool {
+		got[n] = raw
+		return true
+	})
+	want := map[pref.FieldNumber]pref.RawFields{5: raw5b, 6: raw6}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Range() mismatch (-want, +got):\n%s", diff)
+	}
+
+	us.Set(5, nil)
+	us.Set(6, nil)
+	if us.Len() != 0 || len(m.XXX_unrecognized) != 0 {
+		t.Errorf("unknown fields not cleared: %x", m.XXX_unrecognized)
+	}
+
+	// Messages without a XXX_unrecognized field ignore unknown fields.
+	type NoUnknownMessage struct {
+		Int32 *int32 `protobuf:"1"`
+	}
+	mi2 := MessageType{Desc: mustMakeMessageDesc(ptype.StandaloneMessage{
+		Syntax:   pref.Proto2,
+		FullName: "NoUnknownMessage",
+		Fields: []ptype.Field{
+			{Name: "f1", Number: 1, Cardinality: pref.Optional, Kind: pref.Int32Kind},
+		},
+	})}
+	us2 := mi2.MessageOf(&NoUnknownMessage{}).UnknownFields()
+	if us2.IsSupported() {
+		t.Errorf("IsSupported() = true, want false")
+	}
+	us2.Set(5, raw5)
+	if us2.Len() != 0 {
+		t.Errorf("Len() = %d after ignored Set, want 0", us2.Len())
+	}
+}
 
 var cmpOpts = cmp.Options{
 	cmp.Transformer("UnwrapValue", func(v pref.Value) interface{} {
@@ -831,6 +1148,12 @@ func testMessage(t *testing.T, p path, m pref.Message, tt messageOps) {
 				testMaps(t, p, fs.Mutable(n).(pref.Map), tt)
 				p.Pop()
 			}
+		case messageFields:
+			for n, tt := range op {
+				p.Push(int(n))
+				testMessage(t, p, fs.Mutable(n).(pref.Message), tt)
+				p.Pop()
+			}
 		default:
 			t.Fatalf("operation %v, invalid operation: %T", p, op)
 		}
@@ -869,6 +1192,16 @@ func testVectors(t *testing.T, p path, v pref.Vector, tt vectorOps) {
 			}
 		case truncVector:
 			v.Truncate(int(op))
+		case mutableVector:
+			for n, tt := range op {
+				p.Push(n)
+				testMessage(t, p, v.Mutable(n).(pref.Message), tt)
+				p.Pop()
+			}
+		case mutableAppendVector:
+			for _, tt := range op {
+				testMessage(t, p, v.MutableAppend().(pref.Message), tt)
+			}
 		default:
 			t.Fatalf("operation %v, invalid operation: %T", p, op)
 		}
@@ -926,6 +1259,10 @@ func testMaps(t *testing.T, p path, m pref.Map, tt mapOps) {
 			if diff := cmp.Diff(want, got, cmpOpts); diff != "" {
 				t.Errorf("operation %v, Map.Range mismatch (-want, +got):\n%s", p, diff)
 			}
+		case mutableMap:
+			for k, tt := range op {
+				testMessage(t, p, m.Mutable(V(k).MapKey()).(pref.Message), tt)
+			}
 		default:
 			t.Fatalf("operation %v, invalid operation: %T", p, op)
 		}
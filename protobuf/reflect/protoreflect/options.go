@@ -0,0 +1,37 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect
+
+// This file provides typed views over descriptor options so that analysis
+// tools and code generators can query common flags without reparsing the
+// descriptor protos themselves. Flags that are semantically significant to
+// serialization are surfaced as first-class methods instead (e.g.,
+// MessageDescriptor.IsMapEntry and FieldDescriptor.IsPacked).
+
+// OptionValue returns the value of the option field with the given name on
+// the descriptor, consulting the optional DescriptorOptions accessor.
+// As with DescriptorOptions.ByName, a non-extension field of the options
+// type may be named by the field name alone (e.g., "deprecated").
+//
+// It returns an invalid Value if the descriptor does not support options
+// or if the field is unknown or not set.
+func OptionValue(d Descriptor, name FullName) Value {
+	opts, ok := d.DescriptorOptions()
+	if !ok {
+		return Value{}
+	}
+	_, v := opts.ByName(name)
+	return v
+}
+
+// IsDeprecated reports whether the declaration is marked as deprecated in
+// its options. Every descriptor options type has a deprecated field.
+// It reports false if the descriptor does not support options.
+func IsDeprecated(d Descriptor) bool {
+	if v := OptionValue(d, "deprecated"); v.IsValid() {
+		return v.Bool()
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protoreflect
+
+import "testing"
+
+// fakeOptionsDescriptor is a Descriptor whose options hold a fixed set of
+// boolean fields. Methods other than DescriptorOptions are left unimplemented.
+type fakeOptionsDescriptor struct {
+	Descriptor
+	opts map[FullName]Value
+}
+
+func (d fakeOptionsDescriptor) DescriptorOptions() (DescriptorOptions, bool) {
+	if d.opts == nil {
+		return nil, false
+	}
+	return fakeOptions{opts: d.opts}, true
+}
+
+type fakeOptions struct {
+	DescriptorOptions
+	opts map[FullName]Value
+}
+
+func (o fakeOptions) ByName(s FullName) (FieldDescriptor, Value) {
+	if v, ok := o.opts[s]; ok {
+		return nil, v
+	}
+	return nil, Value{}
+}
+
+func TestOptionValue(t *testing.T) {
+	unsupported := fakeOptionsDescriptor{}
+	deprecated := fakeOptionsDescriptor{opts: map[FullName]Value{
+		"deprecated": ValueOf(true),
+		"map_entry":  ValueOf(false),
+	}}
+
+	if v := OptionValue(unsupported, "deprecated"); v.IsValid() {
+		t.Errorf("OptionValue(unsupported, deprecated) = %v, want invalid", v)
+	}
+	if v := OptionValue(deprecated, "deprecated"); !v.IsValid() || !v.Bool() {
+		t.Errorf("OptionValue(deprecated, deprecated) = %v, want true", v)
+	}
+	if v := OptionValue(deprecated, "map_entry"); !v.IsValid() || v.Bool() {
+		t.Errorf("OptionValue(deprecated, map_entry) = %v, want false", v)
+	}
+	if v := OptionValue(deprecated, "unknown_option"); v.IsValid() {
+		t.Errorf("OptionValue(deprecated, unknown_option) = %v, want invalid", v)
+	}
+
+	if IsDeprecated(unsupported) {
+		t.Errorf("IsDeprecated(unsupported) = true, want false")
+	}
+	if !IsDeprecated(deprecated) {
+		t.Errorf("IsDeprecated(deprecated) = false, want true")
+	}
+}
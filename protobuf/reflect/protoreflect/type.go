@@ -187,6 +187,12 @@ type FileDescriptor interface {
 	// by full name. It returns nil if not found.
 	DescriptorByName(FullName) Descriptor
 
+	// SourceLocations is a list of source locations, derived from the
+	// google.protobuf.SourceCodeInfo message, recording the source spans
+	// and comments of declarations within the file.
+	// It is empty if source information is not present.
+	SourceLocations() SourceLocations
+
 	isFileDescriptor
 }
 type isFileDescriptor interface{ ProtoType(FileDescriptor) }
@@ -238,6 +244,60 @@ type FileImport struct {
 	IsWeak bool
 }
 
+// SourceLocations is a list of source locations.
+type SourceLocations interface {
+	// Len reports the number of source locations in the file.
+	Len() int
+	// Get returns the ith SourceLocation. It panics if out of bounds.
+	Get(i int) SourceLocation
+	// ByPath returns the SourceLocation for the declaration identified by
+	// the given descriptor path. It returns the zero SourceLocation if the
+	// path has no recorded location.
+	ByPath(path SourcePath) SourceLocation
+
+	doNotImplement
+}
+
+// SourcePath identifies part of a file descriptor and corresponds with the
+// path field of the google.protobuf.SourceCodeInfo.Location message.
+// Each element is either a field number or an index into a repeated field
+// of the descriptor proto, starting from FileDescriptorProto.
+type SourcePath []int32
+
+// Equal reports whether p1 is equal to p2.
+func (p1 SourcePath) Equal(p2 SourcePath) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for i := range p1 {
+		if p1[i] != p2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SourceLocation describes a source location and
+// corresponds with the google.protobuf.SourceCodeInfo.Location message.
+type SourceLocation struct {
+	// Path is the path to the declaration from the root of the file.
+	Path SourcePath
+
+	// StartLine and StartColumn are the zero-indexed starting location
+	// of the declaration within the source file.
+	StartLine, StartColumn int
+	// EndLine and EndColumn are the zero-indexed ending location
+	// of the declaration within the source file.
+	EndLine, EndColumn int
+
+	// LeadingDetachedComments are the previous detached comments.
+	LeadingDetachedComments []string
+	// LeadingComments is the leading attached comment.
+	LeadingComments string
+	// TrailingComments is the trailing attached comment.
+	TrailingComments string
+}
+
 // MessageDescriptor describes a message and
 // corresponds with the google.protobuf.DescriptorProto message.
 //
@@ -7,55 +7,85 @@
 package protoregistry
 
 import (
+	"fmt"
+	"reflect"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/golang/protobuf/v2/internal/errors"
 	"github.com/golang/protobuf/v2/reflect/protoreflect"
 )
 
-// TODO: Perhaps Register should record the frame of where the function was
-// called and surface that in the error? That would help users debug duplicate
-// registration issues. This presumes that we provide a way to disable automatic
-// registration in generated code.
+// GlobalFiles is a global registry of file descriptors.
+var GlobalFiles = new(Files)
 
-// TODO: Add a type registry:
-/*
+// GlobalTypes is the registry used by default for type lookups.
 var GlobalTypes = new(Types)
 
-type Type interface {
-	protoreflect.Descriptor
-	GoType() reflect.Type
-}
-type Types struct {
-	Parent   *Types
-	Resolver func(url string) (Type, error)
+// NotFound is a sentinel error value to indicate that the type was not found.
+var NotFound = errors.New("not found")
+
+// A ConflictError reports that a file could not be registered because one
+// of its declarations has the same full name as a declaration of a
+// previously registered file. Files.Register returns a *ConflictError so
+// that callers can inspect the conflict programmatically.
+type ConflictError struct {
+	// Name is the full name of the conflicting declaration or package.
+	Name protoreflect.FullName
+	// NewFile is the path of the file whose registration failed.
+	NewFile string
+	// OldFile is the path of the previously registered file that
+	// already declares Name. It may be empty if the owner could not
+	// be determined.
+	OldFile string
+	// OldSite is the file and line at which OldFile was registered,
+	// or empty if unknown.
+	OldSite string
 }
-func NewTypes(typs ...Type) *Types
-func (*Types) Register(typs ...Type) error
-func (*Types) FindEnumByName(enum protoreflect.FullName) (protoreflect.EnumType, error)
-func (*Types) FindMessageByName(message protoreflect.FullName) (protoreflect.MessageType, error)
-func (*Types) FindMessageByURL(url string) (protoreflect.MessageType, error)
-func (*Types) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error)
-func (*Types) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error)
-func (*Types) RangeEnums(f func(protoreflect.EnumType) bool)
-func (*Types) RangeMessages(f func(protoreflect.MessageType) bool)
-func (*Types) RangeExtensions(f func(protoreflect.ExtensionType) bool)
-func (*Types) RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool)
-*/
 
-// GlobalFiles is a global registry of file descriptors.
-var GlobalFiles = new(Files)
+func (e *ConflictError) Error() string {
+	s := fmt.Sprintf("file %q has a name conflict over %v", e.NewFile, e.Name)
+	if e.OldFile != "" {
+		s += fmt.Sprintf(" with file %q", e.OldFile)
+	}
+	if e.OldSite != "" {
+		s += fmt.Sprintf(" (registered at %v)", e.OldSite)
+	}
+	return s
+}
 
-// NotFound is a sentinel error value to indicate that the type was not found.
-var NotFound = errors.New("not found")
+// callSite returns the file and line of the nearest caller outside this
+// package, so that registrations through NewFiles or Clone still record
+// where the user called from.
+func callSite() string {
+	var pcs [8]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		fr, more := frames.Next()
+		if !strings.HasPrefix(fr.Function, "github.com/golang/protobuf/v2/reflect/protoregistry.") {
+			return fmt.Sprintf("%s:%d", fr.File, fr.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
 
 // Files is a registry for looking up or iterating over files and the
 // descriptors contained within them.
-// The Find and Range methods are safe for concurrent use.
+// All methods are safe for concurrent use, including calling Register
+// while Find or Range operations are in flight.
 type Files struct {
+	// parent is the registry to consult if a find operation could not
+	// locate the appropriate entry locally; see NewChild.
+	parent *Files
+
+	mu             sync.RWMutex // protects all fields below
 	filesByPackage filesByPackage
 	filesByPath    filesByPath
+	sites          map[protoreflect.FileDescriptor]string // file:line of each registration
 }
 
 type (
@@ -94,10 +124,14 @@ func NewFiles(files ...protoreflect.FileDescriptor) *Files {
 //
 // If any descriptor within a file conflicts with the descriptor of any
 // previously registered file (e.g., two enums with the same full name),
-// then that file is not registered and an error is returned.
+// then that file is not registered and a *ConflictError is returned,
+// identifying the previously registered file and where it was registered.
 //
 // It is permitted for multiple files to have the same file path.
 func (r *Files) Register(files ...protoreflect.FileDescriptor) error {
+	site := callSite()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var firstErr error
 fileLoop:
 	for _, file := range files {
@@ -129,7 +163,7 @@ fileLoop:
 			case notProtoPackage:
 				if firstErr == nil {
 					name := strings.TrimSuffix(strings.TrimSuffix(string(file.Package()), string(pkg)), ".")
-					firstErr = errors.New("file %q has a name conflict over %v", file.Path(), name)
+					firstErr = r.conflictErrorLocked(protoreflect.FullName(name), file, root)
 				}
 				continue fileLoop
 			default:
@@ -162,11 +196,15 @@ fileLoop:
 
 			if firstErr == nil {
 				name := file.Package().Append(conflicts[0])
-				firstErr = errors.New("file %q has a name conflict over %v", file.Path(), name)
+				firstErr = r.conflictErrorLocked(name, file, root)
 			}
 			continue fileLoop
 		}
 		root.files = append(root.files, file)
+		if r.sites == nil {
+			r.sites = make(map[protoreflect.FileDescriptor]string)
+		}
+		r.sites[file] = site
 
 		// Register the file into the filesByPath map.
 		//
@@ -182,6 +220,35 @@ fileLoop:
 	return firstErr
 }
 
+// conflictErrorLocked constructs a *ConflictError for the given name,
+// determining the previously registered owner from the package node whose
+// declarations conflict. The caller must hold r.mu.
+func (r *Files) conflictErrorLocked(name protoreflect.FullName, file protoreflect.FileDescriptor, node *filesByPackage) error {
+	e := &ConflictError{Name: name, NewFile: file.Path()}
+	var owner protoreflect.FileDescriptor
+	for _, fd := range node.files {
+		if fd.DescriptorByName(name) != nil {
+			owner = fd
+			break
+		}
+	}
+	if owner == nil {
+		// The name is held by a sub-package; report one of its files.
+		if sub := node.subs[name.Name()]; sub != nil && sub != notProtoPackage {
+			var fds []protoreflect.FileDescriptor
+			appendFiles(&fds, sub)
+			if len(fds) > 0 {
+				owner = fds[0]
+			}
+		}
+	}
+	if owner != nil {
+		e.OldFile = owner.Path()
+		e.OldSite = r.sites[owner]
+	}
+	return e
+}
+
 // FindDescriptorByName looks up any descriptor (except files) by its full name.
 // Files are not handled since multiple file descriptors may belong in
 // the same package and have the same full name (see RangeFilesByPackage).
@@ -191,6 +258,14 @@ func (r *Files) FindDescriptorByName(name protoreflect.FullName) (protoreflect.D
 	if r == nil {
 		return nil, NotFound
 	}
+	if d := r.findDescriptorByName(name); d != nil {
+		return d, nil
+	}
+	return r.parent.FindDescriptorByName(name)
+}
+func (r *Files) findDescriptorByName(name protoreflect.FullName) protoreflect.Descriptor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	pkg := name
 	root := &r.filesByPackage
 	for len(pkg) > 0 {
@@ -198,20 +273,98 @@ func (r *Files) FindDescriptorByName(name protoreflect.FullName) (protoreflect.D
 		prefix, pkg = splitPrefix(pkg)
 		switch nextRoot := root.subs[prefix]; nextRoot {
 		case nil:
-			return nil, NotFound
+			return nil
 		case notProtoPackage:
 			// Search current root's package for the descriptor.
 			for _, fd := range root.files {
 				if d := fd.DescriptorByName(name); d != nil {
-					return d, nil
+					return d
 				}
 			}
-			return nil, NotFound
+			return nil
 		default:
 			root = nextRoot
 		}
 	}
-	return nil, NotFound
+	return nil
+}
+
+// FindFileByDescriptor looks up the registered file whose declarations
+// include the given descriptor, matching by full name. If d is itself a
+// file, the lookup succeeds only if that exact file is registered.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Files) FindFileByDescriptor(d protoreflect.Descriptor) (protoreflect.FileDescriptor, error) {
+	if r == nil || d == nil {
+		return nil, NotFound
+	}
+	if fd := r.findFileByDescriptor(d); fd != nil {
+		return fd, nil
+	}
+	return r.parent.FindFileByDescriptor(d)
+}
+func (r *Files) findFileByDescriptor(d protoreflect.Descriptor) protoreflect.FileDescriptor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fd, ok := d.(protoreflect.FileDescriptor); ok {
+		for _, fd2 := range r.filesByPath[fd.Path()] {
+			if fd2 == fd {
+				return fd2
+			}
+		}
+		return nil
+	}
+	name := d.FullName()
+	pkg := name
+	root := &r.filesByPackage
+	for len(pkg) > 0 {
+		var prefix protoreflect.Name
+		prefix, pkg = splitPrefix(pkg)
+		switch nextRoot := root.subs[prefix]; nextRoot {
+		case nil:
+			return nil
+		case notProtoPackage:
+			// Search current root's package for the declaring file.
+			for _, fd := range root.files {
+				if fd.DescriptorByName(name) != nil {
+					return fd
+				}
+			}
+			return nil
+		default:
+			root = nextRoot
+		}
+	}
+	return nil
+}
+
+// RangeDependents iterates over all registered files that directly import
+// the file with the given path, whether by regular, public, or weak import.
+// The iteration order is undefined.
+func (r *Files) RangeDependents(path string, f func(protoreflect.FileDescriptor) bool) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	var fds []protoreflect.FileDescriptor
+	for _, files := range r.filesByPath {
+		for _, fd := range files {
+			imps := fd.Imports()
+			for i := 0; i < imps.Len(); i++ {
+				if imps.Get(i).Path() == path {
+					fds = append(fds, fd)
+					break
+				}
+			}
+		}
+	}
+	r.mu.RUnlock()
+	for _, fd := range fds {
+		if !f(fd) {
+			return
+		}
+	}
+	r.parent.RangeDependents(path, f)
 }
 
 // RangeFiles iterates over all registered files.
@@ -231,31 +384,78 @@ func (r *Files) RangeFilesByPackage(pkg protoreflect.FullName, f func(protorefle
 	if strings.HasSuffix(string(pkg), ".") {
 		return // avoid edge case where splitPrefix allows trailing dot
 	}
+	for _, fd := range r.filesInPackage(pkg) {
+		if !f(fd) {
+			return
+		}
+	}
+	r.parent.RangeFilesByPackage(pkg, f)
+}
+
+// filesInPackage collects the files under the given package prefix.
+// It holds the read lock only while collecting, so that the caller may
+// pass the files to a callback that calls back into the registry.
+func (r *Files) filesInPackage(pkg protoreflect.FullName) []protoreflect.FileDescriptor {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	root := &r.filesByPackage
-	for len(pkg) > 0 && root != nil {
+	for rest := pkg; len(rest) > 0 && root != nil; {
 		var prefix protoreflect.Name
-		prefix, pkg = splitPrefix(pkg)
+		prefix, rest = splitPrefix(rest)
 		root = root.subs[prefix]
 	}
-	rangeFiles(root, f)
+	var fds []protoreflect.FileDescriptor
+	appendFiles(&fds, root)
+	return fds
 }
-func rangeFiles(fs *filesByPackage, f func(protoreflect.FileDescriptor) bool) bool {
+func appendFiles(fds *[]protoreflect.FileDescriptor, fs *filesByPackage) {
 	if fs == nil {
-		return true
+		return
+	}
+	// Append exact matches before prefix matches.
+	*fds = append(*fds, fs.files...) // TODO: iterate non-deterministically
+	for _, fs := range fs.subs {
+		appendFiles(fds, fs)
+	}
+}
+
+// SortedRangeFiles iterates over all registered files in a deterministic
+// order: sorted by file path, and by package name among files sharing a
+// path. It is intended for reproducible output, such as code generation
+// and golden-file tests, where the map-dependent order of RangeFiles
+// does not suffice.
+func (r *Files) SortedRangeFiles(f func(protoreflect.FileDescriptor) bool) {
+	r.SortedRangeFilesByPackage("", f)
+}
+
+// SortedRangeFilesByPackage iterates like RangeFilesByPackage, but in the
+// deterministic order of SortedRangeFiles. Unlike RangeFilesByPackage,
+// exact package matches are not ordered before prefix matches. Files of a
+// child registry are still iterated before those of its parent.
+func (r *Files) SortedRangeFilesByPackage(pkg protoreflect.FullName, f func(protoreflect.FileDescriptor) bool) {
+	if r == nil {
+		return
 	}
-	// Iterate over exact matches.
-	for _, fd := range fs.files { // TODO: iterate non-deterministically
+	if strings.HasSuffix(string(pkg), ".") {
+		return // avoid edge case where splitPrefix allows trailing dot
+	}
+	fds := r.filesInPackage(pkg)
+	sortFiles(fds)
+	for _, fd := range fds {
 		if !f(fd) {
-			return false
+			return
 		}
 	}
-	// Iterate over prefix matches.
-	for _, fs := range fs.subs {
-		if !rangeFiles(fs, f) {
-			return false
+	r.parent.SortedRangeFilesByPackage(pkg, f)
+}
+
+func sortFiles(fds []protoreflect.FileDescriptor) {
+	sort.Slice(fds, func(i, j int) bool {
+		if fds[i].Path() != fds[j].Path() {
+			return fds[i].Path() < fds[j].Path()
 		}
-	}
-	return true
+		return fds[i].Package() < fds[j].Package()
+	})
 }
 
 // RangeFilesByPath iterates over all registered files filtered by
@@ -264,11 +464,179 @@ func (r *Files) RangeFilesByPath(path string, f func(protoreflect.FileDescriptor
 	if r == nil {
 		return
 	}
-	for _, fd := range r.filesByPath[path] { // TODO: iterate non-deterministically
+	r.mu.RLock()
+	fds := append([]protoreflect.FileDescriptor(nil), r.filesByPath[path]...)
+	r.mu.RUnlock()
+	for _, fd := range fds { // TODO: iterate non-deterministically
+		if !f(fd) {
+			return
+		}
+	}
+	r.parent.RangeFilesByPath(path, f)
+}
+
+// SortedRangeFilesByPath iterates like RangeFilesByPath, but with files
+// sharing the path ordered by package name. Files of a child registry are
+// still iterated before those of its parent.
+func (r *Files) SortedRangeFilesByPath(path string, f func(protoreflect.FileDescriptor) bool) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	fds := append([]protoreflect.FileDescriptor(nil), r.filesByPath[path]...)
+	r.mu.RUnlock()
+	sortFiles(fds)
+	for _, fd := range fds {
 		if !f(fd) {
 			return
 		}
 	}
+	r.parent.SortedRangeFilesByPath(path, f)
+}
+
+// Deregister removes the provided list of file descriptors, which must be
+// the same values previously passed to Register. Placeholder files are
+// ignored.
+//
+// If a file is not registered (in this registry; a parent set by NewChild
+// is never modified), an error is returned, but the remaining files are
+// still deregistered.
+func (r *Files) Deregister(files ...protoreflect.FileDescriptor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var firstErr error
+	for _, file := range files {
+		if file.IsPlaceholder() {
+			continue
+		}
+
+		// Locate the package of the file, remembering the spine of
+		// package nodes so that emptied ones can be pruned.
+		type step struct {
+			node *filesByPackage
+			name protoreflect.Name
+		}
+		var spine []step
+		pkg := file.Package()
+		root := &r.filesByPackage
+		for len(pkg) > 0 && root != nil {
+			var prefix protoreflect.Name
+			prefix, pkg = splitPrefix(pkg)
+			next := root.subs[prefix]
+			if next == notProtoPackage {
+				next = nil
+			}
+			spine = append(spine, step{root, prefix})
+			root = next
+		}
+
+		// Remove the file itself.
+		found := false
+		if root != nil {
+			for i, fd := range root.files {
+				if fd == file {
+					root.files = append(root.files[:i], root.files[i+1:]...)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			if firstErr == nil {
+				firstErr = errors.New("file %q is not registered", file.Path())
+			}
+			continue
+		}
+
+		// Remove the file's top-level declarations, and prune any
+		// package nodes that are now empty.
+		rangeTopLevelDeclarations(file, func(s protoreflect.Name) {
+			if root.subs[s] == notProtoPackage {
+				delete(root.subs, s)
+			}
+		})
+		for i := len(spine) - 1; i >= 0; i-- {
+			node, name := spine[i].node, spine[i].name
+			if sub := node.subs[name]; len(sub.files) == 0 && len(sub.subs) == 0 {
+				delete(node.subs, name)
+			} else {
+				break
+			}
+		}
+
+		// Remove the file from the filesByPath map.
+		fds := r.filesByPath[file.Path()]
+		for i, fd := range fds {
+			if fd == file {
+				fds = append(fds[:i], fds[i+1:]...)
+				break
+			}
+		}
+		if len(fds) == 0 {
+			delete(r.filesByPath, file.Path())
+		} else {
+			r.filesByPath[file.Path()] = fds
+		}
+		delete(r.sites, file)
+	}
+	return firstErr
+}
+
+// NewChild returns an empty registry that overlays the receiver:
+// find and range operations consult the child's own files before falling
+// back to the parent, while Register and Deregister affect only the child.
+// This allows a test harness to register temporary descriptors and discard
+// them without leaking into a shared registry such as GlobalFiles.
+//
+// Registrations in the child are checked for conflicts only against other
+// files in the child, so a child file may shadow a parent declaration with
+// the same name in find operations.
+func (r *Files) NewChild() *Files {
+	return &Files{parent: r}
+}
+
+// Clone returns a deep copy of the registry. A derived registry, such as
+// one needed by a plugin, may register additional files in the copy
+// without affecting the original. The copy shares the parent, if any,
+// set by NewChild.
+func (r *Files) Clone() *Files {
+	r2 := new(Files)
+	if r == nil {
+		return r2
+	}
+	r2.parent = r.parent
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r2.filesByPackage = *clonePackages(&r.filesByPackage)
+	if r.filesByPath != nil {
+		r2.filesByPath = make(filesByPath, len(r.filesByPath))
+		for path, fds := range r.filesByPath {
+			r2.filesByPath[path] = append([]protoreflect.FileDescriptor(nil), fds...)
+		}
+	}
+	if r.sites != nil {
+		r2.sites = make(map[protoreflect.FileDescriptor]string, len(r.sites))
+		for fd, site := range r.sites {
+			r2.sites[fd] = site
+		}
+	}
+	return r2
+}
+
+func clonePackages(fs *filesByPackage) *filesByPackage {
+	if fs == notProtoPackage {
+		return notProtoPackage // preserve the sentinel by identity
+	}
+	fs2 := &filesByPackage{
+		files: append([]protoreflect.FileDescriptor(nil), fs.files...),
+	}
+	if fs.subs != nil {
+		fs2.subs = make(map[protoreflect.Name]*filesByPackage, len(fs.subs))
+		for prefix, sub := range fs.subs {
+			fs2.subs[prefix] = clonePackages(sub)
+		}
+	}
+	return fs2
 }
 
 func splitPrefix(name protoreflect.FullName) (protoreflect.Name, protoreflect.FullName) {
@@ -278,6 +646,340 @@ func splitPrefix(name protoreflect.FullName) (protoreflect.Name, protoreflect.Fu
 	return protoreflect.Name(name), ""
 }
 
+// Type is an interface satisfied by protoreflect.EnumType,
+// protoreflect.MessageType, or protoreflect.ExtensionType.
+type Type interface {
+	protoreflect.Descriptor
+	GoType() reflect.Type
+}
+
+// Types is a registry for looking up or iterating over descriptor types.
+// All methods are safe for concurrent use, including calling Register
+// while Find or Range operations are in flight.
+type Types struct {
+	// Parent sets the parent registry to consult if a find operation
+	// could not locate the appropriate entry.
+	//
+	// Setting a parent results in each Range operation also iterating over the
+	// entries contained within the parent. In such a case, it is possible for
+	// Range to emit duplicates (since they may exist in both child and parent).
+	// Range iteration is guaranteed to iterate over local entries before
+	// iterating over parent entries.
+	Parent *Types
+
+	// Resolver determines how to look up message types for an Any type URL.
+	// If nil, the URL is resolved by looking up the fully-qualified name
+	// after the last '/' in this registry (and then the parent).
+	// If non-nil, the Resolver alone handles the lookup and the returned
+	// Type must be a protoreflect.MessageType.
+	Resolver func(url string) (Type, error)
+
+	// URLResolver determines the message name for an Any type URL, allowing
+	// URLs whose prefix is not type.googleapis.com (e.g., corporate schema
+	// registries or versioned URLs) to map to registered message types.
+	// If nil, the name is the fully-qualified name after the last '/' in
+	// the URL. It is consulted only when Resolver is nil, since Resolver
+	// otherwise performs the entire lookup itself.
+	URLResolver func(url string) (protoreflect.FullName, error)
+
+	mu                  sync.RWMutex // protects the maps below
+	typesByName         typesByName
+	extensionsByMessage extensionsByMessage
+}
+
+type (
+	typesByName         map[protoreflect.FullName]Type
+	extensionsByMessage map[protoreflect.FullName]extensionsByNumber
+	extensionsByNumber  map[protoreflect.FieldNumber]protoreflect.ExtensionType
+)
+
+// NewTypes returns a registry initialized with the provided set of types.
+// If there are conflicts, the first one takes precedence.
+func NewTypes(typs ...Type) *Types {
+	// TODO: Should last take precedence? See the comment in NewFiles.
+	r := new(Types)
+	r.Register(typs...) // ignore errors; first takes precedence
+	return r
+}
+
+// Register registers the provided list of descriptor types.
+// Each type must be an protoreflect.EnumType, protoreflect.MessageType,
+// or protoreflect.ExtensionType.
+//
+// If a registration conflict occurs for any type (e.g., two distinct types
+// have the same full name), then that type is not registered and an error
+// is returned.
+func (r *Types) Register(typs ...Type) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var firstErr error
+typeLoop:
+	for _, typ := range typs {
+		// Determine the type kind and check for conflicts before
+		// inserting anything, to keep registration failure atomic.
+		var kind string
+		switch typ.(type) {
+		case protoreflect.EnumType:
+			kind = "enum"
+		case protoreflect.MessageType:
+			kind = "message"
+		case protoreflect.ExtensionType:
+			kind = "extension"
+		default:
+			if firstErr == nil {
+				firstErr = errors.New("invalid type: %v", reflect.TypeOf(typ))
+			}
+			continue typeLoop
+		}
+		name := typ.FullName()
+		if _, ok := r.typesByName[name]; ok {
+			if firstErr == nil {
+				firstErr = errors.New("%v %v is already registered", kind, name)
+			}
+			continue typeLoop
+		}
+		var message protoreflect.FullName
+		xt, isExtension := typ.(protoreflect.ExtensionType)
+		if isExtension {
+			message = xt.ExtendedType().FullName()
+			if _, ok := r.extensionsByMessage[message][xt.Number()]; ok {
+				if firstErr == nil {
+					firstErr = errors.New("extension %v is already registered on message %v", name, message)
+				}
+				continue typeLoop
+			}
+		}
+
+		if r.typesByName == nil {
+			r.typesByName = make(typesByName)
+		}
+		r.typesByName[name] = typ
+		if isExtension {
+			if r.extensionsByMessage == nil {
+				r.extensionsByMessage = make(extensionsByMessage)
+			}
+			if r.extensionsByMessage[message] == nil {
+				r.extensionsByMessage[message] = make(extensionsByNumber)
+			}
+			r.extensionsByMessage[message][xt.Number()] = xt
+		}
+	}
+	return firstErr
+}
+
+// FindEnumByName looks up an enum by its full name.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Types) FindEnumByName(enum protoreflect.FullName) (protoreflect.EnumType, error) {
+	if r == nil {
+		return nil, NotFound
+	}
+	r.mu.RLock()
+	t, ok := r.typesByName[enum].(protoreflect.EnumType)
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+	return r.Parent.FindEnumByName(enum)
+}
+
+// FindMessageByName looks up a message by its full name.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Types) FindMessageByName(message protoreflect.FullName) (protoreflect.MessageType, error) {
+	if r == nil {
+		return nil, NotFound
+	}
+	r.mu.RLock()
+	t, ok := r.typesByName[message].(protoreflect.MessageType)
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+	return r.Parent.FindMessageByName(message)
+}
+
+// FindMessageByURL looks up a message by an Any type URL, such as
+// "type.googleapis.com/google.protobuf.Duration". If the registry has a
+// Resolver, it alone determines the result; otherwise the message name is
+// determined by the URLResolver, if any, or else is the fully-qualified
+// name after the last '/' in the URL.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Types) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	if r == nil {
+		return nil, NotFound
+	}
+	if r.Resolver != nil {
+		typ, err := r.Resolver(url)
+		if err != nil {
+			return nil, err
+		}
+		t, ok := typ.(protoreflect.MessageType)
+		if !ok {
+			return nil, errors.New("type for URL %q is not a message", url)
+		}
+		return t, nil
+	}
+	message := protoreflect.FullName(url)
+	if r.URLResolver != nil {
+		var err error
+		if message, err = r.URLResolver(url); err != nil {
+			return nil, err
+		}
+	} else if i := strings.LastIndexByte(url, '/'); i >= 0 {
+		message = message[i+len("/"):]
+	}
+	r.mu.RLock()
+	t, ok := r.typesByName[message].(protoreflect.MessageType)
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+	return r.Parent.FindMessageByURL(url)
+}
+
+// FindExtensionByName looks up an extension field by the field's full name.
+// Note that this is the full name of the field as determined by where
+// the extension is declared and is unrelated to the full name of the
+// message being extended.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Types) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	if r == nil {
+		return nil, NotFound
+	}
+	r.mu.RLock()
+	t, ok := r.typesByName[field].(protoreflect.ExtensionType)
+	r.mu.RUnlock()
+	if ok {
+		return t, nil
+	}
+	return r.Parent.FindExtensionByName(field)
+}
+
+// FindExtensionByNumber looks up an extension field by the field number
+// within some parent message, identified by its full name.
+//
+// This returns (nil, NotFound) if not found.
+func (r *Types) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	if r == nil {
+		return nil, NotFound
+	}
+	r.mu.RLock()
+	t := r.extensionsByMessage[message][field]
+	r.mu.RUnlock()
+	if t != nil {
+		return t, nil
+	}
+	return r.Parent.FindExtensionByNumber(message, field)
+}
+
+// FindExtensionsInRange returns all registered extensions of the given
+// message whose field numbers are within the inclusive range [min, max],
+// ordered by field number. It allows an unmarshaler that encounters an
+// unknown field number to quickly check whether it falls in one of the
+// message's extension ranges.
+//
+// If both a registry and its parent register an extension on the same
+// field number, only the registry's own extension is returned.
+func (r *Types) FindExtensionsInRange(message protoreflect.FullName, min, max protoreflect.FieldNumber) []protoreflect.ExtensionType {
+	var xts []protoreflect.ExtensionType
+	seen := make(map[protoreflect.FieldNumber]bool)
+	for ; r != nil; r = r.Parent {
+		r.mu.RLock()
+		for n, xt := range r.extensionsByMessage[message] {
+			if min <= n && n <= max && !seen[n] {
+				seen[n] = true
+				xts = append(xts, xt)
+			}
+		}
+		r.mu.RUnlock()
+	}
+	sort.Slice(xts, func(i, j int) bool { return xts[i].Number() < xts[j].Number() })
+	return xts
+}
+
+// RangeEnums iterates over all registered enums.
+// Iteration order is undefined.
+func (r *Types) RangeEnums(f func(protoreflect.EnumType) bool) {
+	for ; r != nil; r = r.Parent {
+		// Collect the types under the read lock, but release it
+		// before calling f, which may call back into the registry.
+		r.mu.RLock()
+		var ts []protoreflect.EnumType
+		for _, typ := range r.typesByName {
+			if t, ok := typ.(protoreflect.EnumType); ok {
+				ts = append(ts, t)
+			}
+		}
+		r.mu.RUnlock()
+		for _, t := range ts {
+			if !f(t) {
+				return
+			}
+		}
+	}
+}
+
+// RangeMessages iterates over all registered messages.
+// Iteration order is undefined.
+func (r *Types) RangeMessages(f func(protoreflect.MessageType) bool) {
+	for ; r != nil; r = r.Parent {
+		r.mu.RLock()
+		var ts []protoreflect.MessageType
+		for _, typ := range r.typesByName {
+			if t, ok := typ.(protoreflect.MessageType); ok {
+				ts = append(ts, t)
+			}
+		}
+		r.mu.RUnlock()
+		for _, t := range ts {
+			if !f(t) {
+				return
+			}
+		}
+	}
+}
+
+// RangeExtensions iterates over all registered extensions.
+// Iteration order is undefined.
+func (r *Types) RangeExtensions(f func(protoreflect.ExtensionType) bool) {
+	for ; r != nil; r = r.Parent {
+		r.mu.RLock()
+		var ts []protoreflect.ExtensionType
+		for _, typ := range r.typesByName {
+			if t, ok := typ.(protoreflect.ExtensionType); ok {
+				ts = append(ts, t)
+			}
+		}
+		r.mu.RUnlock()
+		for _, t := range ts {
+			if !f(t) {
+				return
+			}
+		}
+	}
+}
+
+// RangeExtensionsByMessage iterates over all registered extensions filtered
+// by a given message type. Iteration order is undefined.
+func (r *Types) RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool) {
+	for ; r != nil; r = r.Parent {
+		r.mu.RLock()
+		var ts []protoreflect.ExtensionType
+		for _, t := range r.extensionsByMessage[message] {
+			ts = append(ts, t)
+		}
+		r.mu.RUnlock()
+		for _, t := range ts {
+			if !f(t) {
+				return
+			}
+		}
+	}
+}
+
 // rangeTopLevelDeclarations iterates over the name of all top-level
 // declarations in the proto file.
 func rangeTopLevelDeclarations(fd protoreflect.FileDescriptor, f func(protoreflect.Name)) {
@@ -5,8 +5,11 @@
 package protoregistry_test
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -312,3 +315,548 @@ func TestFiles(t *testing.T) {
 		})
 	}
 }
+
+func mustMakeFile(path string, pkg pref.FullName) pref.FileDescriptor {
+	fd, err := ptype.NewFile(&ptype.File{Syntax: pref.Proto2, Path: path, Package: pkg})
+	if err != nil {
+		panic(err)
+	}
+	return fd
+}
+
+func TestFilesClone(t *testing.T) {
+	files := preg.NewFiles(mustMakeFile("a.proto", "fizz"))
+	clone := files.Clone()
+	if err := clone.Register(mustMakeFile("b.proto", "buzz")); err != nil {
+		t.Fatalf("clone Register() = %v, want nil", err)
+	}
+
+	count := func(r *preg.Files) (n int) {
+		r.RangeFiles(func(pref.FileDescriptor) bool { n++; return true })
+		return n
+	}
+	if n := count(clone); n != 2 {
+		t.Errorf("clone has %d files, want 2", n)
+	}
+	if n := count(files); n != 1 {
+		t.Errorf("original has %d files after clone registration, want 1", n)
+	}
+}
+
+func TestFilesConflictError(t *testing.T) {
+	mustMakeEnumFile := func(path string, pkg pref.FullName) pref.FileDescriptor {
+		fd, err := ptype.NewFile(&ptype.File{
+			Syntax:  pref.Proto2,
+			Path:    path,
+			Package: pkg,
+			Enums: []ptype.Enum{{
+				Name:   "Enum",
+				Values: []ptype.EnumValue{{Name: "EnumValue", Number: 0}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("prototype.NewFile() error: %v", err)
+		}
+		return fd
+	}
+
+	var files preg.Files
+	if err := files.Register(mustMakeEnumFile("a.proto", "conf")); err != nil {
+		t.Fatalf("Register() = %v, want nil", err)
+	}
+
+	// A top-level declaration conflict.
+	err := files.Register(mustMakeEnumFile("b.proto", "conf"))
+	ce, ok := err.(*preg.ConflictError)
+	if !ok {
+		t.Fatalf("Register() = %v (%T), want *ConflictError", err, err)
+	}
+	if ce.Name != "conf.Enum" {
+		t.Errorf("ConflictError.Name = %v, want conf.Enum", ce.Name)
+	}
+	if ce.NewFile != "b.proto" {
+		t.Errorf("ConflictError.NewFile = %q, want \"b.proto\"", ce.NewFile)
+	}
+	if ce.OldFile != "a.proto" {
+		t.Errorf("ConflictError.OldFile = %q, want \"a.proto\"", ce.OldFile)
+	}
+	if !strings.Contains(ce.OldSite, "registry_test.go") {
+		t.Errorf("ConflictError.OldSite = %q, want a position in registry_test.go", ce.OldSite)
+	}
+	for _, want := range []string{
+		`file "b.proto" has a name conflict over conf.Enum`,
+		`with file "a.proto"`,
+		`registered at`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Register() error %q does not contain %q", err.Error(), want)
+		}
+	}
+
+	// A package name conflicting with an existing declaration.
+	err = files.Register(mustMakeFile("c.proto", "conf.Enum.sub"))
+	if ce, ok := err.(*preg.ConflictError); !ok {
+		t.Errorf("Register() = %v (%T), want *ConflictError", err, err)
+	} else if ce.Name != "conf.Enum" || ce.OldFile != "a.proto" {
+		t.Errorf("ConflictError = {Name: %v, OldFile: %q}, want {Name: conf.Enum, OldFile: \"a.proto\"}", ce.Name, ce.OldFile)
+	}
+}
+
+func TestFilesSortedRange(t *testing.T) {
+	// Register in an order unrelated to the expected output.
+	files := preg.NewFiles(
+		mustMakeFile("c.proto", "zb"),
+		mustMakeFile("a.proto", "ya.sub"),
+		mustMakeFile("d.proto", "p2"),
+		mustMakeFile("d.proto", "p1"),
+		mustMakeFile("b.proto", "ya"),
+	)
+
+	collect := func(f func(func(pref.FileDescriptor) bool)) (paths []string) {
+		f(func(fd pref.FileDescriptor) bool {
+			paths = append(paths, fd.Path())
+			return true
+		})
+		return paths
+	}
+	eq := func(xs, ys []string) bool {
+		if len(xs) != len(ys) {
+			return false
+		}
+		for i := range xs {
+			if xs[i] != ys[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	want := []string{"a.proto", "b.proto", "c.proto", "d.proto", "d.proto"}
+	if got := collect(files.SortedRangeFiles); !eq(got, want) {
+		t.Errorf("SortedRangeFiles() = %v, want %v", got, want)
+	}
+	got := collect(func(f func(pref.FileDescriptor) bool) { files.SortedRangeFilesByPackage("ya", f) })
+	if want := []string{"a.proto", "b.proto"}; !eq(got, want) {
+		t.Errorf("SortedRangeFilesByPackage(ya) = %v, want %v", got, want)
+	}
+
+	// Files sharing a path are ordered by package name.
+	var pkgs []pref.FullName
+	files.SortedRangeFilesByPath("d.proto", func(fd pref.FileDescriptor) bool {
+		pkgs = append(pkgs, fd.Package())
+		return true
+	})
+	if len(pkgs) != 2 || pkgs[0] != "p1" || pkgs[1] != "p2" {
+		t.Errorf("SortedRangeFilesByPath(d.proto) packages = %v, want [p1 p2]", pkgs)
+	}
+
+	// A child's files come before the parent's, each sorted.
+	child := files.NewChild()
+	if err := child.Register(mustMakeFile("z.proto", "child2"), mustMakeFile("y.proto", "child1")); err != nil {
+		t.Fatalf("child Register() = %v, want nil", err)
+	}
+	want = []string{"y.proto", "z.proto", "a.proto", "b.proto", "c.proto", "d.proto", "d.proto"}
+	if got := collect(child.SortedRangeFiles); !eq(got, want) {
+		t.Errorf("child SortedRangeFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestFilesFindFileByDescriptor(t *testing.T) {
+	dep, err := ptype.NewFile(&ptype.File{
+		Syntax:  pref.Proto2,
+		Path:    "dep.proto",
+		Package: "deps",
+		Enums: []ptype.Enum{{
+			Name:   "Enum",
+			Values: []ptype.EnumValue{{Name: "EnumValue", Number: 0}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("prototype.NewFile() error: %v", err)
+	}
+	user, err := ptype.NewFile(&ptype.File{
+		Syntax:  pref.Proto2,
+		Path:    "user.proto",
+		Package: "users",
+		Imports: []pref.FileImport{{FileDescriptor: dep}},
+	})
+	if err != nil {
+		t.Fatalf("prototype.NewFile() error: %v", err)
+	}
+
+	files := preg.NewFiles(dep, user)
+
+	d, err := files.FindDescriptorByName("deps.Enum")
+	if err != nil {
+		t.Fatalf("FindDescriptorByName(deps.Enum) = %v, want nil", err)
+	}
+	if fd, err := files.FindFileByDescriptor(d); err != nil || fd != dep {
+		t.Errorf("FindFileByDescriptor(deps.Enum) = (%v, %v), want (dep.proto, nil)", fd, err)
+	}
+	if fd, err := files.FindFileByDescriptor(dep); err != nil || fd != dep {
+		t.Errorf("FindFileByDescriptor(dep.proto) = (%v, %v), want (dep.proto, nil)", fd, err)
+	}
+	if _, err := files.FindFileByDescriptor(mustMakeFile("other.proto", "other")); err != preg.NotFound {
+		t.Errorf("FindFileByDescriptor(unregistered file) = %v, want NotFound", err)
+	}
+
+	// A child registry falls back to the parent.
+	if fd, err := files.NewChild().FindFileByDescriptor(d); err != nil || fd != dep {
+		t.Errorf("child FindFileByDescriptor(deps.Enum) = (%v, %v), want (dep.proto, nil)", fd, err)
+	}
+
+	dependents := func(r *preg.Files, path string) (paths []string) {
+		r.RangeDependents(path, func(fd pref.FileDescriptor) bool {
+			paths = append(paths, fd.Path())
+			return true
+		})
+		return paths
+	}
+	if got := dependents(files, "dep.proto"); len(got) != 1 || got[0] != "user.proto" {
+		t.Errorf("RangeDependents(dep.proto) = %v, want [user.proto]", got)
+	}
+	if got := dependents(files, "user.proto"); len(got) != 0 {
+		t.Errorf("RangeDependents(user.proto) = %v, want none", got)
+	}
+
+	// Dependents in a child registry are combined with the parent's.
+	child := files.NewChild()
+	user2, err := ptype.NewFile(&ptype.File{
+		Syntax:  pref.Proto2,
+		Path:    "user2.proto",
+		Package: "users.two",
+		Imports: []pref.FileImport{{FileDescriptor: dep}},
+	})
+	if err != nil {
+		t.Fatalf("prototype.NewFile() error: %v", err)
+	}
+	if err := child.Register(user2); err != nil {
+		t.Fatalf("child Register() = %v, want nil", err)
+	}
+	if got := dependents(child, "dep.proto"); len(got) != 2 {
+		t.Errorf("child RangeDependents(dep.proto) = %v, want 2 files", got)
+	}
+}
+
+func TestFilesDeregister(t *testing.T) {
+	fd, err := ptype.NewFile(&ptype.File{
+		Syntax:  pref.Proto2,
+		Path:    "test.proto",
+		Package: "foo.bar",
+		Enums: []ptype.Enum{{
+			Name:   "Enum",
+			Values: []ptype.EnumValue{{Name: "EnumValue", Number: 0}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("prototype.NewFile() error: %v", err)
+	}
+
+	var files preg.Files
+	if err := files.Register(fd); err != nil {
+		t.Fatalf("Register() = %v, want nil", err)
+	}
+	if err := files.Deregister(fd); err != nil {
+		t.Fatalf("Deregister() = %v, want nil", err)
+	}
+	if err := files.Deregister(fd); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("Deregister(unregistered) = %v, want not-registered error", err)
+	}
+
+	if d, err := files.FindDescriptorByName("foo.bar.Enum"); err != preg.NotFound {
+		t.Errorf("FindDescriptorByName(foo.bar.Enum) = (%v, %v), want (nil, NotFound)", d, err)
+	}
+	var n int
+	files.RangeFilesByPath("test.proto", func(pref.FileDescriptor) bool { n++; return true })
+	if n != 0 {
+		t.Errorf("RangeFilesByPath found %d files after Deregister, want 0", n)
+	}
+
+	// Deregistration must prune the package tree so that names that
+	// previously conflicted become available again.
+	if err := files.Register(mustMakeFile("clash.proto", "foo.bar.Enum.sub")); err != nil {
+		t.Errorf("Register(previously conflicting package) = %v, want nil", err)
+	}
+}
+
+func TestFilesNewChild(t *testing.T) {
+	parent := preg.NewFiles(mustMakeFile("parent.proto", "parent.pkg"))
+	child := parent.NewChild()
+	if err := child.Register(mustMakeFile("child.proto", "child.pkg")); err != nil {
+		t.Fatalf("child Register() = %v, want nil", err)
+	}
+
+	// The child sees both registries; the parent sees only its own.
+	count := func(r *preg.Files) (n int) {
+		r.RangeFiles(func(pref.FileDescriptor) bool { n++; return true })
+		return n
+	}
+	if n := count(child); n != 2 {
+		t.Errorf("child has %d files, want 2", n)
+	}
+	if n := count(parent); n != 1 {
+		t.Errorf("parent has %d files, want 1", n)
+	}
+	if _, err := child.FindDescriptorByName("parent.pkg"); err != preg.NotFound {
+		// Packages are never returned as descriptors; just make sure
+		// the lookup recursion terminates.
+		t.Errorf("child FindDescriptorByName(parent.pkg) error = %v, want NotFound", err)
+	}
+
+	// Tearing down the child must not affect the parent.
+	child.RangeFilesByPath("child.proto", func(fd pref.FileDescriptor) bool {
+		if err := child.Deregister(fd); err != nil {
+			t.Errorf("child Deregister() = %v, want nil", err)
+		}
+		return true
+	})
+	if n := count(child); n != 1 {
+		t.Errorf("child has %d files after Deregister, want 1 (the parent's)", n)
+	}
+}
+
+func TestFilesConcurrency(t *testing.T) {
+	// Register files while Find and Range operations are in flight.
+	// This test is primarily meaningful under the race detector.
+	var files preg.Files
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		fd := mustMakeFile(fmt.Sprintf("file%d.proto", i), pref.FullName(fmt.Sprintf("pkg%d", i)))
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			files.Register(fd)
+		}()
+		go func() {
+			defer wg.Done()
+			files.RangeFiles(func(pref.FileDescriptor) bool { return true })
+			files.FindDescriptorByName("pkg0.Nothing")
+		}()
+	}
+	wg.Wait()
+
+	var n int
+	files.RangeFiles(func(pref.FileDescriptor) bool { n++; return true })
+	if n != 10 {
+		t.Errorf("registered %d files, want 10", n)
+	}
+}
+
+// testExtension wraps an extension descriptor with stub Go type information
+// to satisfy protoreflect.ExtensionType.
+type testExtension struct {
+	pref.ExtensionDescriptor
+}
+
+func (x testExtension) GoNew() interface{}                   { return nil }
+func (x testExtension) GoType() reflect.Type                 { return nil }
+func (x testExtension) ValueOf(v interface{}) pref.Value     { return pref.ValueOf(v) }
+func (x testExtension) InterfaceOf(v pref.Value) interface{} { return v.Interface() }
+
+// testDescriptor is a descriptor type that is none of an enum, message,
+// or extension type.
+type testDescriptor struct {
+	pref.Descriptor
+}
+
+func (testDescriptor) GoType() reflect.Type { return nil }
+
+func mustMakeEnumType(name pref.FullName) pref.EnumType {
+	ed, err := ptype.NewEnum(&ptype.StandaloneEnum{
+		Syntax:   pref.Proto2,
+		FullName: name,
+		Values:   []ptype.EnumValue{{Name: "ZERO", Number: 0}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ptype.NewGoEnum(&ptype.GoEnum{
+		EnumDescriptor: ed,
+		New:            func(pref.EnumType, pref.EnumNumber) pref.ProtoEnum { return nil },
+	})
+}
+
+func mustMakeMessageType(name pref.FullName) pref.MessageType {
+	md, err := ptype.NewMessage(&ptype.StandaloneMessage{
+		Syntax:          pref.Proto2,
+		FullName:        name,
+		ExtensionRanges: [][2]pref.FieldNumber{{100, 200}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ptype.NewGoMessage(&ptype.GoMessage{
+		MessageDescriptor: md,
+		New:               func(pref.MessageType) pref.ProtoMessage { return nil },
+	})
+}
+
+func mustMakeExtensionType(name pref.FullName, n pref.FieldNumber, extendee pref.FullName) pref.ExtensionType {
+	xd, err := ptype.NewExtension(&ptype.StandaloneExtension{
+		Syntax:       pref.Proto2,
+		FullName:     name,
+		Number:       n,
+		Cardinality:  pref.Optional,
+		Kind:         pref.BoolKind,
+		ExtendedType: ptype.PlaceholderMessage(extendee),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return testExtension{xd}
+}
+
+func TestTypes(t *testing.T) {
+	et1 := mustMakeEnumType("testprotos.Enum1")
+	mt1 := mustMakeMessageType("testprotos.Message1")
+	mt2 := mustMakeMessageType("testprotos.Message2")
+	xt1 := mustMakeExtensionType("testprotos.field_one", 100, "testprotos.Message1")
+	xt2 := mustMakeExtensionType("testprotos.field_two", 101, "testprotos.Message1")
+
+	types := preg.NewTypes(et1, mt1, mt2, xt1, xt2)
+
+	// Registration conflicts are reported, and first wins.
+	if err := types.Register(mustMakeMessageType("testprotos.Message1")); err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("Register(duplicate name) = %v, want already-registered error", err)
+	}
+	if err := types.Register(mustMakeExtensionType("testprotos.field_too", 100, "testprotos.Message1")); err == nil || !strings.Contains(err.Error(), "already registered on message") {
+		t.Errorf("Register(duplicate extension number) = %v, want already-registered error", err)
+	}
+	if err := types.Register(testDescriptor{}); err == nil || !strings.Contains(err.Error(), "invalid type") {
+		t.Errorf("Register(invalid type) = %v, want invalid-type error", err)
+	}
+
+	// Name lookups are filtered by kind.
+	if et, err := types.FindEnumByName("testprotos.Enum1"); et == nil || err != nil {
+		t.Errorf("FindEnumByName(Enum1) = (%v, %v), want (non-nil, nil)", et, err)
+	}
+	if _, err := types.FindEnumByName("testprotos.Message1"); err != preg.NotFound {
+		t.Errorf("FindEnumByName(Message1) error = %v, want NotFound", err)
+	}
+	if mt, err := types.FindMessageByName("testprotos.Message1"); mt == nil || err != nil {
+		t.Errorf("FindMessageByName(Message1) = (%v, %v), want (non-nil, nil)", mt, err)
+	}
+	if xt, err := types.FindExtensionByName("testprotos.field_one"); xt == nil || err != nil {
+		t.Errorf("FindExtensionByName(field_one) = (%v, %v), want (non-nil, nil)", xt, err)
+	}
+	if xt, err := types.FindExtensionByNumber("testprotos.Message1", 101); xt == nil || err != nil {
+		t.Errorf("FindExtensionByNumber(Message1, 101) = (%v, %v), want (non-nil, nil)", xt, err)
+	}
+	if _, err := types.FindExtensionByNumber("testprotos.Message1", 102); err != preg.NotFound {
+		t.Errorf("FindExtensionByNumber(Message1, 102) error = %v, want NotFound", err)
+	}
+
+	// URL lookups use the name after the last slash.
+	for _, url := range []string{
+		"testprotos.Message2",
+		"type.googleapis.com/testprotos.Message2",
+		"totally.invalid.example.com/testprotos.Message2",
+	} {
+		if mt, err := types.FindMessageByURL(url); mt == nil || err != nil {
+			t.Errorf("FindMessageByURL(%v) = (%v, %v), want (non-nil, nil)", url, mt, err)
+		}
+	}
+	if _, err := types.FindMessageByURL("testprotos.Enum1"); err != preg.NotFound {
+		t.Errorf("FindMessageByURL(Enum1) error = %v, want NotFound", err)
+	}
+
+	// A custom Resolver alone determines URL lookups.
+	resolved := &preg.Types{Resolver: func(url string) (preg.Type, error) {
+		if url == "faraway.example.com/testprotos.Message1" {
+			return mt1, nil
+		}
+		return nil, preg.NotFound
+	}}
+	if mt, err := resolved.FindMessageByURL("faraway.example.com/testprotos.Message1"); mt == nil || err != nil {
+		t.Errorf("FindMessageByURL(resolver hit) = (%v, %v), want (non-nil, nil)", mt, err)
+	}
+	if _, err := resolved.FindMessageByURL("testprotos.Message2"); err != preg.NotFound {
+		t.Errorf("FindMessageByURL(resolver miss) error = %v, want NotFound", err)
+	}
+
+	// A URLResolver maps custom URL prefixes to registered names.
+	urlErr := errors.New("unrecognized prefix")
+	renamed := &preg.Types{URLResolver: func(url string) (pref.FullName, error) {
+		const prefix = "corp.example.com/v2/"
+		if !strings.HasPrefix(url, prefix) {
+			return "", urlErr
+		}
+		return pref.FullName(strings.TrimPrefix(url, prefix)), nil
+	}}
+	if err := renamed.Register(mt1); err != nil {
+		t.Errorf("Register() = %v, want nil", err)
+	}
+	if mt, err := renamed.FindMessageByURL("corp.example.com/v2/testprotos.Message1"); mt == nil || err != nil {
+		t.Errorf("FindMessageByURL(URLResolver hit) = (%v, %v), want (non-nil, nil)", mt, err)
+	}
+	if _, err := renamed.FindMessageByURL("type.googleapis.com/testprotos.Message1"); err != urlErr {
+		t.Errorf("FindMessageByURL(URLResolver miss) error = %v, want %v", err, urlErr)
+	}
+
+	// A child registry falls back to its parent for finds and ranges.
+	child := &preg.Types{Parent: types}
+	if err := child.Register(mustMakeMessageType("testprotos.Message3")); err != nil {
+		t.Errorf("child Register() = %v, want nil", err)
+	}
+	if mt, err := child.FindMessageByName("testprotos.Message1"); mt == nil || err != nil {
+		t.Errorf("child FindMessageByName(Message1) = (%v, %v), want (non-nil, nil)", mt, err)
+	}
+	if _, err := types.FindMessageByName("testprotos.Message3"); err != preg.NotFound {
+		t.Errorf("parent FindMessageByName(Message3) error = %v, want NotFound", err)
+	}
+
+	counts := func(r *preg.Types) (enums, messages, extensions, byMessage int) {
+		r.RangeEnums(func(pref.EnumType) bool { enums++; return true })
+		r.RangeMessages(func(pref.MessageType) bool { messages++; return true })
+		r.RangeExtensions(func(pref.ExtensionType) bool { extensions++; return true })
+		r.RangeExtensionsByMessage("testprotos.Message1", func(pref.ExtensionType) bool { byMessage++; return true })
+		return
+	}
+	if enums, messages, extensions, byMessage := counts(types); enums != 1 || messages != 2 || extensions != 2 || byMessage != 2 {
+		t.Errorf("Range counts = (%v, %v, %v, %v), want (1, 2, 2, 2)", enums, messages, extensions, byMessage)
+	}
+	if _, messages, _, _ := counts(child); messages != 3 {
+		t.Errorf("child Range messages = %v, want 3 (including parent)", messages)
+	}
+}
+
+func TestTypesFindExtensionsInRange(t *testing.T) {
+	types := preg.NewTypes(
+		mustMakeExtensionType("testprotos.field_one", 100, "testprotos.Message1"),
+		mustMakeExtensionType("testprotos.field_two", 101, "testprotos.Message1"),
+		mustMakeExtensionType("testprotos.field_three", 150, "testprotos.Message1"),
+		mustMakeExtensionType("testprotos.other_field", 100, "testprotos.Message2"),
+	)
+
+	numbers := func(xts []pref.ExtensionType) (ns []pref.FieldNumber) {
+		for _, xt := range xts {
+			ns = append(ns, xt.Number())
+		}
+		return ns
+	}
+	got := numbers(types.FindExtensionsInRange("testprotos.Message1", 100, 200))
+	if want := []pref.FieldNumber{100, 101, 150}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindExtensionsInRange(Message1, 100, 200) numbers = %v, want %v", got, want)
+	}
+	got = numbers(types.FindExtensionsInRange("testprotos.Message1", 101, 149))
+	if want := []pref.FieldNumber{101}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FindExtensionsInRange(Message1, 101, 149) numbers = %v, want %v", got, want)
+	}
+	if xts := types.FindExtensionsInRange("testprotos.Message3", 0, 1000); xts != nil {
+		t.Errorf("FindExtensionsInRange(Message3, 0, 1000) = %v, want nil", xts)
+	}
+
+	// A child extension on the same number shadows the parent's.
+	shadow := mustMakeExtensionType("testprotos.shadow_field", 101, "testprotos.Message1")
+	child := &preg.Types{Parent: types}
+	if err := child.Register(shadow); err != nil {
+		t.Fatalf("child Register() = %v, want nil", err)
+	}
+	xts := child.FindExtensionsInRange("testprotos.Message1", 100, 200)
+	if got := numbers(xts); !reflect.DeepEqual(got, []pref.FieldNumber{100, 101, 150}) {
+		t.Fatalf("child FindExtensionsInRange(Message1, 100, 200) numbers = %v, want [100 101 150]", got)
+	}
+	if xts[1] != shadow {
+		t.Errorf("child FindExtensionsInRange(Message1, 100, 200) returned the parent's extension for 101, want the child's")
+	}
+}
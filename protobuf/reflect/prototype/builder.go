@@ -0,0 +1,72 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototype
+
+import (
+	"github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+// FileBuilder incrementally constructs a File so that a FileDescriptor with
+// nested messages, enums, services, and cross-references can be assembled
+// without hand-wiring the File literal. Declarations within the file may
+// reference each other using placeholder descriptors (see PlaceholderMessage
+// and PlaceholderEnum); Resolve replaces the placeholders with the concrete
+// types being declared.
+//
+// The methods return the receiver so that calls can be chained:
+//
+//	fd, err := NewFileBuilder(protoreflect.Proto2, "foo.proto", "foo").
+//		AddEnum(Enum{...}).
+//		AddMessage(Message{...}).
+//		Resolve()
+type FileBuilder struct {
+	file File
+}
+
+// NewFileBuilder returns a builder for a proto file with the given syntax,
+// file path, and proto package.
+func NewFileBuilder(syntax protoreflect.Syntax, path string, pkg protoreflect.FullName) *FileBuilder {
+	return &FileBuilder{file: File{Syntax: syntax, Path: path, Package: pkg}}
+}
+
+// AddImport appends an imported proto file.
+func (b *FileBuilder) AddImport(imp protoreflect.FileImport) *FileBuilder {
+	b.file.Imports = append(b.file.Imports, imp)
+	return b
+}
+
+// AddMessage appends a top-level message declaration.
+func (b *FileBuilder) AddMessage(m Message) *FileBuilder {
+	b.file.Messages = append(b.file.Messages, m)
+	return b
+}
+
+// AddEnum appends a top-level enum declaration.
+func (b *FileBuilder) AddEnum(e Enum) *FileBuilder {
+	b.file.Enums = append(b.file.Enums, e)
+	return b
+}
+
+// AddExtension appends a top-level extension declaration.
+func (b *FileBuilder) AddExtension(x Extension) *FileBuilder {
+	b.file.Extensions = append(b.file.Extensions, x)
+	return b
+}
+
+// AddService appends a top-level service declaration.
+func (b *FileBuilder) AddService(s Service) *FileBuilder {
+	b.file.Services = append(b.file.Services, s)
+	return b
+}
+
+// Resolve validates the accumulated declarations, resolving placeholder
+// references to types declared within the same file, and returns an
+// immutable FileDescriptor.
+//
+// Resolve takes ownership of the values added to the builder; the builder
+// must not be used after calling it.
+func (b *FileBuilder) Resolve() (protoreflect.FileDescriptor, error) {
+	return NewFile(&b.file)
+}
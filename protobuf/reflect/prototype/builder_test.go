@@ -0,0 +1,75 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototype
+
+import (
+	"testing"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+func TestFileBuilder(t *testing.T) {
+	fd, err := NewFileBuilder(pref.Proto2, "path/to/built.proto", "built").
+		AddEnum(Enum{
+			Name:   "Color", // "built.Color"
+			Values: []EnumValue{{Name: "RED", Number: 0}},
+		}).
+		AddMessage(Message{
+			Name: "Shape", // "built.Shape"
+			Fields: []Field{{
+				Name:        "color",
+				Number:      1,
+				Cardinality: pref.Optional,
+				Kind:        pref.EnumKind,
+				EnumType:    PlaceholderEnum("built.Color"),
+			}, {
+				Name:        "next",
+				Number:      2,
+				Cardinality: pref.Optional,
+				Kind:        pref.MessageKind,
+				MessageType: PlaceholderMessage("built.Shape"),
+			}},
+			ExtensionRanges: [][2]pref.FieldNumber{{100, 200}},
+		}).
+		AddExtension(Extension{
+			Name:         "label", // "built.label"
+			Number:       100,
+			Cardinality:  pref.Optional,
+			Kind:         pref.StringKind,
+			ExtendedType: PlaceholderMessage("built.Shape"),
+		}).
+		AddService(Service{
+			Name: "Drawer", // "built.Drawer"
+			Methods: []Method{{
+				Name:       "Draw",
+				InputType:  PlaceholderMessage("built.Shape"),
+				OutputType: PlaceholderMessage("built.Shape"),
+			}},
+		}).
+		Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	// Placeholder references must resolve to the declared types.
+	md := fd.Messages().Get(0)
+	if et := md.Fields().ByName("color").EnumType(); et.IsPlaceholder() {
+		t.Errorf("field built.Shape.color resolved to a placeholder, want built.Color")
+	}
+	if mt := md.Fields().ByName("next").MessageType(); mt.IsPlaceholder() {
+		t.Errorf("field built.Shape.next resolved to a placeholder, want built.Shape")
+	}
+	if d := fd.DescriptorByName("built.Drawer.Draw"); d == nil {
+		t.Errorf("DescriptorByName(built.Drawer.Draw) = nil, want method descriptor")
+	}
+
+	// Validation failures surface through Resolve.
+	_, err = NewFileBuilder(pref.Proto2, "bad.proto", "bad").
+		AddMessage(Message{Name: "1nvalid"}).
+		Resolve()
+	if err == nil {
+		t.Errorf("Resolve() with an invalid message name succeeded, want error")
+	}
+}
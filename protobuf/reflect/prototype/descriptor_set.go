@@ -0,0 +1,324 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototype
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	protoV1 "github.com/golang/protobuf/proto"
+	descriptorV1 "github.com/golang/protobuf/protoc-gen-go/descriptor"
+
+	"github.com/golang/protobuf/v2/internal/errors"
+	"github.com/golang/protobuf/v2/reflect/protoreflect"
+	"github.com/golang/protobuf/v2/reflect/protoregistry"
+)
+
+// NewFilesFromDescriptorSet creates a new protoregistry.Files from the
+// provided descriptor set. The files may appear in any order; each file is
+// converted after the files it depends on so that references across files
+// resolve to the actual descriptors. Dependencies on files absent from the
+// set are resolved as placeholders.
+func NewFilesFromDescriptorSet(fs *descriptorV1.FileDescriptorSet) (*protoregistry.Files, error) {
+	r := new(protoregistry.Files)
+	inSet := make(map[string]bool)
+	for _, fd := range fs.GetFile() {
+		inSet[fd.GetName()] = true
+	}
+	done := make(map[string]bool)
+	pending := fs.GetFile()
+	for len(pending) > 0 {
+		var next []*descriptorV1.FileDescriptorProto
+	fileLoop:
+		for _, fd := range pending {
+			for _, dep := range fd.GetDependency() {
+				if inSet[dep] && !done[dep] {
+					next = append(next, fd)
+					continue fileLoop
+				}
+			}
+			f, err := NewFileFromDescriptorProto(fd, r)
+			if err != nil {
+				return nil, err
+			}
+			if err := r.Register(f); err != nil {
+				return nil, err
+			}
+			done[fd.GetName()] = true
+		}
+		if len(next) == len(pending) {
+			return nil, errors.New("descriptor set has an import cycle involving %q", next[0].GetName())
+		}
+		pending = next
+	}
+	return r, nil
+}
+
+// ToFileDescriptorSet returns the files of the registry as a descriptor set,
+// suitable for serialization and reloading with NewFilesFromDescriptorSet.
+// The files are ordered by path so that the output is reproducible.
+//
+// The conversion records only what the descriptor model itself records;
+// in particular, declaration options other than those surfaced by the
+// protoreflect interfaces (e.g., map_entry and packed) are not retained.
+func ToFileDescriptorSet(r *protoregistry.Files) *descriptorV1.FileDescriptorSet {
+	fs := new(descriptorV1.FileDescriptorSet)
+	r.SortedRangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		fs.File = append(fs.File, ToFileDescriptorProto(fd))
+		return true
+	})
+	return fs
+}
+
+// ToFileDescriptorProto returns the file as a FileDescriptorProto so that
+// descriptors can be served back over the wire. The output is accepted by
+// NewFileFromDescriptorProto and round-trips the declarations, source
+// locations, and the options surfaced by the protoreflect interfaces
+// (e.g., map_entry, packed, and weak); other options and reserved ranges
+// are not represented in the descriptor model and are therefore dropped.
+func ToFileDescriptorProto(fd protoreflect.FileDescriptor) *descriptorV1.FileDescriptorProto {
+	p := &descriptorV1.FileDescriptorProto{
+		Name: protoV1.String(fd.Path()),
+	}
+	if fd.Package() != "" {
+		p.Package = protoV1.String(string(fd.Package()))
+	}
+	if fd.Syntax() == protoreflect.Proto3 {
+		p.Syntax = protoV1.String("proto3")
+	}
+	imps := fd.Imports()
+	for i := 0; i < imps.Len(); i++ {
+		imp := imps.Get(i)
+		p.Dependency = append(p.Dependency, imp.Path())
+		if imp.IsPublic {
+			p.PublicDependency = append(p.PublicDependency, int32(i))
+		}
+		if imp.IsWeak {
+			p.WeakDependency = append(p.WeakDependency, int32(i))
+		}
+	}
+	for i := 0; i < fd.Messages().Len(); i++ {
+		p.MessageType = append(p.MessageType, toDescriptorProto(fd.Messages().Get(i)))
+	}
+	for i := 0; i < fd.Enums().Len(); i++ {
+		p.EnumType = append(p.EnumType, toEnumDescriptorProto(fd.Enums().Get(i)))
+	}
+	for i := 0; i < fd.Extensions().Len(); i++ {
+		p.Extension = append(p.Extension, toFieldDescriptorProto(fd.Extensions().Get(i)))
+	}
+	for i := 0; i < fd.Services().Len(); i++ {
+		p.Service = append(p.Service, toServiceDescriptorProto(fd.Services().Get(i)))
+	}
+	p.SourceCodeInfo = toSourceCodeInfo(fd.SourceLocations())
+	return p
+}
+
+func toSourceCodeInfo(ls protoreflect.SourceLocations) *descriptorV1.SourceCodeInfo {
+	if ls.Len() == 0 {
+		return nil
+	}
+	info := new(descriptorV1.SourceCodeInfo)
+	for i := 0; i < ls.Len(); i++ {
+		l := ls.Get(i)
+		lp := &descriptorV1.SourceCodeInfo_Location{
+			Path:                    []int32(l.Path),
+			LeadingDetachedComments: l.LeadingDetachedComments,
+		}
+		if l.EndLine == l.StartLine {
+			lp.Span = []int32{int32(l.StartLine), int32(l.StartColumn), int32(l.EndColumn)}
+		} else {
+			lp.Span = []int32{int32(l.StartLine), int32(l.StartColumn), int32(l.EndLine), int32(l.EndColumn)}
+		}
+		if l.LeadingComments != "" {
+			lp.LeadingComments = protoV1.String(l.LeadingComments)
+		}
+		if l.TrailingComments != "" {
+			lp.TrailingComments = protoV1.String(l.TrailingComments)
+		}
+		info.Location = append(info.Location, lp)
+	}
+	return info
+}
+
+func toDescriptorProto(md protoreflect.MessageDescriptor) *descriptorV1.DescriptorProto {
+	p := &descriptorV1.DescriptorProto{
+		Name: protoV1.String(string(md.Name())),
+	}
+	for i := 0; i < md.Fields().Len(); i++ {
+		p.Field = append(p.Field, toFieldDescriptorProto(md.Fields().Get(i)))
+	}
+	for i := 0; i < md.Oneofs().Len(); i++ {
+		p.OneofDecl = append(p.OneofDecl, &descriptorV1.OneofDescriptorProto{
+			Name: protoV1.String(string(md.Oneofs().Get(i).Name())),
+		})
+	}
+	for i := 0; i < md.ExtensionRanges().Len(); i++ {
+		r := md.ExtensionRanges().Get(i)
+		p.ExtensionRange = append(p.ExtensionRange, &descriptorV1.DescriptorProto_ExtensionRange{
+			Start: protoV1.Int32(int32(r[0])),
+			End:   protoV1.Int32(int32(r[1])),
+		})
+	}
+	if md.IsMapEntry() {
+		p.Options = &descriptorV1.MessageOptions{MapEntry: protoV1.Bool(true)}
+	}
+	for i := 0; i < md.Messages().Len(); i++ {
+		p.NestedType = append(p.NestedType, toDescriptorProto(md.Messages().Get(i)))
+	}
+	for i := 0; i < md.Enums().Len(); i++ {
+		p.EnumType = append(p.EnumType, toEnumDescriptorProto(md.Enums().Get(i)))
+	}
+	for i := 0; i < md.Extensions().Len(); i++ {
+		p.Extension = append(p.Extension, toFieldDescriptorProto(md.Extensions().Get(i)))
+	}
+	return p
+}
+
+func toFieldDescriptorProto(fd protoreflect.FieldDescriptor) *descriptorV1.FieldDescriptorProto {
+	p := &descriptorV1.FieldDescriptorProto{
+		Name:     protoV1.String(string(fd.Name())),
+		Number:   protoV1.Int32(int32(fd.Number())),
+		Label:    descriptorV1.FieldDescriptorProto_Label(fd.Cardinality()).Enum(),
+		Type:     descriptorV1.FieldDescriptorProto_Type(fd.Kind()).Enum(),
+		JsonName: protoV1.String(fd.JSONName()),
+	}
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		p.TypeName = protoV1.String("." + string(fd.EnumType().FullName()))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		p.TypeName = protoV1.String("." + string(fd.MessageType().FullName()))
+	}
+	if xt := fd.ExtendedType(); xt != nil {
+		p.Extendee = protoV1.String("." + string(xt.FullName()))
+	}
+	if fd.HasDefault() {
+		p.DefaultValue = protoV1.String(defaultValueString(fd))
+	}
+	if od := fd.OneofType(); od != nil {
+		p.OneofIndex = protoV1.Int32(int32(od.Index()))
+	}
+	var opts *descriptorV1.FieldOptions
+	if fd.IsPacked() {
+		opts = &descriptorV1.FieldOptions{Packed: protoV1.Bool(true)}
+	} else if fd.Syntax() == protoreflect.Proto3 &&
+		fd.Cardinality() == protoreflect.Repeated && isScalarNumeric[fd.Kind()] {
+		// In proto3, such fields are packed by default, so the option
+		// must be recorded explicitly to preserve the unpacked setting.
+		opts = &descriptorV1.FieldOptions{Packed: protoV1.Bool(false)}
+	}
+	if fd.IsWeak() {
+		if opts == nil {
+			opts = new(descriptorV1.FieldOptions)
+		}
+		opts.Weak = protoV1.Bool(true)
+	}
+	p.Options = opts
+	return p
+}
+
+func toEnumDescriptorProto(ed protoreflect.EnumDescriptor) *descriptorV1.EnumDescriptorProto {
+	p := &descriptorV1.EnumDescriptorProto{
+		Name: protoV1.String(string(ed.Name())),
+	}
+	for i := 0; i < ed.Values().Len(); i++ {
+		vd := ed.Values().Get(i)
+		p.Value = append(p.Value, &descriptorV1.EnumValueDescriptorProto{
+			Name:   protoV1.String(string(vd.Name())),
+			Number: protoV1.Int32(int32(vd.Number())),
+		})
+	}
+	return p
+}
+
+func toServiceDescriptorProto(sd protoreflect.ServiceDescriptor) *descriptorV1.ServiceDescriptorProto {
+	p := &descriptorV1.ServiceDescriptorProto{
+		Name: protoV1.String(string(sd.Name())),
+	}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+		mp := &descriptorV1.MethodDescriptorProto{
+			Name:       protoV1.String(string(md.Name())),
+			InputType:  protoV1.String("." + string(md.InputType().FullName())),
+			OutputType: protoV1.String("." + string(md.OutputType().FullName())),
+		}
+		if md.IsStreamingClient() {
+			mp.ClientStreaming = protoV1.Bool(true)
+		}
+		if md.IsStreamingServer() {
+			mp.ServerStreaming = protoV1.Bool(true)
+		}
+		p.Method = append(p.Method, mp)
+	}
+	return p
+}
+
+// defaultValueString formats the default value of a field in the form
+// expected by parseDefault.
+func defaultValueString(fd protoreflect.FieldDescriptor) string {
+	v := fd.Default()
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool())
+	case protoreflect.EnumKind:
+		// Default values record the name of the enum value.
+		if vd := fd.EnumType().Values().ByNumber(v.Enum()); vd != nil {
+			return string(vd.Name())
+		}
+		return strconv.FormatInt(int64(v.Enum()), 10)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(v.Int(), 10)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(v.Uint(), 10)
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return "nan"
+		case math.IsInf(f, +1):
+			return "inf"
+		case math.IsInf(f, -1):
+			return "-inf"
+		}
+		bits := 64
+		if fd.Kind() == protoreflect.FloatKind {
+			bits = 32
+		}
+		return strconv.FormatFloat(f, 'g', -1, bits)
+	case protoreflect.StringKind:
+		// String values are stored unescaped and can be used as is.
+		return v.String()
+	case protoreflect.BytesKind:
+		// Bytes values use the same escaping as the text format,
+		// however they lack the surrounding double quotes.
+		var b []byte
+		for _, c := range v.Bytes() {
+			switch c {
+			case '\n':
+				b = append(b, `\n`...)
+			case '\r':
+				b = append(b, `\r`...)
+			case '\t':
+				b = append(b, `\t`...)
+			case '"':
+				b = append(b, `\"`...)
+			case '\'':
+				b = append(b, `\'`...)
+			case '\\':
+				b = append(b, `\\`...)
+			default:
+				if c >= 0x20 && c <= 0x7e {
+					b = append(b, c)
+				} else {
+					b = append(b, fmt.Sprintf(`\x%02x`, c)...)
+				}
+			}
+		}
+		return string(b)
+	}
+	return ""
+}
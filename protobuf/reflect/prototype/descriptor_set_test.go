@@ -0,0 +1,193 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package prototype
+
+import (
+	"strings"
+	"testing"
+
+	protoV1 "github.com/golang/protobuf/proto"
+	descriptorV1 "github.com/golang/protobuf/protoc-gen-go/descriptor"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+func TestDescriptorSetRoundTrip(t *testing.T) {
+	dep := &descriptorV1.FileDescriptorProto{
+		Name:    protoV1.String("test/dep.proto"),
+		Package: protoV1.String("testdeps"),
+		EnumType: []*descriptorV1.EnumDescriptorProto{{
+			Name: protoV1.String("Enum"),
+			Value: []*descriptorV1.EnumValueDescriptorProto{{
+				Name:   protoV1.String("DEFAULT"),
+				Number: protoV1.Int32(0),
+			}},
+		}},
+		MessageType: []*descriptorV1.DescriptorProto{{
+			Name: protoV1.String("DepMessage"),
+			Field: []*descriptorV1.FieldDescriptorProto{{
+				Name:     protoV1.String("f1"),
+				Number:   protoV1.Int32(1),
+				Label:    descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorV1.FieldDescriptorProto_TYPE_INT32.Enum(),
+				JsonName: protoV1.String("f1"),
+			}},
+			ExtensionRange: []*descriptorV1.DescriptorProto_ExtensionRange{
+				{Start: protoV1.Int32(100), End: protoV1.Int32(200)},
+			},
+		}},
+	}
+	user := &descriptorV1.FileDescriptorProto{
+		Name:       protoV1.String("test/user.proto"),
+		Package:    protoV1.String("testusers"),
+		Dependency: []string{"test/dep.proto"},
+		MessageType: []*descriptorV1.DescriptorProto{{
+			Name: protoV1.String("UserMessage"),
+			Field: []*descriptorV1.FieldDescriptorProto{{
+				Name:     protoV1.String("dep"),
+				Number:   protoV1.Int32(1),
+				Label:    descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorV1.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: protoV1.String(".testdeps.DepMessage"),
+				JsonName: protoV1.String("dep"),
+			}, {
+				Name:         protoV1.String("enum"),
+				Number:       protoV1.Int32(2),
+				Label:        descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:         descriptorV1.FieldDescriptorProto_TYPE_ENUM.Enum(),
+				TypeName:     protoV1.String(".testdeps.Enum"),
+				DefaultValue: protoV1.String("DEFAULT"),
+				JsonName:     protoV1.String("enum"),
+			}, {
+				Name:         protoV1.String("bytes"),
+				Number:       protoV1.Int32(3),
+				Label:        descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:         descriptorV1.FieldDescriptorProto_TYPE_BYTES.Enum(),
+				DefaultValue: protoV1.String(`ab\x00\"\\`),
+				JsonName:     protoV1.String("bytes"),
+			}, {
+				Name:     protoV1.String("packed"),
+				Number:   protoV1.Int32(4),
+				Label:    descriptorV1.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptorV1.FieldDescriptorProto_TYPE_INT32.Enum(),
+				Options:  &descriptorV1.FieldOptions{Packed: protoV1.Bool(true)},
+				JsonName: protoV1.String("packed"),
+			}, {
+				Name:       protoV1.String("choice"),
+				Number:     protoV1.Int32(5),
+				Label:      descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:       descriptorV1.FieldDescriptorProto_TYPE_STRING.Enum(),
+				OneofIndex: protoV1.Int32(0),
+				JsonName:   protoV1.String("choice"),
+			}},
+			OneofDecl: []*descriptorV1.OneofDescriptorProto{{
+				Name: protoV1.String("union"),
+			}},
+		}},
+		Extension: []*descriptorV1.FieldDescriptorProto{{
+			Name:     protoV1.String("ext"),
+			Number:   protoV1.Int32(100),
+			Label:    descriptorV1.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorV1.FieldDescriptorProto_TYPE_INT32.Enum(),
+			Extendee: protoV1.String(".testdeps.DepMessage"),
+			JsonName: protoV1.String("ext"),
+		}},
+		Service: []*descriptorV1.ServiceDescriptorProto{{
+			Name: protoV1.String("UserService"),
+			Method: []*descriptorV1.MethodDescriptorProto{{
+				Name:            protoV1.String("Call"),
+				InputType:       protoV1.String(".testdeps.DepMessage"),
+				OutputType:      protoV1.String(".testdeps.DepMessage"),
+				ServerStreaming: protoV1.Bool(true),
+			}},
+		}},
+	}
+
+	// List the dependent file first to exercise the dependency ordering.
+	fs := &descriptorV1.FileDescriptorSet{
+		File: []*descriptorV1.FileDescriptorProto{user, dep},
+	}
+	files, err := NewFilesFromDescriptorSet(fs)
+	if err != nil {
+		t.Fatalf("NewFilesFromDescriptorSet() error: %v", err)
+	}
+
+	// Cross-file references must resolve to the real descriptors.
+	d, err := files.FindDescriptorByName("testusers.UserMessage")
+	if err != nil {
+		t.Fatalf("FindDescriptorByName(testusers.UserMessage) error: %v", err)
+	}
+	md := d.(pref.MessageDescriptor)
+	if mt := md.Fields().ByName("dep").MessageType(); mt.IsPlaceholder() {
+		t.Errorf("field testusers.UserMessage.dep resolved to a placeholder, want testdeps.DepMessage")
+	}
+	if v := md.Fields().ByName("enum").Default(); v.Enum() != 0 {
+		t.Errorf("field testusers.UserMessage.enum has default %v, want 0", v)
+	}
+	if v := md.Fields().ByName("bytes").Default(); string(v.Bytes()) != "ab\x00\"\\" {
+		t.Errorf("field testusers.UserMessage.bytes has default %q, want %q", v.Bytes(), "ab\x00\"\\")
+	}
+
+	// Converting to a descriptor set and reloading it must reach a
+	// fixed point: the re-exported set matches the exported one.
+	fs2 := ToFileDescriptorSet(files)
+	if n := len(fs2.GetFile()); n != 2 {
+		t.Fatalf("ToFileDescriptorSet() has %d files, want 2", n)
+	}
+	if got := fs2.GetFile()[0].GetName(); got != "test/dep.proto" {
+		t.Errorf("ToFileDescriptorSet() first file is %q, want %q (sorted by path)", got, "test/dep.proto")
+	}
+	files2, err := NewFilesFromDescriptorSet(fs2)
+	if err != nil {
+		t.Fatalf("NewFilesFromDescriptorSet(reloaded) error: %v", err)
+	}
+	fs3 := ToFileDescriptorSet(files2)
+	if !protoV1.Equal(fs2, fs3) {
+		t.Errorf("descriptor set round trip mismatch:\nfirst:  %v\nsecond: %v", fs2, fs3)
+	}
+}
+
+func TestNewFilesFromDescriptorSetCycle(t *testing.T) {
+	fs := &descriptorV1.FileDescriptorSet{
+		File: []*descriptorV1.FileDescriptorProto{{
+			Name:       protoV1.String("a.proto"),
+			Dependency: []string{"b.proto"},
+		}, {
+			Name:       protoV1.String("b.proto"),
+			Dependency: []string{"a.proto"},
+		}},
+	}
+	if _, err := NewFilesFromDescriptorSet(fs); err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("NewFilesFromDescriptorSet() = %v, want import cycle error", err)
+	}
+}
+
+func TestToFileDescriptorProtoSourceInfo(t *testing.T) {
+	fd := &descriptorV1.FileDescriptorProto{
+		Name:        protoV1.String("test/info.proto"),
+		Package:     protoV1.String("testinfo"),
+		MessageType: []*descriptorV1.DescriptorProto{{Name: protoV1.String("M")}},
+		SourceCodeInfo: &descriptorV1.SourceCodeInfo{
+			Location: []*descriptorV1.SourceCodeInfo_Location{{
+				Span: []int32{0, 0, 10, 1},
+			}, {
+				Path:            []int32{4, 0},
+				Span:            []int32{4, 0, 6, 1},
+				LeadingComments: protoV1.String(" A message.\n"),
+			}, {
+				Path: []int32{2},
+				Span: []int32{2, 8, 16},
+			}},
+		},
+	}
+	f, err := NewFileFromDescriptorProto(fd, nil)
+	if err != nil {
+		t.Fatalf("NewFileFromDescriptorProto() error: %v", err)
+	}
+	got := ToFileDescriptorProto(f)
+	if !protoV1.Equal(got, fd) {
+		t.Errorf("ToFileDescriptorProto() mismatch:\ngot  %v\nwant %v", got, fd)
+	}
+}
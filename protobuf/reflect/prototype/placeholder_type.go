@@ -22,6 +22,7 @@ var (
 	emptyEnumValues enumValues
 	emptyExtensions extensions
 	emptyServices   services
+	emptyLocations  sourceLocations
 )
 
 type placeholderName pref.FullName
@@ -49,6 +50,7 @@ func (t placeholderFile) Enums() pref.EnumDescriptors                    { retur
 func (t placeholderFile) Extensions() pref.ExtensionDescriptors          { return &emptyExtensions }
 func (t placeholderFile) Services() pref.ServiceDescriptors              { return &emptyServices }
 func (t placeholderFile) DescriptorByName(pref.FullName) pref.Descriptor { return nil }
+func (t placeholderFile) SourceLocations() pref.SourceLocations          { return &emptyLocations }
 func (t placeholderFile) Format(s fmt.State, r rune)                     { formatDesc(s, r, t) }
 func (t placeholderFile) ProtoType(pref.FileDescriptor)                  {}
 
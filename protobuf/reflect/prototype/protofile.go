@@ -45,6 +45,8 @@ type File struct {
 	Extensions []Extension
 	Services   []Service
 
+	SourceLocations []protoreflect.SourceLocation
+
 	*fileMeta
 }
 
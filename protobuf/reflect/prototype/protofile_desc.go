@@ -41,18 +41,27 @@ import (
 // However, this will complicate future work for validation since File may now
 // diverge from the stored descriptor proto (see above TODO).
 
+// Resolver resolves references to descriptors by full name and to files
+// by path. It is implemented by *protoregistry.Files.
+type Resolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+	RangeFilesByPath(path string, f func(protoreflect.FileDescriptor) bool)
+}
+
 // NewFileFromDescriptorProto creates a new protoreflect.FileDescriptor from
 // the provided descriptor message. The file must represent a valid proto file
 // according to protobuf semantics.
 //
 // Any import files, enum types, or message types referenced in the file are
-// resolved using the provided registry. When looking up an import file path,
-// the path must be unique. The newly created file descriptor is not registered
-// back into the provided file registry.
+// resolved using the provided resolver, which is usually a
+// *protoregistry.Files. When looking up an import file path, the path must be
+// unique. If the resolver is nil, all remote references are resolved as
+// placeholders. The newly created file descriptor is not registered back
+// into the provided file registry.
 //
 // The caller must relinquish full ownership of the input fd and must not
 // access or mutate any fields.
-func NewFileFromDescriptorProto(fd *descriptorV1.FileDescriptorProto, r *protoregistry.Files) (protoreflect.FileDescriptor, error) {
+func NewFileFromDescriptorProto(fd *descriptorV1.FileDescriptorProto, r Resolver) (protoreflect.FileDescriptor, error) {
 	var f File
 	switch fd.GetSyntax() {
 	case "", "proto2":
@@ -81,11 +90,13 @@ func NewFileFromDescriptorProto(fd *descriptorV1.FileDescriptorProto, r *protore
 	for i, path := range fd.GetDependency() {
 		var n int
 		imp := &f.Imports[i]
-		r.RangeFilesByPath(path, func(fd protoreflect.FileDescriptor) bool {
-			imp.FileDescriptor = fd
-			n++
-			return true
-		})
+		if r != nil {
+			r.RangeFilesByPath(path, func(fd protoreflect.FileDescriptor) bool {
+				imp.FileDescriptor = fd
+				n++
+				return true
+			})
+		}
 		if n > 1 {
 			return nil, errors.New("duplicate files for import %q", path)
 		}
@@ -111,11 +122,33 @@ func NewFileFromDescriptorProto(fd *descriptorV1.FileDescriptorProto, r *protore
 	if err != nil {
 		return nil, err
 	}
+	f.SourceLocations = sourceLocationsFromDescriptorProto(fd.GetSourceCodeInfo())
 
 	return NewFile(&f)
 }
 
-func messagesFromDescriptorProto(mds []*descriptorV1.DescriptorProto, syntax protoreflect.Syntax, r *protoregistry.Files) (ms []Message, err error) {
+func sourceLocationsFromDescriptorProto(info *descriptorV1.SourceCodeInfo) (ls []protoreflect.SourceLocation) {
+	for _, ld := range info.GetLocation() {
+		var l protoreflect.SourceLocation
+		l.Path = protoreflect.SourcePath(ld.GetPath())
+		switch span := ld.GetSpan(); len(span) {
+		case 3:
+			// The end line is implicitly the same as the start line.
+			l.StartLine, l.StartColumn = int(span[0]), int(span[1])
+			l.EndLine, l.EndColumn = int(span[0]), int(span[2])
+		case 4:
+			l.StartLine, l.StartColumn = int(span[0]), int(span[1])
+			l.EndLine, l.EndColumn = int(span[2]), int(span[3])
+		}
+		l.LeadingDetachedComments = ld.GetLeadingDetachedComments()
+		l.LeadingComments = ld.GetLeadingComments()
+		l.TrailingComments = ld.GetTrailingComments()
+		ls = append(ls, l)
+	}
+	return ls
+}
+
+func messagesFromDescriptorProto(mds []*descriptorV1.DescriptorProto, syntax protoreflect.Syntax, r Resolver) (ms []Message, err error) {
 	for _, md := range mds {
 		var m Message
 		m.Name = protoreflect.Name(md.GetName())
@@ -216,7 +249,7 @@ var isScalarNumeric = map[protoreflect.Kind]bool{
 	protoreflect.DoubleKind:   true,
 }
 
-func enumsFromDescriptorProto(eds []*descriptorV1.EnumDescriptorProto, r *protoregistry.Files) (es []Enum, err error) {
+func enumsFromDescriptorProto(eds []*descriptorV1.EnumDescriptorProto, r Resolver) (es []Enum, err error) {
 	for _, ed := range eds {
 		var e Enum
 		e.Name = protoreflect.Name(ed.GetName())
@@ -231,7 +264,7 @@ func enumsFromDescriptorProto(eds []*descriptorV1.EnumDescriptorProto, r *protor
 	return es, nil
 }
 
-func extensionsFromDescriptorProto(xds []*descriptorV1.FieldDescriptorProto, r *protoregistry.Files) (xs []Extension, err error) {
+func extensionsFromDescriptorProto(xds []*descriptorV1.FieldDescriptorProto, r Resolver) (xs []Extension, err error) {
 	for _, xd := range xds {
 		var x Extension
 		x.Name = protoreflect.Name(xd.GetName())
@@ -271,7 +304,7 @@ func extensionsFromDescriptorProto(xds []*descriptorV1.FieldDescriptorProto, r *
 	return xs, nil
 }
 
-func servicesFromDescriptorProto(sds []*descriptorV1.ServiceDescriptorProto, r *protoregistry.Files) (ss []Service, err error) {
+func servicesFromDescriptorProto(sds []*descriptorV1.ServiceDescriptorProto, r Resolver) (ss []Service, err error) {
 	for _, sd := range sds {
 		var s Service
 		s.Name = protoreflect.Name(sd.GetName())
@@ -300,11 +333,14 @@ func servicesFromDescriptorProto(sds []*descriptorV1.ServiceDescriptorProto, r *
 // simplifies our implementation as we won't need to implement C++'s namespace
 // scoping rules.
 
-func findMessageDescriptor(s string, r *protoregistry.Files) (protoreflect.MessageDescriptor, error) {
+func findMessageDescriptor(s string, r Resolver) (protoreflect.MessageDescriptor, error) {
 	if !strings.HasPrefix(s, ".") {
 		return nil, errors.New("identifier name must be fully qualified with a leading dot: %v", s)
 	}
 	name := protoreflect.FullName(strings.TrimPrefix(s, "."))
+	if r == nil {
+		return PlaceholderMessage(name), nil
+	}
 	switch m, err := r.FindDescriptorByName(name); {
 	case err == nil:
 		m, ok := m.(protoreflect.MessageDescriptor)
@@ -319,11 +355,14 @@ func findMessageDescriptor(s string, r *protoregistry.Files) (protoreflect.Messa
 	}
 }
 
-func findEnumDescriptor(s string, r *protoregistry.Files) (protoreflect.EnumDescriptor, error) {
+func findEnumDescriptor(s string, r Resolver) (protoreflect.EnumDescriptor, error) {
 	if !strings.HasPrefix(s, ".") {
 		return nil, errors.New("identifier name must be fully qualified with a leading dot: %v", s)
 	}
 	name := protoreflect.FullName(strings.TrimPrefix(s, "."))
+	if r == nil {
+		return PlaceholderEnum(name), nil
+	}
 	switch e, err := r.FindDescriptorByName(name); {
 	case err == nil:
 		e, ok := e.(protoreflect.EnumDescriptor)
@@ -59,6 +59,20 @@ func (p *fileImports) Get(i int) pref.FileImport           { return (*p)[i] }
 func (p *fileImports) Format(s fmt.State, r rune)          { formatList(s, r, p) }
 func (p *fileImports) ProtoInternal(pragma.DoNotImplement) {}
 
+type sourceLocations []pref.SourceLocation
+
+func (p *sourceLocations) Len() int                      { return len(*p) }
+func (p *sourceLocations) Get(i int) pref.SourceLocation { return (*p)[i] }
+func (p *sourceLocations) ByPath(path pref.SourcePath) pref.SourceLocation {
+	for _, l := range *p {
+		if l.Path.Equal(path) {
+			return l
+		}
+	}
+	return pref.SourceLocation{}
+}
+func (p *sourceLocations) ProtoInternal(pragma.DoNotImplement) {}
+
 type oneofFieldsMeta struct {
 	once   sync.Once
 	typs   []pref.FieldDescriptor
@@ -75,6 +75,7 @@ func (t fileDesc) Enums() pref.EnumDescriptors                       { return t.
 func (t fileDesc) Extensions() pref.ExtensionDescriptors             { return t.f.xs.lazyInit(t, t.f.Extensions) }
 func (t fileDesc) Services() pref.ServiceDescriptors                 { return t.f.ss.lazyInit(t, t.f.Services) }
 func (t fileDesc) DescriptorByName(s pref.FullName) pref.Descriptor  { return t.f.ds.lookup(t, s) }
+func (t fileDesc) SourceLocations() pref.SourceLocations             { return (*sourceLocations)(&t.f.SourceLocations) }
 func (t fileDesc) Format(s fmt.State, r rune)                        { formatDesc(s, r, t) }
 func (t fileDesc) ProtoType(pref.FileDescriptor)                     {}
 func (t fileDesc) ProtoInternal(pragma.DoNotImplement)               {}
@@ -67,7 +67,9 @@ func TestDescriptors(t *testing.T) {
 
 // TestDescriptorAccessors tests that descriptorAccessors is up-to-date.
 func TestDescriptorAccessors(t *testing.T) {
-	ignore := map[string]bool{"ProtoType": true, "DescriptorByName": true}
+	// SourceLocations is ignored since printing source information in the
+	// debug output would drown out the descriptor structure itself.
+	ignore := map[string]bool{"ProtoType": true, "DescriptorByName": true, "SourceLocations": true}
 	rt := reflect.TypeOf((*pref.Descriptor)(nil)).Elem()
 	for i := 0; i < rt.NumMethod(); i++ {
 		ignore[rt.Method(i).Name] = true
@@ -971,3 +973,52 @@ func TestResolve(t *testing.T) {
 		}
 	}
 }
+
+func TestSourceLocations(t *testing.T) {
+	fd, err := NewFileFromDescriptorProto(&descriptorV1.FileDescriptorProto{
+		Name:    protoV1.String("comments.proto"),
+		Package: protoV1.String("comments"),
+		MessageType: []*descriptorV1.DescriptorProto{{
+			Name: protoV1.String("Commented"),
+		}},
+		SourceCodeInfo: &descriptorV1.SourceCodeInfo{Location: []*descriptorV1.SourceCodeInfo_Location{{
+			Path: []int32{},
+			Span: []int32{0, 0, 20, 1},
+		}, {
+			Path:            []int32{4, 0},
+			Span:            []int32{5, 0, 10, 1},
+			LeadingComments: protoV1.String(" A message.\n"),
+		}, {
+			Path: []int32{2},
+			Span: []int32{2, 8, 16}, // three-element span: the end line is the start line
+		}}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewFileFromDescriptorProto() error: %v", err)
+	}
+
+	locs := fd.SourceLocations()
+	if locs.Len() != 3 {
+		t.Fatalf("SourceLocations().Len() = %d, want 3", locs.Len())
+	}
+	loc := locs.ByPath(pref.SourcePath{4, 0})
+	if loc.LeadingComments != " A message.\n" {
+		t.Errorf("ByPath(4, 0).LeadingComments = %q, want %q", loc.LeadingComments, " A message.\n")
+	}
+	if loc.StartLine != 5 || loc.StartColumn != 0 || loc.EndLine != 10 || loc.EndColumn != 1 {
+		t.Errorf("ByPath(4, 0) span = (%d, %d, %d, %d), want (5, 0, 10, 1)",
+			loc.StartLine, loc.StartColumn, loc.EndLine, loc.EndColumn)
+	}
+	loc = locs.ByPath(pref.SourcePath{2})
+	if loc.StartLine != 2 || loc.StartColumn != 8 || loc.EndLine != 2 || loc.EndColumn != 16 {
+		t.Errorf("ByPath(2) span = (%d, %d, %d, %d), want (2, 8, 2, 16)",
+			loc.StartLine, loc.StartColumn, loc.EndLine, loc.EndColumn)
+	}
+	if loc := locs.ByPath(pref.SourcePath{4, 1}); !loc.Path.Equal(nil) {
+		t.Errorf("ByPath(4, 1) = %v, want zero location", loc)
+	}
+
+	if n := PlaceholderFile("p.proto", "p").SourceLocations().Len(); n != 0 {
+		t.Errorf("placeholder SourceLocations().Len() = %d, want 0", n)
+	}
+}
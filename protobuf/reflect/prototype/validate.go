@@ -5,6 +5,7 @@
 package prototype
 
 import (
+	"github.com/golang/protobuf/v2/internal/errors"
 	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
 )
 
@@ -25,20 +26,88 @@ import (
 //	* Remote enum and message types are actually found in imported files.
 //	* Placeholder messages and types may only be for weak fields.
 //	* Placeholder full names must be valid.
-//	* The name of each descriptor must be valid.
 
 func validateFile(t pref.FileDescriptor) error {
+	for i := 0; i < t.Messages().Len(); i++ {
+		if err := validateMessage(t.Messages().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Enums().Len(); i++ {
+		if err := validateEnum(t.Enums().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Extensions().Len(); i++ {
+		if err := validateExtension(t.Extensions().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Services().Len(); i++ {
+		s := t.Services().Get(i)
+		if err := validateName(s); err != nil {
+			return err
+		}
+		for j := 0; j < s.Methods().Len(); j++ {
+			if err := validateName(s.Methods().Get(j)); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
 func validateMessage(t pref.MessageDescriptor) error {
+	if err := validateName(t); err != nil {
+		return err
+	}
+	for i := 0; i < t.Fields().Len(); i++ {
+		if err := validateName(t.Fields().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Oneofs().Len(); i++ {
+		if err := validateName(t.Oneofs().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Messages().Len(); i++ {
+		if err := validateMessage(t.Messages().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Enums().Len(); i++ {
+		if err := validateEnum(t.Enums().Get(i)); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < t.Extensions().Len(); i++ {
+		if err := validateExtension(t.Extensions().Get(i)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func validateExtension(t pref.ExtensionDescriptor) error {
-	return nil
+	return validateName(t)
 }
 
 func validateEnum(t pref.EnumDescriptor) error {
+	if err := validateName(t); err != nil {
+		return err
+	}
+	for i := 0; i < t.Values().Len(); i++ {
+		if err := validateName(t.Values().Get(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateName(t pref.Descriptor) error {
+	if !t.Name().IsValid() {
+		return errors.New("descriptor name %q is invalid", t.Name())
+	}
 	return nil
 }
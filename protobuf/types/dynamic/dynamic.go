@@ -0,0 +1,361 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dynamic provides a protoreflect.Message implementation whose
+// storage is driven entirely by a message descriptor, without a generated
+// Go struct type. It enables programs such as proxies and debuggers to
+// operate on arbitrary message types known only at runtime.
+package dynamic
+
+import (
+	"fmt"
+	"reflect"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+)
+
+// Message is a dynamically constructed message value keyed by field number.
+// It implements both protoreflect.Message and protoreflect.ProtoMessage.
+//
+// The accessor methods of a Message are not safe for concurrent mutation.
+type Message struct {
+	typ messageType
+
+	known     map[pref.FieldNumber]pref.Value
+	unknown   map[pref.FieldNumber]pref.RawFields
+	extByNum  map[pref.FieldNumber]pref.ExtensionType
+	extByName map[pref.FullName]pref.ExtensionType
+}
+
+// NewMessage returns a new empty message with the given descriptor.
+// It panics if the descriptor is a placeholder.
+func NewMessage(md pref.MessageDescriptor) *Message {
+	if md == nil || md.IsPlaceholder() {
+		panic("message descriptor must not be nil or a placeholder")
+	}
+	return &Message{typ: messageType{md}}
+}
+
+func (m *Message) Type() pref.MessageType            { return m.typ }
+func (m *Message) KnownFields() pref.KnownFields     { return (*knownFields)(m) }
+func (m *Message) UnknownFields() pref.UnknownFields { return (*unknownFields)(m) }
+func (m *Message) Interface() pref.ProtoMessage      { return m }
+func (m *Message) ProtoReflect() pref.Message        { return m }
+func (m *Message) ProtoMutable()                     {}
+
+// messageType extends the message descriptor with Go type information.
+type messageType struct{ pref.MessageDescriptor }
+
+func (t messageType) GoNew() pref.ProtoMessage { return NewMessage(t.MessageDescriptor) }
+func (t messageType) GoType() reflect.Type     { return reflect.TypeOf((*Message)(nil)) }
+
+type knownFields Message
+
+// fieldDesc returns the descriptor for a declared field or registered
+// extension field with the given number, or nil if the number is unknown.
+func (fs *knownFields) fieldDesc(n pref.FieldNumber) pref.FieldDescriptor {
+	if fd := fs.typ.Fields().ByNumber(n); fd != nil {
+		return fd
+	}
+	if xt := fs.extByNum[n]; xt != nil {
+		return xt
+	}
+	return nil
+}
+
+func (fs *knownFields) Len() (cnt int) {
+	for n := range fs.known {
+		if fs.Has(n) {
+			cnt++
+		}
+	}
+	return cnt
+}
+func (fs *knownFields) Has(n pref.FieldNumber) bool {
+	fd := fs.fieldDesc(n)
+	if fd == nil {
+		return false
+	}
+	v, ok := fs.known[n]
+	if !ok {
+		return false
+	}
+	switch {
+	case fd.IsMap():
+		return v.Map().Len() > 0
+	case fd.Cardinality() == pref.Repeated:
+		return v.Vector().Len() > 0
+	case isNullable(fd):
+		return true
+	}
+	return !isZero(fd, v)
+}
+func (fs *knownFields) Get(n pref.FieldNumber) pref.Value {
+	fd := fs.fieldDesc(n)
+	if fd == nil {
+		return pref.Value{}
+	}
+	if v, ok := fs.known[n]; ok {
+		return v
+	}
+	// Unpopulated repeated fields report an empty, detached container;
+	// use Mutable to initialize the field before mutating the container.
+	switch {
+	case fd.IsMap():
+		return pref.ValueOf(&dynamicMap{fd: fd})
+	case fd.Cardinality() == pref.Repeated:
+		return pref.ValueOf(&vector{fd: fd})
+	}
+	return fd.Default()
+}
+func (fs *knownFields) Set(n pref.FieldNumber, v pref.Value) {
+	fd := fs.fieldDesc(n)
+	if fd == nil {
+		panic(fmt.Sprintf("invalid field: %d", n))
+	}
+	fs.claimOneof(fd)
+	if fs.known == nil {
+		fs.known = make(map[pref.FieldNumber]pref.Value)
+	}
+	fs.known[n] = v
+}
+func (fs *knownFields) Clear(n pref.FieldNumber) {
+	if fs.fieldDesc(n) == nil {
+		panic(fmt.Sprintf("invalid field: %d", n))
+	}
+	delete(fs.known, n)
+}
+func (fs *knownFields) Mutable(n pref.FieldNumber) pref.Mutable {
+	fd := fs.fieldDesc(n)
+	if fd == nil {
+		panic(fmt.Sprintf("invalid field: %d", n))
+	}
+	v, ok := fs.known[n]
+	if !ok {
+		switch {
+		case fd.IsMap():
+			v = pref.ValueOf(&dynamicMap{fd: fd})
+		case fd.Cardinality() == pref.Repeated:
+			v = pref.ValueOf(&vector{fd: fd})
+		case fd.Kind() == pref.MessageKind || fd.Kind() == pref.GroupKind:
+			v = pref.ValueOf(NewMessage(fd.MessageType()))
+		default:
+			// Mutable is only valid for composite types.
+			panic(fmt.Sprintf("invalid mutable call: %d", n))
+		}
+		fs.claimOneof(fd)
+		if fs.known == nil {
+			fs.known = make(map[pref.FieldNumber]pref.Value)
+		}
+		fs.known[n] = v
+	}
+	switch {
+	case fd.IsMap():
+		return v.Map()
+	case fd.Cardinality() == pref.Repeated:
+		return v.Vector()
+	case fd.Kind() == pref.MessageKind || fd.Kind() == pref.GroupKind:
+		return v.Message()
+	default:
+		panic(fmt.Sprintf("invalid mutable call: %d", n))
+	}
+}
+func (fs *knownFields) Range(f func(pref.FieldNumber, pref.Value) bool) {
+	for n, v := range fs.known {
+		if fs.Has(n) {
+			if !f(n, v) {
+				return
+			}
+		}
+	}
+}
+func (fs *knownFields) ExtensionTypes() pref.ExtensionFieldTypes {
+	return (*extensionFieldTypes)(fs)
+}
+
+// claimOneof clears any other member of the oneof that fd belongs to.
+func (fs *knownFields) claimOneof(fd pref.FieldDescriptor) {
+	if od := fd.OneofType(); od != nil {
+		for i := 0; i < od.Fields().Len(); i++ {
+			delete(fs.known, od.Fields().Get(i).Number())
+		}
+	}
+}
+
+// isNullable reports whether the presence of fd can be tracked independently
+// of its value. Only scalars in proto2, member fields of a oneof, and
+// singular messages are nullable.
+func isNullable(fd pref.FieldDescriptor) bool {
+	if fd.Syntax() == pref.Proto2 || fd.OneofType() != nil {
+		return true
+	}
+	return fd.Kind() == pref.MessageKind || fd.Kind() == pref.GroupKind
+}
+
+// isZero reports whether v is the zero value for the kind of fd.
+func isZero(fd pref.FieldDescriptor, v pref.Value) bool {
+	switch fd.Kind() {
+	case pref.BoolKind:
+		return !v.Bool()
+	case pref.Int32Kind, pref.Sint32Kind, pref.Sfixed32Kind,
+		pref.Int64Kind, pref.Sint64Kind, pref.Sfixed64Kind:
+		return v.Int() == 0
+	case pref.Uint32Kind, pref.Fixed32Kind, pref.Uint64Kind, pref.Fixed64Kind:
+		return v.Uint() == 0
+	case pref.FloatKind, pref.DoubleKind:
+		return v.Float() == 0
+	case pref.StringKind:
+		return v.String() == ""
+	case pref.BytesKind:
+		return len(v.Bytes()) == 0
+	case pref.EnumKind:
+		return v.Enum() == 0
+	}
+	return false
+}
+
+type extensionFieldTypes Message
+
+func (fs *extensionFieldTypes) Len() int { return len(fs.extByNum) }
+func (fs *extensionFieldTypes) Register(xt pref.ExtensionType) {
+	if got, want := xt.ExtendedType().FullName(), fs.typ.FullName(); got != want {
+		panic(fmt.Sprintf("extended type mismatch: got %v, want %v", got, want))
+	}
+	if !fs.typ.ExtensionRanges().Has(xt.Number()) {
+		panic(fmt.Sprintf("invalid extension field number: %d", xt.Number()))
+	}
+	if fs.extByNum[xt.Number()] != nil || fs.extByName[xt.FullName()] != nil {
+		panic(fmt.Sprintf("extension already registered: %v", xt.FullName()))
+	}
+	if fs.extByNum == nil {
+		fs.extByNum = make(map[pref.FieldNumber]pref.ExtensionType)
+		fs.extByName = make(map[pref.FullName]pref.ExtensionType)
+	}
+	fs.extByNum[xt.Number()] = xt
+	fs.extByName[xt.FullName()] = xt
+}
+func (fs *extensionFieldTypes) Remove(xt pref.ExtensionType) {
+	if fs.extByNum[xt.Number()] != xt {
+		return
+	}
+	if _, ok := fs.known[xt.Number()]; ok {
+		panic(fmt.Sprintf("value for extension still populated: %v", xt.FullName()))
+	}
+	delete(fs.extByNum, xt.Number())
+	delete(fs.extByName, xt.FullName())
+}
+func (fs *extensionFieldTypes) ByNumber(n pref.FieldNumber) pref.ExtensionType {
+	return fs.extByNum[n]
+}
+func (fs *extensionFieldTypes) ByName(s pref.FullName) pref.ExtensionType {
+	return fs.extByName[s]
+}
+func (fs *extensionFieldTypes) Range(f func(pref.ExtensionType) bool) {
+	for _, xt := range fs.extByNum {
+		if !f(xt) {
+			return
+		}
+	}
+}
+
+type unknownFields Message
+
+func (fs *unknownFields) Len() int { return len(fs.unknown) }
+func (fs *unknownFields) Get(n pref.FieldNumber) pref.RawFields {
+	return fs.unknown[n]
+}
+func (fs *unknownFields) Set(n pref.FieldNumber, b pref.RawFields) {
+	if len(b) == 0 {
+		delete(fs.unknown, n)
+		return
+	}
+	if fs.unknown == nil {
+		fs.unknown = make(map[pref.FieldNumber]pref.RawFields)
+	}
+	fs.unknown[n] = b
+}
+func (fs *unknownFields) Range(f func(pref.FieldNumber, pref.RawFields) bool) {
+	for n, b := range fs.unknown {
+		if !f(n, b) {
+			return
+		}
+	}
+}
+func (fs *unknownFields) IsSupported() bool { return true }
+
+// vector is a dynamically allocated list of element values.
+type vector struct {
+	fd pref.FieldDescriptor
+	vs []pref.Value
+}
+
+func (vs *vector) Len() int                { return len(vs.vs) }
+func (vs *vector) Get(i int) pref.Value    { return vs.vs[i] }
+func (vs *vector) Set(i int, v pref.Value) { vs.vs[i] = v }
+func (vs *vector) Append(v pref.Value)     { vs.vs = append(vs.vs, v) }
+func (vs *vector) Mutable(i int) pref.Mutable {
+	// Mutable is only valid for messages and panics for other kinds.
+	return vs.vs[i].Message()
+}
+func (vs *vector) MutableAppend() pref.Mutable {
+	// MutableAppend is only valid for messages and panics for other kinds.
+	m := NewMessage(vs.fd.MessageType())
+	vs.vs = append(vs.vs, pref.ValueOf(m))
+	return m
+}
+func (vs *vector) Truncate(i int) { vs.vs = vs.vs[:i] }
+func (vs *vector) ProtoMutable()  {}
+
+// dynamicMap is a dynamically allocated map of entry values, keyed by the
+// Go representation of the map key so that equal keys compare equal.
+type dynamicMap struct {
+	fd pref.FieldDescriptor
+	ms map[interface{}]pref.Value
+}
+
+func (ms *dynamicMap) Len() int { return len(ms.ms) }
+func (ms *dynamicMap) Has(k pref.MapKey) bool {
+	_, ok := ms.ms[k.Interface()]
+	return ok
+}
+func (ms *dynamicMap) Get(k pref.MapKey) pref.Value {
+	return ms.ms[k.Interface()]
+}
+func (ms *dynamicMap) Set(k pref.MapKey, v pref.Value) {
+	if !k.IsValid() || !v.IsValid() {
+		panic("invalid key or value")
+	}
+	if ms.ms == nil {
+		ms.ms = make(map[interface{}]pref.Value)
+	}
+	ms.ms[k.Interface()] = v
+}
+func (ms *dynamicMap) Clear(k pref.MapKey) {
+	delete(ms.ms, k.Interface())
+}
+func (ms *dynamicMap) Mutable(k pref.MapKey) pref.Mutable {
+	// Mutable is only valid for messages and panics for other kinds.
+	v, ok := ms.ms[k.Interface()]
+	if !ok {
+		fd := ms.fd.MessageType().Fields().ByNumber(2) // map entry value field
+		v = pref.ValueOf(NewMessage(fd.MessageType()))
+		ms.Set(k, v)
+	}
+	return v.Message()
+}
+func (ms *dynamicMap) Range(f func(pref.MapKey, pref.Value) bool) {
+	for k, v := range ms.ms {
+		if !f(pref.ValueOf(k).MapKey(), v) {
+			return
+		}
+	}
+}
+func (ms *dynamicMap) ProtoMutable() {}
+
+var (
+	_ pref.Message      = (*Message)(nil)
+	_ pref.ProtoMessage = (*Message)(nil)
+	_ pref.Vector       = (*vector)(nil)
+	_ pref.Map          = (*dynamicMap)(nil)
+)
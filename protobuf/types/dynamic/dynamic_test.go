@@ -0,0 +1,290 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dynamic
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	pref "github.com/golang/protobuf/v2/reflect/protoreflect"
+	ptype "github.com/golang/protobuf/v2/reflect/prototype"
+)
+
+func mustMakeFile(t *testing.T) pref.FileDescriptor {
+	t.Helper()
+	fd, err := ptype.NewFile(&ptype.File{
+		Syntax:  pref.Proto2,
+		Path:    "test/dynamic.proto",
+		Package: "dynamic.test",
+		Messages: []ptype.Message{{
+			Name: "M",
+			Fields: []ptype.Field{{
+				Name:        "f_int",
+				Number:      1,
+				Cardinality: pref.Optional,
+				Kind:        pref.Int32Kind,
+				Default:     pref.ValueOf(int32(42)),
+			}, {
+				Name:        "f_str",
+				Number:      2,
+				Cardinality: pref.Repeated,
+				Kind:        pref.StringKind,
+			}, {
+				Name:        "f_msg",
+				Number:      3,
+				Cardinality: pref.Optional,
+				Kind:        pref.MessageKind,
+				MessageType: ptype.PlaceholderMessage("dynamic.test.M"),
+			}, {
+				Name:        "f_map",
+				Number:      4,
+				Cardinality: pref.Repeated,
+				Kind:        pref.MessageKind,
+				MessageType: ptype.PlaceholderMessage("dynamic.test.M.FMapEntry"),
+			}, {
+				Name:        "f_one",
+				Number:      5,
+				Cardinality: pref.Optional,
+				Kind:        pref.StringKind,
+				OneofName:   "choice",
+			}, {
+				Name:        "f_two",
+				Number:      6,
+				Cardinality: pref.Optional,
+				Kind:        pref.Int32Kind,
+				OneofName:   "choice",
+			}},
+			Oneofs: []ptype.Oneof{{Name: "choice"}},
+			Messages: []ptype.Message{{
+				Name:       "FMapEntry",
+				IsMapEntry: true,
+				Fields: []ptype.Field{{
+					Name:        "key",
+					Number:      1,
+					Cardinality: pref.Optional,
+					Kind:        pref.StringKind,
+				}, {
+					Name:        "value",
+					Number:      2,
+					Cardinality: pref.Optional,
+					Kind:        pref.Int32Kind,
+				}},
+			}},
+			ExtensionRanges: [][2]pref.FieldNumber{{100, 200}},
+		}},
+		Extensions: []ptype.Extension{{
+			Name:         "ext",
+			Number:       100,
+			Cardinality:  pref.Optional,
+			Kind:         pref.Int32Kind,
+			ExtendedType: ptype.PlaceholderMessage("dynamic.test.M"),
+		}, {
+			Name:         "ext_out_of_range",
+			Number:       500,
+			Cardinality:  pref.Optional,
+			Kind:         pref.Int32Kind,
+			ExtendedType: ptype.PlaceholderMessage("dynamic.test.M"),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("NewFile() error: %v", err)
+	}
+	return fd
+}
+
+func TestMessageScalars(t *testing.T) {
+	fd := mustMakeFile(t)
+	m := NewMessage(fd.Messages().Get(0))
+	ks := m.KnownFields()
+
+	if n := ks.Len(); n != 0 {
+		t.Errorf("Len() = %d, want 0", n)
+	}
+	if v := ks.Get(1); v.Int() != 42 {
+		t.Errorf("Get(f_int) = %v, want default 42", v)
+	}
+	if ks.Has(1) {
+		t.Errorf("Has(f_int) = true, want false")
+	}
+
+	// Proto2 scalars are nullable; an explicit zero is still populated.
+	ks.Set(1, pref.ValueOf(int32(0)))
+	if !ks.Has(1) {
+		t.Errorf("Has(f_int) = false after Set, want true")
+	}
+	if n := ks.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+	ks.Clear(1)
+	if ks.Has(1) {
+		t.Errorf("Has(f_int) = true after Clear, want false")
+	}
+
+	// Get on an unknown field number reports an invalid value.
+	if v := ks.Get(1000); v.IsValid() {
+		t.Errorf("Get(1000) = %v, want invalid value", v)
+	}
+}
+
+func TestMessageComposites(t *testing.T) {
+	fd := mustMakeFile(t)
+	m := NewMessage(fd.Messages().Get(0))
+	ks := m.KnownFields()
+
+	// An unpopulated repeated field reports an empty vector.
+	if n := ks.Get(2).Vector().Len(); n != 0 {
+		t.Errorf("Get(f_str).Vector().Len() = %d, want 0", n)
+	}
+	vec := ks.Mutable(2).(pref.Vector)
+	vec.Append(pref.ValueOf("a"))
+	vec.Append(pref.ValueOf("b"))
+	if !ks.Has(2) {
+		t.Errorf("Has(f_str) = false, want true")
+	}
+	if got := ks.Get(2).Vector(); got.Len() != 2 || got.Get(1).String() != "b" {
+		t.Errorf("Get(f_str) did not observe mutations through Mutable")
+	}
+	vec.Truncate(1)
+	if n := ks.Get(2).Vector().Len(); n != 1 {
+		t.Errorf("Vector().Len() = %d after Truncate(1), want 1", n)
+	}
+
+	// Nested message fields are allocated on demand.
+	mm := ks.Mutable(3).(pref.Message)
+	mm.KnownFields().Set(1, pref.ValueOf(int32(7)))
+	if v := ks.Get(3).Message().KnownFields().Get(1); v.Int() != 7 {
+		t.Errorf("nested message field = %v, want 7", v)
+	}
+
+	// Map fields are keyed by the map key value.
+	mp := ks.Mutable(4).(pref.Map)
+	k := pref.ValueOf("k").MapKey()
+	mp.Set(k, pref.ValueOf(int32(1)))
+	if !mp.Has(k) || mp.Len() != 1 {
+		t.Errorf("map does not contain key %v", k)
+	}
+	if v := ks.Get(4).Map().Get(pref.ValueOf("k").MapKey()); v.Int() != 1 {
+		t.Errorf("map value = %v, want 1", v)
+	}
+	mp.Range(func(gotK pref.MapKey, gotV pref.Value) bool {
+		if gotK.String() != "k" || gotV.Int() != 1 {
+			t.Errorf("Range() = (%v, %v), want (k, 1)", gotK, gotV)
+		}
+		return true
+	})
+	mp.Clear(k)
+	if ks.Has(4) {
+		t.Errorf("Has(f_map) = true after clearing the only entry, want false")
+	}
+}
+
+func TestMessageOneofs(t *testing.T) {
+	fd := mustMakeFile(t)
+	ks := NewMessage(fd.Messages().Get(0)).KnownFields()
+
+	ks.Set(5, pref.ValueOf("x"))
+	if !ks.Has(5) {
+		t.Errorf("Has(f_one) = false, want true")
+	}
+	// Setting another member of the oneof clears the previous one.
+	ks.Set(6, pref.ValueOf(int32(9)))
+	if ks.Has(5) {
+		t.Errorf("Has(f_one) = true after setting f_two, want false")
+	}
+	if !ks.Has(6) {
+		t.Errorf("Has(f_two) = false, want true")
+	}
+}
+
+func TestMessageUnknownFields(t *testing.T) {
+	fd := mustMakeFile(t)
+	m := NewMessage(fd.Messages().Get(0))
+	us := m.UnknownFields()
+
+	if !us.IsSupported() {
+		t.Fatalf("IsSupported() = false, want true")
+	}
+	raw := pref.RawFields{0x38, 0x01} // field 7, varint 1
+	us.Set(7, raw)
+	if us.Len() != 1 || !bytes.Equal(us.Get(7), raw) {
+		t.Errorf("Get(7) = %v, want %v", us.Get(7), raw)
+	}
+	us.Set(7, nil)
+	if us.Len() != 0 {
+		t.Errorf("Len() = %d after clearing, want 0", us.Len())
+	}
+}
+
+// testExtension wraps an extension descriptor to implement
+// protoreflect.ExtensionType for an int32 extension field.
+type testExtension struct {
+	pref.ExtensionDescriptor
+}
+
+func (x testExtension) GoNew() interface{}                   { return int32(0) }
+func (x testExtension) GoType() reflect.Type                 { return reflect.TypeOf(int32(0)) }
+func (x testExtension) ValueOf(v interface{}) pref.Value     { return pref.ValueOf(v) }
+func (x testExtension) InterfaceOf(v pref.Value) interface{} { return v.Interface() }
+
+func TestMessageExtensions(t *testing.T) {
+	fd := mustMakeFile(t)
+	ks := NewMessage(fd.Messages().Get(0)).KnownFields()
+	xts := ks.ExtensionTypes()
+
+	xt := testExtension{fd.Extensions().Get(0)}
+	xts.Register(xt)
+	if xts.Len() != 1 || xts.ByNumber(100) == nil || xts.ByName("dynamic.test.ext") == nil {
+		t.Errorf("extension not registered")
+	}
+
+	ks.Set(100, pref.ValueOf(int32(5)))
+	if !ks.Has(100) {
+		t.Errorf("Has(ext) = false, want true")
+	}
+	if v := ks.Get(100); v.Int() != 5 {
+		t.Errorf("Get(ext) = %v, want 5", v)
+	}
+
+	// Removing an extension with a populated value panics.
+	if got := func() (v interface{}) {
+		defer func() { v = recover() }()
+		xts.Remove(xt)
+		return nil
+	}(); got == nil {
+		t.Errorf("Remove() with a populated value succeeded, want panic")
+	}
+	ks.Clear(100)
+	xts.Remove(xt)
+	if xts.Len() != 0 {
+		t.Errorf("Len() = %d after Remove, want 0", xts.Len())
+	}
+
+	// Registering an extension outside the extension ranges panics.
+	if got := func() (v interface{}) {
+		defer func() { v = recover() }()
+		xts.Register(testExtension{fd.Extensions().Get(1)})
+		return nil
+	}(); got == nil {
+		t.Errorf("Register() with an out-of-range field number succeeded, want panic")
+	}
+}
+
+func TestMessageType(t *testing.T) {
+	fd := mustMakeFile(t)
+	m := NewMessage(fd.Messages().Get(0))
+
+	mt := m.Type()
+	if got, want := mt.FullName(), pref.FullName("dynamic.test.M"); got != want {
+		t.Errorf("Type().FullName() = %v, want %v", got, want)
+	}
+	m2, ok := mt.GoNew().(*Message)
+	if !ok || m2.KnownFields().Len() != 0 {
+		t.Errorf("GoNew() = %v, want new empty dynamic message", mt.GoNew())
+	}
+	if got, want := mt.GoType(), reflect.TypeOf(m); got != want {
+		t.Errorf("GoType() = %v, want %v", got, want)
+	}
+}
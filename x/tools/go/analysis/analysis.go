@@ -56,6 +56,12 @@ type Analyzer struct {
 	// ResultType is the type of the optional result of the Run function.
 	ResultType reflect.Type
 
+	// Severity is the default severity of this analyzer's
+	// diagnostics, used for any Diagnostic that does not specify
+	// its own. The zero value, SeverityDefault, is treated as
+	// SeverityError.
+	Severity Severity
+
 	// FactTypes indicates that this analyzer imports and exports
 	// Facts of the specified concrete types.
 	// An analyzer that uses facts may assume that its import
@@ -87,12 +93,54 @@ type Pass struct {
 	OtherFiles []string       // names of non-Go files of this package
 	Pkg        *types.Package // type information about the package
 	TypesInfo  *types.Info    // type information about the syntax trees
+	TypesSizes types.Sizes    // function for computing sizes of types
+
+	// TypeErrors holds the errors produced by the type checker
+	// while checking this package, in the order in which they were
+	// reported. It is empty for a well-typed package; analyzers
+	// that do not set RunDespiteErrors never observe a non-empty
+	// list. Analyzers that do may consult it to avoid reporting in
+	// regions already known to be broken and to degrade gracefully
+	// where type information is missing.
+	TypeErrors []types.Error
+
+	// Module describes the module to which this package belongs,
+	// or is nil if the driver does not know (as in GOPATH mode).
+	Module *Module
+
+	// GOOS, GOARCH, and BuildTags record the build configuration
+	// under which the driver loaded the package, so that analyzers
+	// can vary their behavior by target platform; they are empty if
+	// the driver does not supply them.
+	GOOS, GOARCH string
+	BuildTags    []string
+
+	// ReadFile returns the contents of the named file.
+	//
+	// The only valid file names are the elements of OtherFiles
+	// and the names of the files corresponding to Files.
+	//
+	// Authors of suggested fixes should read the source through
+	// this function rather than from the file system so that they
+	// see the same bytes as the driver, which may use modified or
+	// in-memory versions (overlays) of the files.
+	ReadFile func(filename string) ([]byte, error)
 
 	// Report reports a Diagnostic, a finding about a specific location
 	// in the analyzed source code such as a potential mistake.
 	// It may be called by the Run function.
 	Report func(Diagnostic)
 
+	// ExportArtifact associates a named artifact with this pass:
+	// an aggregate result such as a counter, a summary table, or a
+	// machine-readable report, which the driver may present
+	// alongside the diagnostics. Unlike facts, artifacts are not
+	// visible to other analyses. The value must be serializable by
+	// encoding/json; a later artifact with the same name replaces
+	// an earlier one.
+	// It may be called by the Run function.
+	ExportArtifact func(name string, value interface{})
+
 	// ResultOf provides the inputs to this analysis pass, which are
 	// the corresponding results of its prerequisite analyzers.
 	// The map keys are the elements of Analysis.Required,
@@ -131,6 +179,13 @@ type Pass struct {
 	// For example, suggested or applied refactorings.
 }
 
+// A Module describes the module to which a package belongs.
+type Module struct {
+	Path    string // module path
+	Version string // module version, or "" for the main module
+	Main    bool   // whether the package is in the driver's main module
+}
+
 // Reportf is a helper function that reports a Diagnostic using the
 // specified position and formatted error message.
 func (pass *Pass) Reportf(pos token.Pos, format string, args ...interface{}) {
@@ -138,6 +193,21 @@ func (pass *Pass) Reportf(pos token.Pos, format string, args ...interface{}) {
 	pass.Report(Diagnostic{Pos: pos, Message: msg})
 }
 
+// A Range describes a (possibly empty) extent of source code, such as
+// an ast.Node.
+type Range interface {
+	Pos() token.Pos // start of the extent
+	End() token.Pos // end of the extent
+}
+
+// ReportRangef is a helper function that reports a Diagnostic spanning
+// the given range, such as an ast.Node, with a formatted error
+// message. The full extent lets editors place accurate underlines.
+func (pass *Pass) ReportRangef(rng Range, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	pass.Report(Diagnostic{Pos: rng.Pos(), End: rng.End(), Message: msg})
+}
+
 func (pass *Pass) String() string {
 	return fmt.Sprintf("%s@%s", pass.Analyzer.Name, pass.Pkg.Path())
 }
@@ -187,6 +257,68 @@ type Fact interface {
 // It is primarily intended to make it easy to look up documentation.
 type Diagnostic struct {
 	Pos      token.Pos
-	Category string // optional
+	End      token.Pos // optional: the end of the reported extent; zero if unknown
+	Category string    // optional
+	Severity Severity  // optional; default is the Analyzer's Severity
 	Message  string
+
+	// SuggestedFixes is an optional list of fixes to address the
+	// problem described by the diagnostic. Each one represents
+	// an alternative strategy; at most one may be applied.
+	SuggestedFixes []SuggestedFix
+}
+
+// A SuggestedFix is a code change associated with a Diagnostic that
+// addresses the problem described by it.
+//
+// The TextEdits must not overlap, nor contain edits for other
+// packages. Edits for the current package must satisfy:
+//	- Pos <= End,
+//	- Pos and End are within the analyzed package, and
+//	- the edits, applied in any order, produce the same result.
+type SuggestedFix struct {
+	// A description for this suggested fix to be shown to a user
+	// deciding whether to accept it.
+	Message   string
+	TextEdits []TextEdit
+}
+
+// A TextEdit represents the replacement of the code between Pos and
+// End with the new text. Each TextEdit should apply to a single file.
+// End should not be earlier in the file than Pos.
+type TextEdit struct {
+	// For a pure insertion, End can either be set to Pos or token.NoPos.
+	Pos     token.Pos
+	End     token.Pos
+	NewText []byte
+}
+
+// A Severity describes how serious a diagnostic is.
+//
+// Drivers may use severities to decide how to present diagnostics
+// and whether they should cause a nonzero exit status; see the
+// checker's -severity flag.
+type Severity int8
+
+const (
+	// SeverityDefault defers the choice of severity to the
+	// Analyzer, and ultimately to the driver, whose default is
+	// SeverityError.
+	SeverityDefault Severity = iota
+
+	SeverityInfo    // a report that is not a problem
+	SeverityWarning // a likely problem that need not fail the build
+	SeverityError   // a definite problem; causes a nonzero exit
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "default"
 }
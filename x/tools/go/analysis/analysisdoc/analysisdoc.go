@@ -0,0 +1,144 @@
+// Package analysisdoc generates reference documentation for a suite
+// of analyzers, so that a team shipping a multichecker binary can
+// publish a rules reference that never drifts from the code.
+//
+// The generated page lists each analyzer's name, documentation, and
+// flags; the caller may supply the diagnostic categories an analyzer
+// emits and example diagnostics, which cannot be derived from the
+// Analyzer values themselves.
+package analysisdoc
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A Doc specifies the reference documentation to generate for a
+// suite of analyzers.
+type Doc struct {
+	Title string // page title, such as the name of the checker command
+
+	Analyzers []*analysis.Analyzer
+
+	// Categories optionally maps an analyzer name to the
+	// diagnostic categories it can emit.
+	Categories map[string][]string
+
+	// Examples optionally maps an analyzer name to sample
+	// diagnostics, shown verbatim after its description.
+	Examples map[string][]string
+}
+
+// entry is the rendered form of one analyzer.
+type entry struct {
+	Name       string
+	Title      string // first paragraph of Doc
+	Body       string // remaining paragraphs
+	Categories []string
+	Examples   []string
+	Flags      []flagEntry
+}
+
+type flagEntry struct {
+	Name, Default, Usage string
+}
+
+// entries prepares the sorted per-analyzer contents of the page.
+func (d *Doc) entries() []entry {
+	var entries []entry
+	for _, a := range d.Analyzers {
+		paras := strings.SplitN(a.Doc, "\n\n", 2)
+		e := entry{
+			Name:       a.Name,
+			Title:      strings.TrimSpace(paras[0]),
+			Categories: d.Categories[a.Name],
+			Examples:   d.Examples[a.Name],
+		}
+		if len(paras) > 1 {
+			e.Body = strings.TrimSpace(paras[1])
+		}
+		a.Flags.VisitAll(func(f *flag.Flag) {
+			e.Flags = append(e.Flags, flagEntry{
+				Name:    a.Name + "." + f.Name,
+				Default: f.DefValue,
+				Usage:   f.Usage,
+			})
+		})
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Markdown writes the documentation to w in Markdown format.
+func (d *Doc) Markdown(w io.Writer) error {
+	entries := d.entries()
+
+	if _, err := fmt.Fprintf(w, "# %s\n\n", d.Title); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		fmt.Fprintf(w, "- [%s](#%s): %s\n", e.Name, e.Name, e.Title)
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "\n## %s\n\n%s\n", e.Name, e.Title)
+		if e.Body != "" {
+			fmt.Fprintf(w, "\n%s\n", e.Body)
+		}
+		if len(e.Categories) > 0 {
+			fmt.Fprintf(w, "\nCategories: `%s`\n", strings.Join(e.Categories, "`, `"))
+		}
+		if len(e.Examples) > 0 {
+			fmt.Fprintf(w, "\nExample diagnostics:\n\n")
+			for _, ex := range e.Examples {
+				fmt.Fprintf(w, "\t%s\n", ex)
+			}
+		}
+		if len(e.Flags) > 0 {
+			fmt.Fprintf(w, "\n| Flag | Default | Description |\n| --- | --- | --- |\n")
+			for _, f := range e.Flags {
+				fmt.Fprintf(w, "| `-%s` | `%s` | %s |\n", f.Name, f.Default, f.Usage)
+			}
+		}
+	}
+	return nil
+}
+
+var htmlTmpl = template.Must(template.New("analysisdoc").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{range .Entries}}<li><a href="#{{.Name}}">{{.Name}}</a>: {{.Title}}</li>
+{{end}}</ul>
+{{range .Entries}}
+<h2 id="{{.Name}}">{{.Name}}</h2>
+<p>{{.Title}}</p>
+{{if .Body}}<pre>{{.Body}}</pre>
+{{end}}{{if .Categories}}<p>Categories: {{range .Categories}}<code>{{.}}</code> {{end}}</p>
+{{end}}{{if .Examples}}<p>Example diagnostics:</p>
+<pre>{{range .Examples}}{{.}}
+{{end}}</pre>
+{{end}}{{if .Flags}}<table>
+<tr><th>Flag</th><th>Default</th><th>Description</th></tr>
+{{range .Flags}}<tr><td><code>-{{.Name}}</code></td><td><code>{{.Default}}</code></td><td>{{.Usage}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// HTML writes the documentation to w as a self-contained HTML page.
+func (d *Doc) HTML(w io.Writer) error {
+	return htmlTmpl.Execute(w, struct {
+		Title   string
+		Entries []entry
+	}{d.Title, d.entries()})
+}
@@ -0,0 +1,67 @@
+package analysisdoc_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysisdoc"
+)
+
+func testDoc() *analysisdoc.Doc {
+	a := &analysis.Analyzer{
+		Name: "sample",
+		Doc: `check for sample mistakes
+
+The longer description of the analyzer,
+over several lines.`,
+	}
+	a.Flags.Bool("strict", false, "report even minor mistakes")
+
+	return &analysisdoc.Doc{
+		Title:      "mychecker",
+		Analyzers:  []*analysis.Analyzer{a},
+		Categories: map[string][]string{"sample": {"minor", "major"}},
+		Examples:   map[string][]string{"sample": {`p.go:1:1: sample mistake`}},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testDoc().Markdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# mychecker",
+		"- [sample](#sample): check for sample mistakes",
+		"## sample",
+		"The longer description of the analyzer,",
+		"Categories: `minor`, `major`",
+		"p.go:1:1: sample mistake",
+		"| `-sample.strict` | `false` | report even minor mistakes |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testDoc().HTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<title>mychecker</title>",
+		`<h2 id="sample">sample</h2>`,
+		"<code>-sample.strict</code>",
+		"p.go:1:1: sample mistake",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML output does not contain %q:\n%s", want, got)
+		}
+	}
+}
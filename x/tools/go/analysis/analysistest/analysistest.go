@@ -2,7 +2,9 @@
 package analysistest
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"go/token"
 	"go/types"
 	"io/ioutil"
@@ -115,6 +117,165 @@ func Run(t Testing, dir string, a *analysis.Analyzer, patterns ...string) []*Res
 	return results
 }
 
+// RunWithSuggestedFixes behaves like Run, but additionally applies
+// the suggested fixes of each reported diagnostic and verifies the
+// rewritten source against golden files.
+//
+// For each source file to which at least one fix applies there must
+// be a golden file named after it with a ".golden" suffix. In the
+// simplest case it holds the result of applying every suggested fix
+// to the original file.
+//
+// A diagnostic may offer several alternative fixes, which typically
+// conflict, so they cannot be validated by a single output. In that
+// case the golden file is an archive of sections of the form
+//
+//	-- <message> --
+//	<file contents>
+//
+// and the fixes of all diagnostics that share a message are applied
+// together and compared against the section with that message.
+//
+// Both the rewritten source and the golden contents are passed
+// through go/format before comparison, so a golden file need not
+// match the original formatting byte for byte.
+func RunWithSuggestedFixes(t Testing, dir string, a *analysis.Analyzer, patterns ...string) []*Result {
+	results := Run(t, dir, a, patterns...)
+
+	// Group the edits by file and by fix message.
+	fileEdits := make(map[*token.File]map[string][]edit)
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		fset := result.Pass.Fset
+		for _, diag := range result.Diagnostics {
+			for _, fix := range diag.SuggestedFixes {
+				for _, te := range fix.TextEdits {
+					tf := fset.File(te.Pos)
+					if tf == nil {
+						t.Errorf("invalid position %v in fix %q", te.Pos, fix.Message)
+						continue
+					}
+					end := te.End
+					if !end.IsValid() {
+						end = te.Pos
+					}
+					if fileEdits[tf] == nil {
+						fileEdits[tf] = make(map[string][]edit)
+					}
+					fileEdits[tf][fix.Message] = append(fileEdits[tf][fix.Message], edit{
+						start: tf.Offset(te.Pos),
+						end:   tf.Offset(end),
+						text:  te.NewText,
+					})
+				}
+			}
+		}
+	}
+
+	for tf, fixes := range fileEdits {
+		orig, err := ioutil.ReadFile(tf.Name())
+		if err != nil {
+			t.Errorf("error reading %s: %v", tf.Name(), err)
+			continue
+		}
+		golden, err := ioutil.ReadFile(tf.Name() + ".golden")
+		if err != nil {
+			t.Errorf("error reading %s.golden: %v", tf.Name(), err)
+			continue
+		}
+
+		if sections := parseGolden(golden); sections != nil {
+			// An archive: each fix message has its own golden variant.
+			for message, edits := range fixes {
+				want, ok := sections[message]
+				if !ok {
+					t.Errorf("%s.golden has no section for fix %q", tf.Name(), message)
+					continue
+				}
+				compareFixes(t, tf.Name(), message, applyEdits(orig, edits), want)
+			}
+		} else {
+			// A plain golden file: apply all fixes together.
+			var all []edit
+			for _, edits := range fixes {
+				all = append(all, edits...)
+			}
+			compareFixes(t, tf.Name(), "", applyEdits(orig, all), golden)
+		}
+	}
+
+	return results
+}
+
+// An edit is a replacement of a range of bytes of a file.
+type edit struct {
+	start, end int // byte offsets
+	text       []byte
+}
+
+// applyEdits returns the result of applying the edits to contents.
+func applyEdits(contents []byte, edits []edit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), contents...)
+	for _, e := range edits {
+		var buf []byte
+		buf = append(buf, out[:e.start]...)
+		buf = append(buf, e.text...)
+		buf = append(buf, out[e.end:]...)
+		out = buf
+	}
+	return out
+}
+
+// compareFixes formats the fixed and golden contents and reports any
+// difference between them.
+func compareFixes(t Testing, filename, message string, got, want []byte) {
+	if formatted, err := format.Source(got); err == nil {
+		got = formatted
+	}
+	if formatted, err := format.Source(want); err == nil {
+		want = formatted
+	}
+	if !bytes.Equal(got, want) {
+		label := filename
+		if message != "" {
+			label = fmt.Sprintf("%s: fix %q", filename, message)
+		}
+		t.Errorf("suggested fixes failed for %s:\ngot:\n%s\nwant:\n%s", label, got, want)
+	}
+}
+
+// parseGolden parses the contents of a golden file as an archive of
+// sections introduced by "-- message --" lines, or returns nil if the
+// file does not start with a section marker.
+func parseGolden(data []byte) map[string][]byte {
+	if !bytes.HasPrefix(data, []byte("-- ")) {
+		return nil
+	}
+	sections := make(map[string][]byte)
+	var name string
+	var buf bytes.Buffer
+	flush := func() {
+		if name != "" {
+			sections[name] = append([]byte(nil), buf.Bytes()...)
+		}
+		buf.Reset()
+	}
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		trimmed := strings.TrimSpace(string(line))
+		if strings.HasPrefix(trimmed, "-- ") && strings.HasSuffix(trimmed, " --") {
+			flush()
+			name = strings.TrimSuffix(strings.TrimPrefix(trimmed, "-- "), " --")
+			continue
+		}
+		buf.Write(line)
+	}
+	flush()
+	return sections
+}
+
 // A Result holds the result of applying an analyzer to a package.
 type Result = checker.TestAnalyzerResult
 
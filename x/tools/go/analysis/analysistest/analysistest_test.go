@@ -2,12 +2,14 @@ package analysistest_test
 
 import (
 	"fmt"
+	"go/ast"
 	"log"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
 	"golang.org/x/tools/go/analysis/passes/findcall"
 )
@@ -84,6 +86,87 @@ func println(...interface{}) { println() } // want println:"found" "call of prin
 	}
 }
 
+// fixer reports a diagnostic on functions named F, offering two
+// alternative renamings, and on functions named Plain, offering one.
+var fixer = &analysis.Analyzer{
+	Name: "fixer",
+	Doc:  "offers fixes for test purposes",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		for _, f := range pass.Files {
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				rename := func(name string) analysis.SuggestedFix {
+					return analysis.SuggestedFix{
+						Message: "rename to " + name,
+						TextEdits: []analysis.TextEdit{
+							{Pos: fn.Name.Pos(), End: fn.Name.End(), NewText: []byte(name)},
+						},
+					}
+				}
+				switch fn.Name.Name {
+				case "F":
+					pass.Report(analysis.Diagnostic{
+						Pos:            fn.Pos(),
+						Message:        "fix me",
+						SuggestedFixes: []analysis.SuggestedFix{rename("G"), rename("H")},
+					})
+				case "Plain":
+					pass.Report(analysis.Diagnostic{
+						Pos:            fn.Pos(),
+						Message:        "fix me",
+						SuggestedFixes: []analysis.SuggestedFix{rename("Renamed")},
+					})
+				}
+			}
+		}
+		return nil, nil
+	},
+}
+
+// TestSuggestedFixes exercises RunWithSuggestedFixes, using both a
+// plain golden file and an archive of alternative fixes.
+func TestSuggestedFixes(t *testing.T) {
+	filemap := map[string]string{
+		"a/a.go": `package a
+
+func F() {} // want "fix me"
+`,
+		"a/a.go.golden": `-- rename to G --
+package a
+
+func G() {} // want "fix me"
+-- rename to H --
+package a
+
+func H() {} // want "fix me"
+`,
+		"a/b.go": `package a
+
+func Plain() {} // want "fix me"
+`,
+		"a/b.go.golden": `package a
+
+func Renamed() {} // want "fix me"
+`,
+	}
+	dir, cleanup, err := analysistest.WriteFiles(filemap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	var got []string
+	t2 := errorfunc(func(s string) { got = append(got, s) }) // a fake *testing.T
+	analysistest.RunWithSuggestedFixes(t2, dir, fixer, "a")
+
+	if got != nil {
+		t.Errorf("unexpected errors:\n%s", strings.Join(got, "\n"))
+	}
+}
+
 type errorfunc func(string)
 
 func (f errorfunc) Errorf(format string, args ...interface{}) {
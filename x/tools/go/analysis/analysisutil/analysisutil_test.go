@@ -0,0 +1,106 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisutil_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysisutil"
+)
+
+const src = `package p
+
+import "fmt"
+
+var x = (((y)))
+var y int
+
+func f() { fmt.Println(x + 1) }
+`
+
+func parseAndCheck(t *testing.T) (*token.FileSet, *ast.File, *types.Package, *types.Info) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Uses: make(map[*ast.Ident]types.Object),
+		Defs: make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fset, f, pkg, info
+}
+
+func TestUnparen(t *testing.T) {
+	fset, f, _, _ := parseAndCheck(t)
+	rhs := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0]
+	got := analysisutil.Unparen(rhs)
+	if id, ok := got.(*ast.Ident); !ok || id.Name != "y" {
+		t.Errorf("Unparen(%s) = %s, want y",
+			analysisutil.Format(fset, rhs), analysisutil.Format(fset, got))
+	}
+}
+
+func TestObjectOf(t *testing.T) {
+	_, f, _, info := parseAndCheck(t)
+	use := analysisutil.Unparen(f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0]).(*ast.Ident)
+	def := f.Decls[2].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Names[0]
+	if got := analysisutil.ObjectOf(info, use); got == nil || got != analysisutil.ObjectOf(info, def) {
+		t.Errorf("ObjectOf(y use) = %v, want the object of y's definition", got)
+	}
+}
+
+func TestImports(t *testing.T) {
+	_, _, pkg, _ := parseAndCheck(t)
+	if !analysisutil.Imports(pkg, "fmt") {
+		t.Errorf("Imports(p, fmt) = false, want true")
+	}
+	if analysisutil.Imports(pkg, "os") {
+		t.Errorf("Imports(p, os) = true, want false")
+	}
+}
+
+func TestShouldBuild(t *testing.T) {
+	for _, test := range []struct {
+		name, src    string
+		goos, goarch string
+		want         bool
+	}{
+		{"p.go", "package p\n", "linux", "amd64", true},
+		{"p_linux.go", "package p\n", "linux", "amd64", true},
+		{"p_linux.go", "package p\n", "windows", "amd64", false},
+		{"p_windows_386.go", "package p\n", "windows", "386", true},
+		{"p_windows_386.go", "package p\n", "windows", "amd64", false},
+		{"p_arm_test.go", "package p\n", "linux", "arm", true},
+		{"p_arm_test.go", "package p\n", "linux", "amd64", false},
+		{"p.go", "//go:build linux && !arm\n\npackage p\n", "linux", "amd64", true},
+		{"p.go", "//go:build linux && !arm\n\npackage p\n", "linux", "arm", false},
+		{"p.go", "// +build linux darwin\n\npackage p\n", "darwin", "amd64", true},
+		{"p.go", "// +build linux darwin\n\npackage p\n", "windows", "amd64", false},
+		{"p.go", "// +build linux\n// +build amd64\n\npackage p\n", "linux", "amd64", true},
+		{"p.go", "// +build linux\n// +build amd64\n\npackage p\n", "linux", "arm", false},
+	} {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, test.name, test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := analysisutil.ShouldBuild(fset, f, test.goos, test.goarch, nil)
+		if got != test.want {
+			t.Errorf("ShouldBuild(%s %q, %s/%s) = %t, want %t",
+				test.name, test.src, test.goos, test.goarch, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisutil
+
+// This file provides helpers that evaluate the build constraints of
+// a pass's files — //go:build and // +build comments, and the GOOS
+// and GOARCH elements of file names — against a chosen platform.
+// The driver type-checks a single configuration, but some analyzers
+// (such as atomicalign) care which files take part in the build on
+// other platforms.
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// BuildFiles returns the files of the pass that would take part in a
+// build for the given GOOS, GOARCH, and build tags; see ShouldBuild.
+func BuildFiles(pass *analysis.Pass, goos, goarch string, tags []string) []*ast.File {
+	var files []*ast.File
+	for _, f := range pass.Files {
+		if ShouldBuild(pass.Fset, f, goos, goarch, tags) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// ShouldBuild reports whether the file f would take part in a build
+// for the given GOOS, GOARCH, and build tags, according to its
+// //go:build and // +build constraints and the GOOS and GOARCH
+// elements of its file name. A //go:build line takes precedence over
+// any // +build lines, as in the go command.
+func ShouldBuild(fset *token.FileSet, f *ast.File, goos, goarch string, tags []string) bool {
+	name := filepath.Base(fset.File(f.Pos()).Name())
+	if !matchFileName(name, goos, goarch) {
+		return false
+	}
+	expr := constraintOf(f)
+	if expr == nil {
+		return true
+	}
+	return expr.Eval(func(tag string) bool {
+		if tag == goos || tag == goarch {
+			return true
+		}
+		for _, t := range tags {
+			if tag == t {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// constraintOf returns the build constraint expression of the file,
+// or nil if it is unconstrained. The constraint comments must appear
+// before the package clause. Multiple // +build lines are combined
+// with AND.
+func constraintOf(f *ast.File) constraint.Expr {
+	var plusBuild constraint.Expr
+	for _, g := range f.Comments {
+		if g.End() >= f.Package {
+			break
+		}
+		for _, c := range g.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					return expr // a //go:build line wins
+				}
+			case constraint.IsPlusBuild(c.Text):
+				if expr, err := constraint.Parse(c.Text); err == nil {
+					if plusBuild == nil {
+						plusBuild = expr
+					} else {
+						plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+					}
+				}
+			}
+		}
+	}
+	return plusBuild
+}
+
+// matchFileName reports whether the GOOS and GOARCH elements of the
+// file name, if any, match the given platform, in the manner of the
+// go/build package: *_GOOS.go, *_GOARCH.go, or *_GOOS_GOARCH.go.
+func matchFileName(name string, goos, goarch string) bool {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+	parts := strings.Split(name, "_")
+	if n := len(parts); n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2] == goos && parts[n-1] == goarch
+	}
+	if n := len(parts); n >= 2 {
+		if last := parts[n-1]; knownOS[last] {
+			return last == goos
+		} else if knownArch[last] {
+			return last == goarch
+		}
+	}
+	return true
+}
+
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+	"js": true, "linux": true, "nacl": true, "netbsd": true,
+	"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true,
+	"arm64": true, "loong64": true, "mips": true, "mips64": true,
+	"mips64le": true, "mipsle": true, "ppc64": true, "ppc64le": true,
+	"riscv64": true, "s390x": true, "wasm": true,
+}
@@ -0,0 +1,39 @@
+// Package checker provides a driver for running analyzers on packages
+// loaded with golang.org/x/tools/go/packages, for use by long-lived
+// tools such as language servers and watch-mode command-line tools.
+//
+// Unlike the command-line drivers multichecker and singlechecker,
+// which print diagnostics once the whole analysis is complete, this
+// driver reports results incrementally, as each package finishes, and
+// may be cancelled via a context.
+package checker
+
+import (
+	"context"
+
+	"golang.org/x/tools/go/analysis"
+	internal "golang.org/x/tools/go/analysis/internal/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// A Result holds the outcome of analyzing one package with one
+// analyzer. Pass is nil if the analysis could not be started, in
+// which case Err explains why.
+type Result = internal.PackageResult
+
+// Analyze runs the analyzers on the initial packages, which must have
+// been loaded with at least packages.LoadSyntax, and calls report
+// once per package, in completion order, as soon as all of the
+// package's analyzers have finished. The results appear in the same
+// order as the analyzers. Calls to report are serialized.
+//
+// If the context is cancelled, no further packages are started;
+// Analyze returns ctx.Err() once the packages already in flight have
+// completed. Prerequisite analyses and facts are computed only once
+// per package even when it is reachable from several roots.
+func Analyze(ctx context.Context, pkgs []*packages.Package, analyzers []*analysis.Analyzer, report func(pkg *packages.Package, results []*Result)) error {
+	if err := analysis.Validate(analyzers); err != nil {
+		return err
+	}
+	return internal.Stream(ctx, pkgs, analyzers, report)
+}
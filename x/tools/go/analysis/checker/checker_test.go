@@ -0,0 +1,97 @@
+package checker_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/analysis/passes/findcall"
+	"golang.org/x/tools/go/packages"
+)
+
+func load(t *testing.T, dir string, patterns ...string) []*packages.Package {
+	cfg := &packages.Config{
+		Mode: packages.LoadAllSyntax,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off", "GOPROXY=off"),
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("errors during loading")
+	}
+	return pkgs
+}
+
+func TestAnalyze(t *testing.T) {
+	files := map[string]string{
+		"a/a.go": `package a
+
+func A() { println("hi") }
+`,
+		"b/b.go": `package b
+
+func B() { println("bye"); println("again") }
+`,
+	}
+	dir, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	pkgs := load(t, dir, "a", "b")
+
+	got := make(map[string]int)
+	err = checker.Analyze(context.Background(), pkgs, []*analysis.Analyzer{findcall.Analyzer},
+		func(pkg *packages.Package, results []*checker.Result) {
+			for _, res := range results {
+				if res.Err != nil {
+					t.Errorf("analyzing %s: %v", pkg.PkgPath, res.Err)
+				}
+				got[pkg.PkgPath] += len(res.Diagnostics)
+			}
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"a": 1, "b": 2}
+	for pkg, n := range want {
+		if got[pkg] != n {
+			t.Errorf("package %s: got %d diagnostics, want %d", pkg, got[pkg], n)
+		}
+	}
+}
+
+func TestAnalyzeCancelled(t *testing.T) {
+	files := map[string]string{
+		"a/a.go": `package a
+
+func A() { println("hi") }
+`,
+	}
+	dir, cleanup, err := analysistest.WriteFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	pkgs := load(t, dir, "a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // cancel before any package is started
+
+	err = checker.Analyze(ctx, pkgs, []*analysis.Analyzer{findcall.Analyzer},
+		func(pkg *packages.Package, results []*checker.Result) {
+			t.Errorf("report called for %s after cancellation", pkg.PkgPath)
+		})
+	if err != context.Canceled {
+		t.Errorf("Analyze returned %v, want context.Canceled", err)
+	}
+}
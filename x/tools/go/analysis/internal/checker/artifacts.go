@@ -0,0 +1,48 @@
+// This file implements the -artifacts mode: the named values that
+// analyzers attach to their passes with ExportArtifact — counters,
+// summary tables, machine-readable reports — are gathered and written
+// out as JSON, so a pass can report aggregate statistics without
+// abusing diagnostics.
+
+package checker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+// ArtifactsFile is the name of the file to which the artifacts
+// exported by the analyzers are written as JSON, or "" to discard
+// them.
+var ArtifactsFile = ""
+
+// exportArtifact records a named artifact of the action's pass.
+func (act *action) exportArtifact(name string, value interface{}) {
+	if act.artifacts == nil {
+		act.artifacts = make(map[string]interface{})
+	}
+	act.artifacts[name] = value
+}
+
+// writeArtifacts writes the artifacts of the root actions to the
+// named file as JSON, a map of package ID to analyzer name to
+// artifact name to value.
+func writeArtifacts(filename string, roots []*action) error {
+	tree := make(map[string]map[string]map[string]interface{})
+	for _, act := range roots {
+		if len(act.artifacts) == 0 {
+			continue
+		}
+		byPkg := tree[act.pkg.ID]
+		if byPkg == nil {
+			byPkg = make(map[string]map[string]interface{})
+			tree[act.pkg.ID] = byPkg
+		}
+		byPkg[act.a.Name] = act.artifacts
+	}
+	data, err := json.MarshalIndent(tree, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0666)
+}
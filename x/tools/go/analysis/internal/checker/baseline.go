@@ -0,0 +1,146 @@
+// This file implements baseline files: a recording of a run's
+// diagnostics that later runs subtract from their output, so that
+// an analyzer can be adopted on a legacy codebase without first
+// fixing (or suppressing) every existing finding.
+
+package checker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	// Baseline is the name of a baseline file; diagnostics
+	// recorded in it are not reported again.
+	Baseline = ""
+
+	// WriteBaseline is the name of a file to which the current
+	// run's diagnostics are written in baseline format.
+	WriteBaseline = ""
+)
+
+// A baselineKey is a position-tolerant fingerprint of a diagnostic:
+// the analyzer, the reported file, and the message, but not the line
+// or column, so that unrelated edits to a file do not invalidate the
+// baseline. A baseline maps each fingerprint to the number of
+// occurrences it may absorb.
+type baselineKey struct {
+	analyzer string
+	file     string
+	message  string
+}
+
+type baseline map[baselineKey]int
+
+// match reports whether the baseline covers the given diagnostic,
+// consuming one occurrence if so.
+func (b baseline) match(k baselineKey) bool {
+	if b[k] > 0 {
+		b[k]--
+		return true
+	}
+	return false
+}
+
+// readBaseline parses a baseline file written by writeBaseline.
+// Each line has the form
+//	analyzer\tfile\tmessage
+// and blank lines and lines starting with "#" are ignored.
+func readBaseline(filename string) (baseline, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := make(baseline)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s:%d: malformed baseline entry", filename, line)
+		}
+		b[baselineKey{parts[0], parts[1], parts[2]}]++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writeBaseline records the diagnostics of all root actions in
+// baseline format, sorted for reproducibility.
+func writeBaseline(filename string, roots []*action) error {
+	var lines []string
+	for _, act := range forEachRoot(roots) {
+		for _, d := range act.diagnostics {
+			k := diagnosticKey(act, d)
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s", k.analyzer, k.file, k.message))
+		}
+	}
+	sort.Strings(lines)
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "# Baseline of known diagnostics. Lines are analyzer\\tfile\\tmessage.\n")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// applyBaseline removes from each root action the diagnostics
+// matched by the baseline and returns the number removed.
+func applyBaseline(b baseline, roots []*action) (nmatched int) {
+	for _, act := range forEachRoot(roots) {
+		kept := act.diagnostics[:0]
+		for _, d := range act.diagnostics {
+			if b.match(diagnosticKey(act, d)) {
+				nmatched++
+			} else {
+				kept = append(kept, d)
+			}
+		}
+		act.diagnostics = kept
+	}
+	return nmatched
+}
+
+func diagnosticKey(act *action, d analysis.Diagnostic) baselineKey {
+	return baselineKey{
+		analyzer: act.a.Name,
+		file:     act.pkg.Fset.Position(d.Pos).Filename,
+		message:  d.Message,
+	}
+}
+
+// forEachRoot returns the root actions reachable from roots,
+// each exactly once.
+func forEachRoot(roots []*action) []*action {
+	seen := make(map[*action]bool)
+	var result []*action
+	for _, act := range roots {
+		if act.isroot && !seen[act] {
+			seen[act] = true
+			result = append(result, act)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// TestBaselineRoundTrip checks that diagnostics written to a baseline
+// file are matched by a later run, even if their positions have
+// shifted, and that new diagnostics are not suppressed.
+func TestBaselineRoundTrip(t *testing.T) {
+	fset := token.NewFileSet()
+	f := fset.AddFile("a/a.go", -1, 1000)
+
+	a := &analysis.Analyzer{Name: "test"}
+	pkg := &packages.Package{Fset: fset}
+	act := &action{a: a, pkg: pkg, isroot: true, diagnostics: []analysis.Diagnostic{
+		{Pos: f.Pos(10), Message: "finding one"},
+		{Pos: f.Pos(20), Message: "finding two"},
+		{Pos: f.Pos(30), Message: "finding two"}, // same message twice
+	}}
+
+	name := filepath.Join(t.TempDir(), "baseline.txt")
+	if err := writeBaseline(name, []*action{act}); err != nil {
+		t.Fatalf("writeBaseline: %v", err)
+	}
+	b, err := readBaseline(name)
+	if err != nil {
+		t.Fatalf("readBaseline: %v", err)
+	}
+	want := baseline{
+		{"test", "a/a.go", "finding one"}: 1,
+		{"test", "a/a.go", "finding two"}: 2,
+	}
+	if len(b) != len(want) {
+		t.Fatalf("readBaseline returned %v, want %v", b, want)
+	}
+	for k, n := range want {
+		if b[k] != n {
+			t.Errorf("baseline[%v] = %d, want %d", k, b[k], n)
+		}
+	}
+
+	// A later run: positions have shifted, one "finding two" was
+	// fixed, and a new finding appeared. The baseline must absorb
+	// the old findings by analyzer, file, and message only.
+	act.diagnostics = []analysis.Diagnostic{
+		{Pos: f.Pos(110), Message: "finding one"},
+		{Pos: f.Pos(120), Message: "finding two"},
+		{Pos: f.Pos(130), Message: "finding three"},
+	}
+	if nmatched := applyBaseline(b, []*action{act}); nmatched != 2 {
+		t.Errorf("applyBaseline matched %d diagnostics, want 2", nmatched)
+	}
+	if len(act.diagnostics) != 1 || act.diagnostics[0].Message != "finding three" {
+		t.Errorf("after applyBaseline, diagnostics = %v, want only finding three", act.diagnostics)
+	}
+
+	// Occurrences beyond the recorded count resurface: the run above
+	// consumed one of the two recorded "finding two" occurrences, so
+	// only one of these two can be absorbed.
+	act.diagnostics = []analysis.Diagnostic{
+		{Pos: f.Pos(10), Message: "finding two"},
+		{Pos: f.Pos(20), Message: "finding two"},
+	}
+	if nmatched := applyBaseline(b, []*action{act}); nmatched != 1 {
+		t.Errorf("applyBaseline matched %d diagnostics, want 1", nmatched)
+	}
+	if len(act.diagnostics) != 1 {
+		t.Errorf("after applyBaseline, %d diagnostics remain, want 1", len(act.diagnostics))
+	}
+}
+
+func TestReadBaselineMalformed(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "baseline.txt")
+	if err := ioutil.WriteFile(name, []byte("# comment\n\nno tabs here\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBaseline(name); err == nil {
+		t.Errorf("readBaseline succeeded on malformed entry, want error")
+	}
+}
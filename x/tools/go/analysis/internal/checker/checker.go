@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/build"
 	"go/token"
 	"go/types"
 	"io/ioutil"
@@ -22,6 +24,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/tools/go/analysis"
@@ -31,6 +34,10 @@ import (
 var (
 	JSON = false
 
+	// SARIF determines whether diagnostics are emitted in the
+	// SARIF 2.1.0 interchange format instead of plain text.
+	SARIF = false
+
 	// Debug is a set of single-letter flags:
 	//
 	//	f	show [f]acts as they are created
@@ -43,6 +50,25 @@ var (
 
 	Context = -1 // if >=0, display offending line plus this many lines of context
 
+	// Severities overrides the severity of all diagnostics of the
+	// named analyzers, promoting or demoting them regardless of
+	// what the analyzer requested.
+	Severities = make(severityFlag)
+
+	// IncludeTests indicates whether test files should be analyzed too:
+	// _test.go files, external test packages, and the test variants
+	// of imported packages. Some analyzers (tests, testinggoroutine)
+	// are useless without them; others only report noise there.
+	IncludeTests = true
+
+	// Suppress determines whether suppression comments in the
+	// analyzed source, such as
+	//	//lint:ignore name reason
+	// and
+	//	//nolint:name
+	// are honored. A suppressed diagnostic is not printed.
+	Suppress = false
+
 	// Log files for optional performance tracing.
 	CPUProfile, MemProfile, Trace string
 )
@@ -50,20 +76,93 @@ var (
 // RegisterFlags registers command-line flags used the analysis driver.
 func RegisterFlags() {
 	flag.BoolVar(&JSON, "json", JSON, "emit JSON output")
+	flag.BoolVar(&SARIF, "sarif", SARIF, "emit SARIF 2.1.0 output")
 	flag.StringVar(&Debug, "debug", Debug, `debug flags, any subset of "lpsv"`)
 	flag.IntVar(&Context, "c", Context, `display offending line with this many lines of context`)
+	flag.BoolVar(&IncludeTests, "test", IncludeTests, "indicates whether test files should be analyzed, too")
+	flag.BoolVar(&Suppress, "suppress", Suppress, "honor //lint:ignore and //nolint suppression comments")
+	flag.Var(Severities, "severity", `set severity of analyzers, e.g. "printf=warning,cgocall=error"`)
+	flag.StringVar(&Baseline, "baseline", Baseline, "suppress diagnostics recorded in this baseline file")
+	flag.StringVar(&WriteBaseline, "writebaseline", WriteBaseline, "write current diagnostics to this file in baseline format")
+	flag.StringVar(&DiffFile, "diff", DiffFile, "report only diagnostics on lines changed by this unified diff file")
+	flag.BoolVar(&Patch, "patch", Patch, "print suggested fixes as a unified diff on standard output")
+	flag.StringVar(&RunFilter, "run", RunFilter, `run only analyzers matching this regexp (e.g. "cgocall|unsafeptr")`)
+	flag.StringVar(&ConfigFile, "config", ConfigFile, "load analyzer settings and exclusions from this JSON file (default vet.json, if present)")
+	flag.StringVar(&ArtifactsFile, "artifacts", ArtifactsFile, "write artifacts exported by the analyzers to this file as JSON")
+	flag.StringVar(&Platforms, "platforms", Platforms, `analyze under each of these GOOS/GOARCH configurations (e.g. "linux/amd64,windows/386")`)
 
 	flag.StringVar(&CPUProfile, "cpuprofile", "", "write CPU profile to this file")
 	flag.StringVar(&MemProfile, "memprofile", "", "write memory profile to this file")
 	flag.StringVar(&Trace, "trace", "", "write trace log to this file")
 }
 
+// A severityFlag is a flag.Value that maps analyzer names to the
+// severity to use for their diagnostics in place of the default.
+type severityFlag map[string]analysis.Severity
+
+func (f severityFlag) String() string {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%s=%s", name, f[name])
+	}
+	return buf.String()
+}
+
+func (f severityFlag) Set(s string) error {
+	for _, item := range strings.Split(s, ",") {
+		eq := strings.IndexByte(item, '=')
+		if eq < 0 {
+			return fmt.Errorf("invalid severity %q; want name=error|warning|info", item)
+		}
+		name := item[:eq]
+		switch item[eq+1:] {
+		case "error":
+			f[name] = analysis.SeverityError
+		case "warning":
+			f[name] = analysis.SeverityWarning
+		case "info":
+			f[name] = analysis.SeverityInfo
+		default:
+			return fmt.Errorf("invalid severity %q; want name=error|warning|info", item)
+		}
+	}
+	return nil
+}
+
+// severity returns the effective severity of a diagnostic:
+// the -severity flag override if any, then the diagnostic's own
+// severity, then the analyzer's default, and finally error.
+func severity(a *analysis.Analyzer, d analysis.Diagnostic) analysis.Severity {
+	if s, ok := Severities[a.Name]; ok {
+		return s
+	}
+	if d.Severity != analysis.SeverityDefault {
+		return d.Severity
+	}
+	if a.Severity != analysis.SeverityDefault {
+		return a.Severity
+	}
+	return analysis.SeverityError
+}
+
 // Run loads the packages specified by args using go/packages,
 // then applies the specified analyzers to them.
 // Analysis flags must already have been set.
 // It provides most of the logic for the main functions of both the
 // singlechecker and the multi-analysis commands.
-func Run(args []string, analyzers []*analysis.Analyzer) error {
+//
+// The exit code is 0 if no diagnostics of error severity were
+// reported, and 1 otherwise; diagnostics of warning or info severity
+// do not affect it.
+func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 	if CPUProfile != "" {
 		f, err := os.Create(CPUProfile)
 		if err != nil {
@@ -113,20 +212,62 @@ func Run(args []string, analyzers []*analysis.Analyzer) error {
 		log.Printf("load %s", args)
 	}
 
+	var cfg *config
+	if name := configFile(); name != "" {
+		var err error
+		if cfg, err = readConfig(name); err != nil {
+			log.Print(err)
+			return 1
+		}
+		if analyzers, err = applyConfig(cfg, analyzers); err != nil {
+			log.Print(err)
+			return 1
+		}
+	}
+
+	analyzers, catRE, err := filterAnalyzers(analyzers)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
 	// Optimization: if the selected analyzers don't produce/consume
 	// facts, we need source only for the initial packages.
 	allSyntax := needFacts(analyzers)
-	initial, err := load(args, allSyntax)
-	if err != nil {
-		return err
-	}
 
-	roots := analyze(initial, analyzers)
+	var roots []*action
+	if Platforms == "" {
+		initial, err := load(args, allSyntax)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		roots = analyze(initial, analyzers)
+	} else {
+		platforms, err := parsePlatforms(Platforms)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		for _, p := range platforms {
+			setPlatform(p)
+			initial, err := load(args, allSyntax)
+			if err != nil {
+				log.Print(err)
+				return 1
+			}
+			roots = append(roots, analyze(initial, analyzers)...)
+		}
+	}
+	if cfg != nil {
+		applyExclusions(cfg, roots)
+	}
+	if catRE != nil {
+		filterCategories(catRE, roots)
+	}
 
 	// Print the results.
-	printDiagnostics(roots)
-
-	return nil
+	return printDiagnostics(roots)
 }
 
 // load loads the initial packages.
@@ -137,7 +278,7 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 	}
 	conf := packages.Config{
 		Mode:  mode,
-		Tests: true,
+		Tests: IncludeTests,
 	}
 	initial, err := packages.Load(&conf, patterns...)
 	if err == nil {
@@ -186,7 +327,17 @@ type TestAnalyzerResult struct {
 }
 
 func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
-	// Construct the action graph.
+	roots := actionGraph(pkgs, analyzers)
+
+	// Execute the graph in parallel.
+	execAll(roots)
+
+	return roots
+}
+
+// actionGraph constructs the action graph for the initial packages
+// and returns its roots, one per (analyzer, package) pair.
+func actionGraph(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action {
 	if dbg('v') {
 		log.Printf("building graph of analysis passes")
 	}
@@ -241,8 +392,13 @@ func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action
 		}
 	}
 
-	// Execute the graph in parallel.
-	execAll(roots)
+	// Count each action's dependents so that its outputs can be
+	// released as soon as the last of them has finished.
+	for _, act := range actions {
+		for _, dep := range act.deps {
+			dep.pending++
+		}
+	}
 
 	return roots
 }
@@ -250,12 +406,51 @@ func analyze(pkgs []*packages.Package, analyzers []*analysis.Analyzer) []*action
 // printDiagnostics prints the diagnostics for the root packages in either
 // plain text or JSON format. JSON format also includes errors for any
 // dependencies.
-func printDiagnostics(roots []*action) {
+//
+// It returns the exit code to use: nonzero if any diagnostic of
+// error severity was printed, or if any analysis failed.
+func printDiagnostics(roots []*action) (exitcode int) {
+	if WriteBaseline != "" {
+		if err := writeBaseline(WriteBaseline, roots); err != nil {
+			log.Fatalf("writing baseline: %v", err)
+		}
+	}
+	if Baseline != "" {
+		base, err := readBaseline(Baseline)
+		if err != nil {
+			log.Fatalf("reading baseline: %v", err)
+		}
+		applyBaseline(base, roots)
+	}
+	if DiffFile != "" {
+		files, err := readDiffFile()
+		if err != nil {
+			log.Fatalf("reading diff: %v", err)
+		}
+		applyDiff(files, roots)
+	}
+
+	if Patch {
+		if err := printPatch(os.Stdout, roots); err != nil {
+			log.Fatalf("printing patch: %v", err)
+		}
+	}
+	if ArtifactsFile != "" {
+		if err := writeArtifacts(ArtifactsFile, roots); err != nil {
+			log.Fatalf("writing artifacts: %v", err)
+		}
+	}
+
+	if SARIF {
+		return printSarifDiagnostics(roots)
+	}
+
 	// Print the output.
 	//
 	// Print diagnostics only for root packages,
 	// but errors for all packages.
 	printed := make(map[*action]bool)
+	nsuppressed := 0
 	var print func(*action)
 	var visitAll func(actions []*action)
 	visitAll = func(actions []*action) {
@@ -282,16 +477,27 @@ func printDiagnostics(roots []*action) {
 					Err string `json:"error"`
 				}
 				m[act.a.Name] = jsonError{act.err.Error()}
+				exitcode = 1
 			} else if act.isroot {
 				type jsonDiagnostic struct {
 					Category string `json:"category,omitempty"`
+					Severity string `json:"severity"`
 					Posn     string `json:"posn"`
 					Message  string `json:"message"`
 				}
 				var diagnostics []jsonDiagnostic
 				for _, f := range act.diagnostics {
+					if Suppress && suppressed(act, f) {
+						nsuppressed++
+						continue
+					}
+					sev := severity(act.a, f)
+					if sev == analysis.SeverityError {
+						exitcode = 1
+					}
 					diagnostics = append(diagnostics, jsonDiagnostic{
 						Category: f.Category,
+						Severity: sev.String(),
 						Posn:     act.pkg.Fset.Position(f.Pos).String(),
 						Message:  f.Message,
 					})
@@ -328,10 +534,16 @@ func printDiagnostics(roots []*action) {
 		print = func(act *action) {
 			if act.err != nil {
 				fmt.Fprintf(os.Stderr, "%s: %v\n", act.a.Name, act.err)
+				exitcode = 1
 				return
 			}
 			if act.isroot {
 				for _, f := range act.diagnostics {
+					if Suppress && suppressed(act, f) {
+						nsuppressed++
+						continue
+					}
+
 					// We don't display a.Name/f.Category
 					// as most users don't care.
 
@@ -343,7 +555,19 @@ func printDiagnostics(roots []*action) {
 					}
 					seen[k] = true
 
-					fmt.Fprintf(os.Stderr, "%s: %s\n", posn, f.Message)
+					// Errors are the common case and carry no
+					// prefix; lesser severities are labeled.
+					prefix := ""
+					switch severity(act.a, f) {
+					case analysis.SeverityError:
+						exitcode = 1
+					case analysis.SeverityWarning:
+						prefix = "warning: "
+					case analysis.SeverityInfo:
+						prefix = "info: "
+					}
+
+					fmt.Fprintf(os.Stderr, "%s: %s%s\n", posn, prefix, f.Message)
 
 					// -c=0: show offending line of code in context.
 					if Context >= 0 {
@@ -361,6 +585,10 @@ func printDiagnostics(roots []*action) {
 		visitAll(roots)
 	}
 
+	if nsuppressed > 0 {
+		fmt.Fprintf(os.Stderr, "%d diagnostics suppressed by comments\n", nsuppressed)
+	}
+
 	// Print timing info.
 	if dbg('t') {
 		if !dbg('p') {
@@ -386,6 +614,99 @@ func printDiagnostics(roots []*action) {
 			}
 		}
 	}
+
+	return exitcode
+}
+
+// suppressed reports whether the diagnostic is silenced by a
+// suppression comment of the form
+//	//lint:ignore name reason
+// or
+//	//nolint[:name1,name2]
+// on the same line as the diagnostic, on the line immediately above
+// it, or in the doc comment of the enclosing declaration.
+// The name "all" in a lint:ignore comment matches every analyzer.
+func suppressed(act *action, d analysis.Diagnostic) bool {
+	posn := act.pkg.Fset.Position(d.Pos)
+
+	// Find the file containing the diagnostic.
+	var file *ast.File
+	for _, f := range act.pkg.Syntax {
+		if f.Pos() <= d.Pos && d.Pos <= f.End() {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return false
+	}
+
+	matches := func(cg *ast.CommentGroup) bool {
+		if cg == nil {
+			return false
+		}
+		for _, c := range cg.List {
+			if directiveSuppresses(c.Text, act.a.Name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Comments on the diagnostic's line or the line above it.
+	for _, cg := range file.Comments {
+		line := act.pkg.Fset.Position(cg.Pos()).Line
+		if (line == posn.Line || line == posn.Line-1) && matches(cg) {
+			return true
+		}
+	}
+
+	// Doc comment of the enclosing top-level declaration.
+	for _, decl := range file.Decls {
+		if decl.Pos() <= d.Pos && d.Pos <= decl.End() {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				return matches(decl.Doc)
+			case *ast.GenDecl:
+				return matches(decl.Doc)
+			}
+		}
+	}
+	return false
+}
+
+// directiveSuppresses reports whether the comment text is a
+// suppression directive that applies to the named analyzer.
+func directiveSuppresses(text, name string) bool {
+	if !strings.HasPrefix(text, "//") {
+		return false // e.g. /*-style comment
+	}
+	text = text[len("//"):]
+
+	if strings.HasPrefix(text, "lint:ignore ") {
+		fields := strings.Fields(text[len("lint:ignore "):])
+		if len(fields) < 2 {
+			return false // a reason is required
+		}
+		for _, n := range strings.Split(fields[0], ",") {
+			if n == name || n == "all" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if text == "nolint" {
+		return true // unqualified form applies to all analyzers
+	}
+	if strings.HasPrefix(text, "nolint:") {
+		for _, n := range strings.Split(text[len("nolint:"):], ",") {
+			if strings.TrimSpace(n) == name {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // needFacts reports whether any analysis required by the specified set
@@ -419,11 +740,13 @@ type action struct {
 	pass         *analysis.Pass
 	isroot       bool
 	deps         []*action
+	pending      int32 // dependents that have not yet finished; see releaseDeps
 	objectFacts  map[objectFactKey]analysis.Fact
 	packageFacts map[packageFactKey]analysis.Fact
 	inputs       map[*analysis.Analyzer]interface{}
 	result       interface{}
 	diagnostics  []analysis.Diagnostic
+	artifacts    map[string]interface{}
 	err          error
 	duration     time.Duration
 }
@@ -462,10 +785,27 @@ func execAll(actions []*action) {
 
 func (act *action) exec() { act.once.Do(act.execOnce) }
 
+// gate limits the number of actions that may do analysis work
+// concurrently. Each action's goroutine is cheap while it waits
+// for its dependencies, but the analysis itself is CPU-bound, so
+// there is no benefit in running more of them than GOMAXPROCS;
+// doing so only increases peak memory and scheduler contention.
+var gate = make(chan struct{}, runtime.GOMAXPROCS(0))
+
 func (act *action) execOnce() {
 	// Analyze dependencies.
 	execAll(act.deps)
 
+	// Release the dependencies' outputs once this action, their
+	// last dependent, is done with them.
+	defer act.releaseDeps()
+
+	// Limit CPU-bound work to GOMAXPROCS.
+	// (The token is acquired only after the dependencies are done,
+	// so the wait below cannot be part of a cycle.)
+	gate <- struct{}{}
+	defer func() { <-gate }()
+
 	// TODO(adonovan): uncomment this during profiling.
 	// It won't build pre-go1.11 but conditional compilation
 	// using build tags isn't warranted.
@@ -525,8 +865,16 @@ func (act *action) execOnce() {
 		OtherFiles:        act.pkg.OtherFiles,
 		Pkg:               act.pkg.Types,
 		TypesInfo:         act.pkg.TypesInfo,
+		TypesSizes:        act.pkg.TypesSizes,
+		TypeErrors:        act.pkg.TypeErrors,
+		Module:            moduleOf(act.pkg),
+		GOOS:              envOr("GOOS", runtime.GOOS),
+		GOARCH:            envOr("GOARCH", runtime.GOARCH),
+		BuildTags:         build.Default.BuildTags,
+		ReadFile:          act.readFile,
 		ResultOf:          inputs,
 		Report:            func(d analysis.Diagnostic) { act.diagnostics = append(act.diagnostics, d) },
+		ExportArtifact:    act.exportArtifact,
 		ImportObjectFact:  act.importObjectFact,
 		ExportObjectFact:  act.exportObjectFact,
 		ImportPackageFact: act.importPackageFact,
@@ -552,6 +900,74 @@ func (act *action) execOnce() {
 	// disallow calls after Run
 	pass.ExportObjectFact = nil
 	pass.ExportPackageFact = nil
+	pass.ExportArtifact = nil
+}
+
+// releaseDeps releases the outputs of this action's dependencies
+// once the last dependent has finished with them.
+//
+// All analyzers of a package share its FileSet, syntax trees, and
+// types.Info, so the per-action outputs — the result value (for
+// analyzers like buildssa, often the largest allocation of the run),
+// the pass, and the fact tables — dominate the memory of large runs.
+// Dependents copy the facts they need during their own execution, so
+// nothing is read from a non-root action afterwards; only root
+// actions are retained, for reporting.
+func (act *action) releaseDeps() {
+	for _, dep := range act.deps {
+		if atomic.AddInt32(&dep.pending, -1) == 0 && !dep.isroot {
+			dep.pass = nil
+			dep.result = nil
+			dep.objectFacts = nil
+			dep.packageFacts = nil
+			dep.diagnostics = nil
+			dep.artifacts = nil
+		}
+	}
+}
+
+// moduleOf returns the module information for the package reported
+// by go list, or nil in GOPATH mode.
+func moduleOf(pkg *packages.Package) *analysis.Module {
+	if m := pkg.Module; m != nil {
+		return &analysis.Module{Path: m.Path, Version: m.Version, Main: m.Main}
+	}
+	return nil
+}
+
+// envOr returns the value of the environment variable, which the go
+// tool also honors, or the given default.
+func envOr(name, value string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return value
+}
+
+// readFile implements Pass.ReadFile.
+// This driver has no overlays, so it serves files from disk, but
+// only the files that belong to the action's package, preserving
+// the contract that lets other drivers virtualize the file system.
+func (act *action) readFile(filename string) ([]byte, error) {
+	valid := false
+	for _, f := range act.pkg.Syntax {
+		if act.pkg.Fset.Position(f.Pos()).Filename == filename {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		for _, name := range act.pkg.OtherFiles {
+			if name == filename {
+				valid = true
+				break
+			}
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("Pass.ReadFile: %s is not among the files of package %s", filename, act.pkg)
+	}
+	return ioutil.ReadFile(filename)
 }
 
 // inheritFacts populates act.facts with
@@ -0,0 +1,143 @@
+// This file implements configuration file support: a JSON file,
+// conventionally named vet.json and kept at the root of a repository,
+// that enables or disables analyzers, sets their flags, and excludes
+// diagnostics in generated or vendored files, so that a team's policy
+// lives in the repository rather than in wrapper scripts.
+//
+// Example:
+//
+//	{
+//		"analyzers": {"cgocall": false},
+//		"flags": {"unusedresult": {"funcs": "fmt.Sprintf"}},
+//		"exclude": ["\\.pb\\.go$", "^vendor/"]
+//	}
+//
+// Analyzers not mentioned keep their default (enabled) state, and
+// flags set on the command line override the file.
+
+package checker
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ConfigFile is the name of the configuration file. If the flag is
+// not set, a vet.json file in the current directory is used, if
+// present.
+var ConfigFile = ""
+
+// A config is the parsed form of a configuration file.
+type config struct {
+	Analyzers map[string]bool              // enable (true) or disable (false) analyzers by name
+	Flags     map[string]map[string]string // per-analyzer flag settings
+	Exclude   []string                     // diagnostics in files matching these regexps are dropped
+
+	exclude []*regexp.Regexp // compiled form of Exclude
+}
+
+// configFile returns the name of the configuration file to load,
+// or "" if there is none.
+func configFile() string {
+	if ConfigFile != "" {
+		return ConfigFile
+	}
+	if _, err := os.Stat("vet.json"); err == nil {
+		return "vet.json"
+	}
+	return ""
+}
+
+// readConfig parses the configuration file.
+func readConfig(filename string) (*config, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(config)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	for _, pat := range cfg.Exclude {
+		re, err := regexp.Compile(pat)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid exclusion: %v", filename, err)
+		}
+		cfg.exclude = append(cfg.exclude, re)
+	}
+	return cfg, nil
+}
+
+// applyConfig returns the analyzers enabled by the configuration,
+// with their flags set, reporting any name that matches no analyzer
+// so that a typo does not silently leave a check running or idle.
+func applyConfig(cfg *config, analyzers []*analysis.Analyzer) ([]*analysis.Analyzer, error) {
+	byName := make(map[string]*analysis.Analyzer)
+	for _, a := range analyzers {
+		byName[a.Name] = a
+	}
+	for name := range cfg.Analyzers {
+		if byName[name] == nil {
+			return nil, fmt.Errorf("config enables unknown analyzer %q", name)
+		}
+	}
+	// The drivers connect analyzer flags to the command line as
+	// -analyzer.flag (multichecker) or -flag (singlechecker);
+	// any of those given explicitly wins over the file.
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for name, flags := range cfg.Flags {
+		a := byName[name]
+		if a == nil {
+			return nil, fmt.Errorf("config sets flags of unknown analyzer %q", name)
+		}
+		for fname, value := range flags {
+			if a.Flags.Lookup(fname) == nil {
+				return nil, fmt.Errorf("config sets unknown flag -%s.%s", name, fname)
+			}
+			if set[name+"."+fname] || set[fname] {
+				continue
+			}
+			if err := a.Flags.Set(fname, value); err != nil {
+				return nil, fmt.Errorf("config: -%s.%s: %v", name, fname, err)
+			}
+		}
+	}
+
+	var keep []*analysis.Analyzer
+	for _, a := range analyzers {
+		if enable, ok := cfg.Analyzers[a.Name]; !ok || enable {
+			keep = append(keep, a)
+		}
+	}
+	return keep, nil
+}
+
+// applyExclusions discards diagnostics in files whose names match an
+// exclusion pattern of the configuration.
+func applyExclusions(cfg *config, roots []*action) {
+	if len(cfg.exclude) == 0 {
+		return
+	}
+	for _, act := range roots {
+		var kept []analysis.Diagnostic
+	diags:
+		for _, d := range act.diagnostics {
+			name := act.pkg.Fset.Position(d.Pos).Filename
+			for _, re := range cfg.exclude {
+				if re.MatchString(name) {
+					continue diags
+				}
+			}
+			kept = append(kept, d)
+		}
+		act.diagnostics = kept
+	}
+}
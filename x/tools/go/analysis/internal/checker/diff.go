@@ -0,0 +1,124 @@
+// This file implements changed-lines filtering: given a unified
+// diff, only diagnostics whose positions fall on lines added or
+// modified by the diff are reported. Pre-submit bots use this to
+// avoid blocking a change on pre-existing problems nearby.
+
+package checker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DiffFile is the name of a file containing a unified diff.
+// If set, only diagnostics on lines added by the diff are reported.
+var DiffFile = ""
+
+// changedLines parses a unified diff and returns, for each post-image
+// file, the set of line numbers added or modified by the diff.
+func changedLines(r io.Reader) (map[string]map[int]bool, error) {
+	files := make(map[string]map[int]bool)
+
+	var lines map[int]bool // changed lines of current file
+	line := 0              // current post-image line number
+	inHunk := false
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			name := strings.TrimSpace(text[len("+++ "):])
+			if i := strings.IndexByte(name, '\t'); i >= 0 {
+				name = name[:i] // strip timestamp
+			}
+			name = strings.TrimPrefix(name, "b/")
+			inHunk = false
+			if name == "/dev/null" {
+				lines = nil // deleted file
+				continue
+			}
+			lines = files[name]
+			if lines == nil {
+				lines = make(map[int]bool)
+				files[name] = lines
+			}
+
+		case strings.HasPrefix(text, "@@ "):
+			// @@ -oldstart,oldcount +newstart,newcount @@
+			fields := strings.Fields(text)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, fmt.Errorf("diff:%d: malformed hunk header", n)
+			}
+			start := strings.TrimPrefix(fields[2], "+")
+			if i := strings.IndexByte(start, ','); i >= 0 {
+				start = start[:i]
+			}
+			var err error
+			line, err = strconv.Atoi(start)
+			if err != nil {
+				return nil, fmt.Errorf("diff:%d: malformed hunk header", n)
+			}
+			inHunk = true
+
+		case inHunk && strings.HasPrefix(text, "+"):
+			if lines != nil {
+				lines[line] = true
+			}
+			line++
+
+		case inHunk && strings.HasPrefix(text, " "):
+			line++
+
+			// Lines starting with "-" belong to the old file
+			// and do not advance the new line counter.
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// applyDiff removes from each root action the diagnostics that do
+// not fall on a changed line, and returns the number removed.
+// A diagnostic matches a diff entry if its file name ends with the
+// entry's (typically repository-relative) path.
+func applyDiff(files map[string]map[int]bool, roots []*action) (nfiltered int) {
+	for _, act := range forEachRoot(roots) {
+		kept := act.diagnostics[:0]
+		for _, d := range act.diagnostics {
+			posn := act.pkg.Fset.Position(d.Pos)
+			if onChangedLine(files, posn.Filename, posn.Line) {
+				kept = append(kept, d)
+			} else {
+				nfiltered++
+			}
+		}
+		act.diagnostics = kept
+	}
+	return nfiltered
+}
+
+func onChangedLine(files map[string]map[int]bool, filename string, line int) bool {
+	for name, lines := range files {
+		if filename == name || strings.HasSuffix(filename, "/"+name) {
+			return lines[line]
+		}
+	}
+	return false
+}
+
+// readDiffFile parses the unified diff named by DiffFile.
+func readDiffFile() (map[string]map[int]bool, error) {
+	f, err := os.Open(DiffFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return changedLines(f)
+}
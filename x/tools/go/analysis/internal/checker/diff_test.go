@@ -0,0 +1,191 @@
+package checker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChangedLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		diff    string
+		want    map[string]map[int]bool
+		wantErr bool
+	}{
+		{
+			name: "single hunk",
+			diff: `--- a/p/a.go
++++ b/p/a.go
+@@ -1,4 +1,5 @@
+ package p
+-func f() {}
++func f() {
++}
+ func g() {}
+`,
+			want: map[string]map[int]bool{
+				"p/a.go": {2: true, 3: true},
+			},
+		},
+		{
+			name: "multiple hunks advance line counter",
+			diff: `--- a/p/a.go
++++ b/p/a.go
+@@ -2,3 +2,4 @@
+ two
++two point five
+ three
+ four
+@@ -10,2 +11,3 @@
+ ten
++ten point five
+ eleven
+`,
+			want: map[string]map[int]bool{
+				"p/a.go": {3: true, 12: true},
+			},
+		},
+		{
+			name: "multiple files",
+			diff: `--- a/p/a.go
++++ b/p/a.go
+@@ -1,1 +1,2 @@
+ one
++added to a
+--- a/p/b.go
++++ b/p/b.go
+@@ -1,1 +1,2 @@
+ one
++added to b
+`,
+			want: map[string]map[int]bool{
+				"p/a.go": {2: true},
+				"p/b.go": {2: true},
+			},
+		},
+		{
+			name: "rename with timestamp header",
+			diff: "--- a/p/old.go\t2024-01-01 00:00:00\n" +
+				"+++ b/p/new.go\t2024-01-01 00:00:00\n" +
+				"@@ -1,1 +1,2 @@\n" +
+				" one\n" +
+				"+two\n",
+			want: map[string]map[int]bool{
+				"p/new.go": {2: true},
+			},
+		},
+		{
+			name: "deleted file is ignored",
+			diff: `--- a/p/gone.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-one
+-two
+--- a/p/a.go
++++ b/p/a.go
+@@ -1,1 +1,2 @@
+ one
++two
+`,
+			want: map[string]map[int]bool{
+				"p/a.go": {2: true},
+			},
+		},
+		{
+			name: "new file",
+			diff: `--- /dev/null
++++ b/p/new.go
+@@ -0,0 +1,2 @@
++one
++two
+`,
+			want: map[string]map[int]bool{
+				"p/new.go": {1: true, 2: true},
+			},
+		},
+		{
+			name: "deletions do not advance line counter",
+			diff: `--- a/p/a.go
++++ b/p/a.go
+@@ -1,4 +1,3 @@
+ one
+-two
+-three
++TWO
+ four
+`,
+			want: map[string]map[int]bool{
+				"p/a.go": {2: true},
+			},
+		},
+		{
+			name: "plus lines outside a hunk are not counted",
+			diff: `+++ not a file header continuation
+--- a/p/a.go
++++ b/p/a.go
+@@ -1,1 +1,2 @@
+ one
++two
+`,
+			want: map[string]map[int]bool{
+				"not a file header continuation": {},
+				"p/a.go":                         {2: true},
+			},
+		},
+		{
+			name:    "malformed hunk header",
+			diff:    "--- a/p/a.go\n+++ b/p/a.go\n@@ bogus @@\n",
+			wantErr: true,
+		},
+		{
+			name:    "malformed hunk start",
+			diff:    "--- a/p/a.go\n+++ b/p/a.go\n@@ -1,1 +x,2 @@\n",
+			wantErr: true,
+		},
+		{
+			name: "empty diff",
+			diff: "",
+			want: map[string]map[int]bool{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := changedLines(strings.NewReader(test.diff))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("changedLines succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("changedLines: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("changedLines = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOnChangedLine(t *testing.T) {
+	files := map[string]map[int]bool{
+		"p/a.go": {2: true},
+	}
+	for _, test := range []struct {
+		filename string
+		line     int
+		want     bool
+	}{
+		{"p/a.go", 2, true},              // exact match
+		{"/work/repo/p/a.go", 2, true},   // absolute path with diff-relative suffix
+		{"/work/repo/p/a.go", 3, false},  // unchanged line
+		{"q/a.go", 2, false},             // different file
+		{"/work/deep/a.go", 2, false},    // suffix must match on a path boundary
+		{"/work/repo/xp/a.go", 2, false}, // ditto
+	} {
+		if got := onChangedLine(files, test.filename, test.line); got != test.want {
+			t.Errorf("onChangedLine(%q, %d) = %v, want %v", test.filename, test.line, got, test.want)
+		}
+	}
+}
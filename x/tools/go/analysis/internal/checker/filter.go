@@ -0,0 +1,76 @@
+// This file implements the -run flag: a regular expression that
+// selects which of the driver's analyzers to run, and optionally
+// which of their diagnostic categories to report, so that one check
+// can be run out of a large multichecker without rebuilding it.
+
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// RunFilter is a regular expression matched against analyzer names;
+// only matching analyzers are run. As with go test -run, the pattern
+// may be followed by a slash and a second expression, which is then
+// matched against diagnostic categories: -run 'printf/dynamic'
+// reports only the printf analyzer's "dynamic" diagnostics.
+var RunFilter = ""
+
+// filterAnalyzers returns the analyzers selected by the -run flag,
+// along with the category pattern, if any, to be applied to their
+// diagnostics by filterCategories.
+func filterAnalyzers(analyzers []*analysis.Analyzer) ([]*analysis.Analyzer, *regexp.Regexp, error) {
+	if RunFilter == "" {
+		return analyzers, nil, nil
+	}
+
+	namePat, catPat := RunFilter, ""
+	if i := strings.IndexByte(RunFilter, '/'); i >= 0 {
+		namePat, catPat = RunFilter[:i], RunFilter[i+1:]
+	}
+
+	nameRE, err := regexp.Compile(namePat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid -run pattern: %v", err)
+	}
+	var catRE *regexp.Regexp
+	if catPat != "" {
+		if catRE, err = regexp.Compile(catPat); err != nil {
+			return nil, nil, fmt.Errorf("invalid -run pattern: %v", err)
+		}
+	}
+
+	var selected []*analysis.Analyzer
+	for _, a := range analyzers {
+		if nameRE.MatchString(a.Name) {
+			selected = append(selected, a)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil, fmt.Errorf("-run pattern %q matches none of the analyzers", namePat)
+	}
+	return selected, catRE, nil
+}
+
+// filterCategories discards diagnostics whose category does not match
+// the pattern. An analyzer that sets no category on a diagnostic is
+// treated as if it had used its own name.
+func filterCategories(catRE *regexp.Regexp, roots []*action) {
+	for _, act := range roots {
+		var kept []analysis.Diagnostic
+		for _, d := range act.diagnostics {
+			cat := d.Category
+			if cat == "" {
+				cat = act.a.Name
+			}
+			if catRE.MatchString(cat) {
+				kept = append(kept, d)
+			}
+		}
+		act.diagnostics = kept
+	}
+}
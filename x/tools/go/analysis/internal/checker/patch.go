@@ -0,0 +1,208 @@
+// This file implements the -patch mode: the fixes suggested by
+// analyzers are printed as a unified diff instead of being dropped, so
+// a review bot can post the patch and a human can apply it, in whole
+// or in part, with standard tools.
+
+package checker
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// Patch determines whether the suggested fixes of the root packages'
+// diagnostics are printed to standard output as a unified diff.
+var Patch = false
+
+// contextLines is the number of unchanged lines shown around each hunk.
+const contextLines = 3
+
+// An edit is a replacement of a range of bytes of a file.
+type edit struct {
+	start, end int // byte offsets
+	text       []byte
+}
+
+// printPatch prints the suggested fixes of the root actions as a
+// unified diff, one section per affected file. Where fixes overlap,
+// the one suggested first wins.
+func printPatch(w io.Writer, roots []*action) error {
+	edits := make(map[string][]edit) // filename -> edits
+
+	for _, act := range roots {
+		for _, d := range act.diagnostics {
+			if Suppress && suppressed(act, d) {
+				continue
+			}
+			for _, fix := range d.SuggestedFixes {
+				for _, te := range fix.TextEdits {
+					file := act.pkg.Fset.File(te.Pos)
+					if file == nil {
+						continue
+					}
+					end := te.End
+					if !end.IsValid() {
+						end = te.Pos
+					}
+					name := file.Name()
+					edits[name] = append(edits[name], edit{
+						start: file.Offset(te.Pos),
+						end:   file.Offset(end),
+						text:  te.NewText,
+					})
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(edits))
+	for name := range edits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := filePatch(w, name, content, edits[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filePatch prints the unified diff for one file.
+func filePatch(w io.Writer, name string, content []byte, edits []edit) error {
+	// Sort the edits and discard those that overlap a previous one
+	// or fall outside the file.
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	kept := edits[:0]
+	prev := 0
+	for _, e := range edits {
+		if e.start < prev || e.start > e.end || e.end > len(content) {
+			continue
+		}
+		kept = append(kept, e)
+		prev = e.end
+		if e.end == e.start {
+			prev++ // a second insertion at the same point would conflict
+		}
+	}
+	edits = kept
+	if len(edits) == 0 {
+		return nil
+	}
+
+	// The starting offset of each line.
+	starts := []int{0}
+	for i, b := range content {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	nlines := len(starts)
+	if starts[nlines-1] == len(content) {
+		nlines-- // no partial last line
+	}
+	lineOf := func(off int) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i] > off }) - 1
+	}
+	endOf := func(line int) int {
+		if line+1 < len(starts) {
+			return starts[line+1]
+		}
+		return len(content)
+	}
+
+	// The range of lines affected by each edit.
+	type span struct{ first, last int }
+	spans := make([]span, len(edits))
+	for i, e := range edits {
+		first, last := lineOf(e.start), lineOf(e.end)
+		if last > first && e.end == starts[last] {
+			last-- // the edit stops at the line boundary
+		}
+		if last >= nlines {
+			last = nlines - 1
+		}
+		spans[i] = span{first, last}
+	}
+
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", name, name); err != nil {
+		return err
+	}
+
+	delta := 0 // cumulative change in line count
+	for i := 0; i < len(edits); {
+		// Gather the edits whose contexts touch into one hunk.
+		j := i + 1
+		for j < len(edits) && spans[j].first <= spans[j-1].last+2*contextLines+1 {
+			j++
+		}
+		first, last := spans[i].first, spans[j-1].last
+
+		lo := first - contextLines
+		if lo < 0 {
+			lo = 0
+		}
+		hi := last + contextLines
+		if hi >= nlines {
+			hi = nlines - 1
+		}
+
+		// The affected lines, with the hunk's edits applied.
+		var replaced []byte
+		off := starts[first]
+		for _, e := range edits[i:j] {
+			replaced = append(replaced, content[off:e.start]...)
+			replaced = append(replaced, e.text...)
+			off = e.end
+		}
+		replaced = append(replaced, content[off:endOf(last)]...)
+
+		oldCount := hi - lo + 1
+		newCount := oldCount - (last - first + 1) + countLines(replaced)
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", lo+1, oldCount, lo+1+delta, newCount)
+		writeLines(w, ' ', content[starts[lo]:starts[first]])
+		writeLines(w, '-', content[starts[first]:endOf(last)])
+		writeLines(w, '+', replaced)
+		writeLines(w, ' ', content[endOf(last):endOf(hi)])
+		delta += newCount - oldCount
+
+		i = j
+	}
+	return nil
+}
+
+// countLines returns the number of lines in text, counting a trailing
+// partial line as a line.
+func countLines(text []byte) int {
+	n := 0
+	for i, b := range text {
+		if b == '\n' || i == len(text)-1 {
+			n++
+		}
+	}
+	return n
+}
+
+// writeLines writes each line of text prefixed by prefix. A missing
+// newline on the last line is marked in the conventional way.
+func writeLines(w io.Writer, prefix byte, text []byte) {
+	for len(text) > 0 {
+		i := 0
+		for i < len(text) && text[i] != '\n' {
+			i++
+		}
+		if i == len(text) {
+			fmt.Fprintf(w, "%c%s\n\\ No newline at end of file\n", prefix, text)
+			return
+		}
+		fmt.Fprintf(w, "%c%s\n", prefix, text[:i])
+		text = text[i+1:]
+	}
+}
@@ -0,0 +1,132 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numbered returns n lines "1\n2\n...", with line i replaced by repl[i].
+func numbered(n int, repl map[int]string) string {
+	var buf bytes.Buffer
+	for i := 1; i <= n; i++ {
+		if s, ok := repl[i]; ok {
+			buf.WriteString(s)
+		} else {
+			fmt.Fprintf(&buf, "%d\n", i)
+		}
+	}
+	return buf.String()
+}
+
+// TestFilePatch checks that the printed unified diff, applied with
+// git apply, transforms the file content as the edits describe.
+func TestFilePatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("skipping: git not found: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		edits   []edit
+		want    string // content after applying the patch
+	}{
+		{
+			name:    "replace line",
+			content: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			edits:   []edit{{6, 8, []byte("D\n")}},
+			want:    "a\nb\nc\nD\ne\nf\ng\nh\n",
+		},
+		{
+			name:    "merged hunk",
+			content: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			edits:   []edit{{2, 4, []byte("B\n")}, {10, 12, []byte("F\n")}},
+			want:    "a\nB\nc\nd\ne\nF\ng\nh\n",
+		},
+		{
+			name:    "separate hunks",
+			content: numbered(20, nil),
+			edits:   []edit{{2, 4, []byte("two\n")}, {42, 45, []byte("eighteen\n")}},
+			want:    numbered(20, map[int]string{2: "two\n", 18: "eighteen\n"}),
+		},
+		{
+			name:    "insertion",
+			content: "a\nb\nc\nd\n",
+			edits:   []edit{{4, 4, []byte("X\n")}},
+			want:    "a\nb\nX\nc\nd\n",
+		},
+		{
+			name:    "insertion at EOF",
+			content: "a\nb\n",
+			edits:   []edit{{4, 4, []byte("c\n")}},
+			want:    "a\nb\nc\n",
+		},
+		{
+			name:    "no trailing newline",
+			content: "a\nb\nc",
+			edits:   []edit{{2, 3, []byte("B")}},
+			want:    "a\nB\nc",
+		},
+		{
+			name:    "edit last line without newline",
+			content: "a\nb\nc",
+			edits:   []edit{{4, 5, []byte("C")}},
+			want:    "a\nb\nC",
+		},
+		{
+			name:    "overlapping edits, first wins",
+			content: "a\nb\nc\nd\n",
+			edits:   []edit{{2, 6, []byte("X\n")}, {4, 6, []byte("Y\n")}},
+			want:    "a\nX\nd\n",
+		},
+		{
+			name:    "deletion",
+			content: "a\nb\nc\nd\n",
+			edits:   []edit{{2, 6, nil}},
+			want:    "a\nd\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			edits := append([]edit(nil), test.edits...)
+			if err := filePatch(&buf, "a.go", []byte(test.content), edits); err != nil {
+				t.Fatalf("filePatch: %v", err)
+			}
+
+			// Apply the patch with git and check the result.
+			dir := t.TempDir()
+			file := filepath.Join(dir, "a.go")
+			if err := ioutil.WriteFile(file, []byte(test.content), 0666); err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command("git", "apply", "-p0")
+			cmd.Dir = dir
+			cmd.Stdin = strings.NewReader(buf.String())
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git apply: %v\n%s\npatch:\n%s", err, out, buf.String())
+			}
+			got, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("patched content mismatch:\n got: %q\nwant: %q\npatch:\n%s", got, test.want, buf.String())
+			}
+		})
+	}
+
+	// No edits, no patch.
+	var buf bytes.Buffer
+	if err := filePatch(&buf, "a.go", []byte("a\n"), nil); err != nil {
+		t.Fatalf("filePatch: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("filePatch with no edits printed:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,44 @@
+// This file implements the -platforms mode: the packages are loaded
+// and analyzed once per GOOS/GOARCH configuration, so that problems
+// specific to one platform — such as misaligned 64-bit atomics on
+// 32-bit targets — are not invisible under the host configuration.
+
+package checker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Platforms is a comma-separated list of GOOS/GOARCH pairs under
+// which to load and analyze the packages, such as
+// "linux/amd64,windows/386", or "" to analyze only the host
+// configuration. Diagnostics reported at the same position by the
+// same analyzer on several platforms are printed once.
+var Platforms = ""
+
+type platform struct{ goos, goarch string }
+
+// parsePlatforms parses the value of the -platforms flag.
+func parsePlatforms(s string) ([]platform, error) {
+	var platforms []platform
+	for _, item := range strings.Split(s, ",") {
+		slash := strings.IndexByte(item, '/')
+		if slash < 0 {
+			return nil, fmt.Errorf("invalid platform %q; want GOOS/GOARCH", item)
+		}
+		platforms = append(platforms, platform{item[:slash], item[slash+1:]})
+	}
+	return platforms, nil
+}
+
+// setPlatform directs the subsequent load and analyze calls at the
+// given target: the go command reads GOOS and GOARCH from the
+// environment, as do the corresponding Pass fields. Platforms are
+// analyzed sequentially, so the process-wide environment is safe to
+// update between them.
+func setPlatform(p platform) {
+	os.Setenv("GOOS", p.goos)
+	os.Setenv("GOARCH", p.goarch)
+}
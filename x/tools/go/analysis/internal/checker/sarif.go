@@ -0,0 +1,242 @@
+// This file emits diagnostics in the SARIF 2.1.0 format
+// (Static Analysis Results Interchange Format), which is understood
+// by GitHub code scanning and other security dashboards.
+
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// The subset of the SARIF 2.1.0 object model that we populate.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID        string        `json:"id"`
+	ShortDesc sarifMessage  `json:"shortDescription"`
+	FullDesc  *sarifMessage `json:"fullDescription,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifContent `json:"insertedContent,omitempty"`
+}
+
+type sarifContent struct {
+	Text string `json:"text"`
+}
+
+// printSarifDiagnostics prints the diagnostics for the root packages
+// as a single SARIF run, one rule per analyzer.
+// Like printDiagnostics, it returns the exit code.
+func printSarifDiagnostics(roots []*action) (exitcode int) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name: filepath.Base(os.Args[0]),
+		}},
+		Results: []sarifResult{}, // non-null even if empty
+	}
+
+	// Emit one rule per analyzer, in name order for determinism.
+	rules := make(map[string]*analysis.Analyzer)
+	visited := make(map[*action]bool)
+	var visit func(actions []*action)
+	visit = func(actions []*action) {
+		for _, act := range actions {
+			if !visited[act] {
+				visited[act] = true
+				visit(act.deps)
+				rules[act.a.Name] = act.a
+
+				if act.err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", act.a.Name, act.err)
+					exitcode = 1
+					continue
+				}
+				if !act.isroot {
+					continue
+				}
+				for _, d := range act.diagnostics {
+					if Suppress && suppressed(act, d) {
+						continue
+					}
+					var level string
+					switch severity(act.a, d) {
+					case analysis.SeverityError:
+						level = "error"
+						exitcode = 1
+					case analysis.SeverityWarning:
+						level = "warning"
+					case analysis.SeverityInfo:
+						level = "note"
+					}
+					posn := act.pkg.Fset.Position(d.Pos)
+					region := sarifRegion{
+						StartLine:   posn.Line,
+						StartColumn: posn.Column,
+					}
+					if d.End.IsValid() {
+						end := act.pkg.Fset.Position(d.End)
+						region.EndLine = end.Line
+						region.EndColumn = end.Column
+					}
+					run.Results = append(run.Results, sarifResult{
+						RuleID:  act.a.Name,
+						Level:   level,
+						Message: sarifMessage{Text: d.Message},
+						Locations: []sarifLocation{{
+							PhysicalLocation: sarifPhysicalLocation{
+								ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(posn.Filename)},
+								Region:           region,
+							},
+						}},
+						Fixes: sarifFixes(act.pkg.Fset, d.SuggestedFixes),
+					})
+				}
+			}
+		}
+	}
+	visit(roots)
+
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := rules[name]
+		rule := sarifRule{
+			ID:        name,
+			ShortDesc: sarifMessage{Text: strings.Split(a.Doc, "\n\n")[0]},
+		}
+		if a.Doc != rule.ShortDesc.Text {
+			rule.FullDesc = &sarifMessage{Text: a.Doc}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}, "", "\t")
+	if err != nil {
+		log.Panicf("internal error: SARIF marshalling failed: %v", err)
+	}
+	os.Stdout.Write(data)
+	fmt.Println()
+
+	return exitcode
+}
+
+// sarifFixes converts the suggested fixes of a diagnostic to their
+// SARIF representation, with one artifact change per file edited by
+// each fix. It returns nil if there are no fixes.
+func sarifFixes(fset *token.FileSet, fixes []analysis.SuggestedFix) []sarifFix {
+	var result []sarifFix
+	for _, f := range fixes {
+		fix := sarifFix{Description: sarifMessage{Text: f.Message}}
+		changes := make(map[string]int) // file URI to index in ArtifactChanges
+		for _, edit := range f.TextEdits {
+			start := fset.Position(edit.Pos)
+			end := start
+			if edit.End.IsValid() {
+				end = fset.Position(edit.End)
+			}
+			uri := filepath.ToSlash(start.Filename)
+			c, ok := changes[uri]
+			if !ok {
+				c = len(fix.ArtifactChanges)
+				changes[uri] = c
+				fix.ArtifactChanges = append(fix.ArtifactChanges, sarifArtifactChange{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+				})
+			}
+			repl := sarifReplacement{
+				DeletedRegion: sarifRegion{
+					StartLine:   start.Line,
+					StartColumn: start.Column,
+					EndLine:     end.Line,
+					EndColumn:   end.Column,
+				},
+			}
+			if len(edit.NewText) > 0 {
+				repl.InsertedContent = &sarifContent{Text: string(edit.NewText)}
+			}
+			fix.ArtifactChanges[c].Replacements = append(fix.ArtifactChanges[c].Replacements, repl)
+		}
+		result = append(result, fix)
+	}
+	return result
+}
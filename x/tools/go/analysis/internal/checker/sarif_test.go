@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestSarifFixes(t *testing.T) {
+	fset := token.NewFileSet()
+	f := fset.AddFile("a/a.go", -1, 100)
+	f.SetLinesForContent([]byte("package a\n\nfunc f() {}\n"))
+	pos := func(line, col int) token.Pos {
+		return f.LineStart(line) + token.Pos(col-1)
+	}
+
+	fixes := []analysis.SuggestedFix{{
+		Message: "Replace f with g",
+		TextEdits: []analysis.TextEdit{
+			{Pos: pos(3, 6), End: pos(3, 7), NewText: []byte("g")},
+			{Pos: pos(3, 12)}, // pure insertion of nothing; NoPos end
+		},
+	}}
+
+	got, err := json.Marshal(sarifFixes(fset, fixes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{` +
+		`"description":{"text":"Replace f with g"},` +
+		`"artifactChanges":[{` +
+		`"artifactLocation":{"uri":"a/a.go"},` +
+		`"replacements":[` +
+		`{"deletedRegion":{"startLine":3,"startColumn":6,"endLine":3,"endColumn":7},"insertedContent":{"text":"g"}},` +
+		`{"deletedRegion":{"startLine":3,"startColumn":12,"endLine":3,"endColumn":12}}` +
+		`]}]}]`
+	if string(got) != want {
+		t.Errorf("sarifFixes JSON mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	if fixes := sarifFixes(fset, nil); fixes != nil {
+		t.Errorf("sarifFixes(nil) = %v, want nil", fixes)
+	}
+}
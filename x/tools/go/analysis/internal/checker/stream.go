@@ -0,0 +1,68 @@
+// This file implements the streaming entry point used by the public
+// golang.org/x/tools/go/analysis/checker package: diagnostics are
+// delivered to a callback as each package finishes rather than after
+// the whole analysis completes.
+
+package checker
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// A PackageResult holds the outcome of analyzing one package with one
+// analyzer. Pass is nil if the analysis could not be started, in
+// which case Err explains why.
+type PackageResult struct {
+	Pass        *analysis.Pass
+	Diagnostics []analysis.Diagnostic
+	Artifacts   map[string]interface{} // named artifacts exported by the pass; may be nil
+	Result      interface{}
+	Err         error
+}
+
+// Stream runs the analyzers on the initial packages, calling notify
+// once per package, in completion order, as soon as all of the
+// package's analyzers have finished. Calls to notify are serialized.
+//
+// If the context is cancelled, no further packages are started;
+// Stream returns ctx.Err() once the packages already in flight have
+// completed. The action graph is shared across packages, so
+// prerequisite analyses and facts are computed only once.
+func Stream(ctx context.Context, pkgs []*packages.Package, analyzers []*analysis.Analyzer, notify func(pkg *packages.Package, results []*PackageResult)) error {
+	roots := actionGraph(pkgs, analyzers)
+
+	byPkg := make(map[*packages.Package][]*action)
+	for _, act := range roots {
+		byPkg[act.pkg] = append(byPkg[act.pkg], act)
+	}
+
+	var (
+		mu sync.Mutex // serializes calls to notify
+		wg sync.WaitGroup
+	)
+	for _, pkg := range pkgs {
+		if ctx.Err() != nil {
+			break
+		}
+		acts := byPkg[pkg]
+		wg.Add(1)
+		go func(pkg *packages.Package, acts []*action) {
+			defer wg.Done()
+			execAll(acts)
+
+			results := make([]*PackageResult, len(acts))
+			for i, act := range acts {
+				results[i] = &PackageResult{act.pass, act.diagnostics, act.artifacts, act.result, act.err}
+			}
+			mu.Lock()
+			notify(pkg, results)
+			mu.Unlock()
+		}(pkg, acts)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
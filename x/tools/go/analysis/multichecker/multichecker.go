@@ -8,22 +8,24 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysisdoc"
 	"golang.org/x/tools/go/analysis/internal/checker"
 )
 
 // TODO(adonovan): support tri-state enable flags so -printf.enable=true means
 // "run only printf" and -printf.enable=false means "run all but printf"
 
-// TODO(adonovan): document (and verify) the exit codes:
-// "Vet's exit code is 2 for erroneous invocation of the tool, 1 if a
-// problem was reported, and 0 otherwise. Note that the tool does not
-// check every possible problem and depends on unreliable heuristics
-// so it should be used as guidance only, not as a firm indicator of
-// program correctness."
+// The exit code follows the vet convention: 2 for erroneous
+// invocation of the tool, 1 if a problem of error severity was
+// reported, and 0 otherwise. Note that the tool does not check every
+// possible problem and depends on unreliable heuristics so it should
+// be used as guidance only, not as a firm indicator of program
+// correctness.
 
 const usage = `Analyze is a tool for static analysis of Go programs.
 
@@ -84,9 +86,19 @@ func Main(analyzers ...*analysis.Analyzer) {
 		os.Exit(0)
 	}
 
-	if err := checker.Run(args, analyzers); err != nil {
-		log.Fatal(err)
+	if args[0] == "doc" {
+		doc := &analysisdoc.Doc{Title: filepath.Base(os.Args[0]), Analyzers: analyzers}
+		emit := doc.Markdown
+		if len(args) > 1 && args[1] == "html" {
+			emit = doc.HTML
+		}
+		if err := emit(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
 	}
+
+	os.Exit(checker.Run(args, analyzers))
 }
 
 func help(analyzers []*analysis.Analyzer, args []string) {
@@ -104,6 +116,7 @@ func help(analyzers []*analysis.Analyzer, args []string) {
 		}
 		fmt.Println("\nBy default all analyzers are run.")
 		fmt.Println("To select specific analyzers, use the -NAME.enable flag for each one.")
+		fmt.Println("To generate a rules reference, run 'analyze doc' (Markdown) or 'analyze doc html'.")
 
 		// Show only the core command-line flags.
 		fmt.Println("\nCore flags:")
@@ -0,0 +1,21 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomicalign_test
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/atomicalign"
+)
+
+func Test(t *testing.T) {
+	if runtime.GOARCH != "386" && runtime.GOARCH != "arm" {
+		t.Skip("this test requires a 32-bit platform")
+	}
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, atomicalign.Analyzer, "a")
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the atomic alignment checker.
+// The results assume a 32-bit platform.
+
+package a
+
+import (
+	"sync/atomic"
+)
+
+func ok() {
+	// A variable of 64-bit type is always allocated 64-bit aligned.
+	var x int64
+	atomic.AddInt64(&x, 1)
+
+	var s struct {
+		a int64
+		b uint64
+	}
+	atomic.LoadInt64(&s.a)
+	atomic.LoadUint64(&s.b)
+}
+
+func misaligned() {
+	var s struct {
+		a bool
+		b int32
+		c int64 // offset 8: aligned
+		d uint32
+		e uint64 // offset 20: misaligned
+	}
+	atomic.StoreInt64(&s.c, 0)
+	atomic.StoreUint64(&s.e, 0) // want "address of non 64-bit aligned field .e passed to atomic.StoreUint64"
+
+	var t struct {
+		a int32
+		b int64 // offset 4: misaligned
+	}
+	atomic.AddInt64(&t.b, 1) // want "address of non 64-bit aligned field .b passed to atomic.AddInt64"
+}
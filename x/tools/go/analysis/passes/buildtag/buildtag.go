@@ -9,6 +9,8 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/build/constraint"
+	"go/token"
 	"strings"
 	"unicode"
 
@@ -18,7 +20,7 @@ import (
 
 var Analyzer = &analysis.Analyzer{
 	Name: "buildtag",
-	Doc:  "check that +build tags are well-formed and correctly located",
+	Doc:  "check that //go:build and +build tags are well-formed and correctly located",
 	Run:  runBuildTag,
 }
 
@@ -35,6 +37,12 @@ func runBuildTag(pass *analysis.Pass) (interface{}, error) {
 }
 
 func checkGoFile(pass *analysis.Pass, f *ast.File) {
+	var (
+		goBuild    constraint.Expr // first //go:build expression, if any
+		crossCheck = true          // ok to compare the two forms when done
+		plusBuild  []*ast.Comment  // all +build comment lines
+	)
+
 	pastCutoff := false
 	for _, group := range f.Comments {
 		// A +build comment is ignored after or adjoining the package declaration.
@@ -50,14 +58,109 @@ func checkGoFile(pass *analysis.Pass, f *ast.File) {
 
 		// Check each line of a //-comment.
 		for _, c := range group.List {
-			if !strings.Contains(c.Text, "+build") {
+			if strings.Contains(c.Text, "+build") {
+				if err := checkLine(c.Text, pastCutoff); err != nil {
+					pass.Reportf(c.Pos(), "%s", err)
+					crossCheck = false
+					continue
+				}
+				if constraint.IsPlusBuild(c.Text) {
+					plusBuild = append(plusBuild, c)
+				}
 				continue
 			}
-			if err := checkLine(c.Text, pastCutoff); err != nil {
-				pass.Reportf(c.Pos(), "%s", err)
+			if constraint.IsGoBuild(c.Text) {
+				// A //go:build comment is ignored after the package
+				// declaration (but, unlike a +build comment,
+				// adjoining it is OK).
+				if group.Pos() >= f.Package {
+					pass.Reportf(c.Pos(), "misplaced //go:build comment")
+					crossCheck = false
+					continue
+				}
+				if goBuild != nil {
+					pass.Reportf(c.Pos(), "unexpected extra //go:build line")
+					crossCheck = false
+					continue
+				}
+				x, err := constraint.Parse(c.Text)
+				if err != nil {
+					pass.Reportf(c.Pos(), "%v", err)
+					crossCheck = false
+					continue
+				}
+				goBuild = x
+			} else if inner := strings.TrimSpace(strings.TrimPrefix(c.Text, "//")); strings.HasPrefix(inner, "go:build") && constraint.IsGoBuild("//"+inner) {
+				pass.Reportf(c.Pos(), "malformed //go:build line (space between // and go:build)")
 			}
 		}
 	}
+
+	// If the file has both forms, they must agree.
+	if goBuild != nil && len(plusBuild) > 0 && crossCheck {
+		checkMatch(pass, goBuild, plusBuild)
+	}
+}
+
+// checkMatch checks that the +build comment lines of a file express the
+// same constraint as its //go:build expression, and if not, reports the
+// mismatch with a fix that rewrites them in terms of the latter.
+func checkMatch(pass *analysis.Pass, goBuild constraint.Expr, plusBuild []*ast.Comment) {
+	lines, err := constraint.PlusBuildLines(goBuild)
+	if err != nil {
+		// Expression is too complex to be expressed in +build form.
+		pass.Reportf(plusBuild[0].Pos(), "%v", err)
+		return
+	}
+
+	// Compare the two as constraint expressions, so that
+	// formatting differences alone do not cause a report.
+	var want, have constraint.Expr
+	for _, line := range lines {
+		y, err := constraint.Parse(line)
+		if err != nil {
+			return // cannot happen: PlusBuildLines output is well formed
+		}
+		want = andExpr(want, y)
+	}
+	for _, c := range plusBuild {
+		y, err := constraint.Parse(stripDiagComment(c.Text))
+		if err != nil {
+			return // cannot happen: checkLine accepted the line
+		}
+		have = andExpr(have, y)
+	}
+	if want.String() == have.String() {
+		return
+	}
+
+	d := analysis.Diagnostic{
+		Pos:     plusBuild[0].Pos(),
+		Message: "+build lines do not match //go:build condition",
+	}
+	if len(plusBuild) == len(lines) {
+		var edits []analysis.TextEdit
+		for i, c := range plusBuild {
+			text := stripDiagComment(c.Text)
+			edits = append(edits, analysis.TextEdit{
+				Pos:     c.Pos(),
+				End:     c.Pos() + token.Pos(len(text)),
+				NewText: []byte(lines[i]),
+			})
+		}
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Rewrite +build lines to match //go:build condition",
+			TextEdits: edits,
+		}}
+	}
+	pass.Report(d)
+}
+
+func andExpr(x, y constraint.Expr) constraint.Expr {
+	if x == nil {
+		return y
+	}
+	return &constraint.AndExpr{X: x, Y: y}
 }
 
 func checkOtherFile(pass *analysis.Pass, filename string) error {
@@ -103,8 +206,19 @@ func checkOtherFile(pass *analysis.Pass, filename string) error {
 	return nil
 }
 
+// stripDiagComment removes a secondary "// want" comment from a build
+// comment line. This testing hack lets the test data for this analyzer
+// carry expectations on the very lines being checked.
+func stripDiagComment(line string) string {
+	if i := strings.Index(line, " // want "); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
 // checkLine checks a line that starts with "//" and contains "+build".
 func checkLine(line string, pastCutoff bool) error {
+	line = stripDiagComment(line)
 	line = strings.TrimPrefix(line, "//")
 	line = strings.TrimSpace(line)
 
@@ -0,0 +1,11 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file tests that the checker reports build comment
+// lines that disagree with the //go:build line.
+
+//go:build !ignore2
+// +build ignore2 // want `\+build lines do not match //go:build condition`
+
+package a
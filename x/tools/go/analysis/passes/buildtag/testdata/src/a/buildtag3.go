@@ -0,0 +1,11 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file tests misplaced and malformed //go:build lines.
+
+// go:build never // want `malformed //go:build line \(space between // and go:build\)`
+
+package a
+
+//go:build toolate2 // want "misplaced //go:build comment"
@@ -12,12 +12,14 @@ import (
 	"go/token"
 	"go/types"
 	"log"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 const Doc = `detect some violations of the cgo pointer passing rules
@@ -27,19 +29,153 @@ This looks for code that uses cgo to call C code passing values
 whose types are almost always invalid according to the cgo pointer
 sharing rules.
 Specifically, it warns about attempts to pass a Go chan, map, func,
-or slice to C, either directly, or via a pointer, array, or struct.`
+or slice to C, either directly, or via a pointer, array, or struct.
+It also warns about assignments that store a Go pointer into C memory,
+that is, into memory obtained from C.malloc or into a field of a C
+struct or union reached through a pointer. In functions exported to C
+with an //export directive, returning a Go pointer, or storing one
+through a pointer parameter received from C, is reported as well.
+Values of type-parameter type are checked against every type in the
+parameter's type set. A Go func value flowing into a C call, directly
+or through a local variable, is reported with a suggestion to pass a
+cgo.Handle instead.
+
+The -safe and -unsafe flags adjust the set of functions checked. Each
+names C functions (C.CBytes is safe by default) or package-qualified Go
+functions. Calls to safe functions are not checked; Go functions
+declared unsafe have their arguments checked like cgo calls. The
+declarations are recorded as facts, so they need not be repeated when
+dependent packages are analyzed.`
 
 var Analyzer = &analysis.Analyzer{
 	Name:             "cgocall",
 	Doc:              Doc,
 	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes:        []analysis.Fact{new(cgoSafe), new(cgoUnsafe)},
 	RunDespiteErrors: true,
 	Run:              run,
 }
 
+// A cgoSafe fact records that calls to a function need not be checked,
+// even if the function is declared unsafe elsewhere.
+type cgoSafe struct{}
+
+func (*cgoSafe) AFact()         {}
+func (*cgoSafe) String() string { return "cgoSafe" }
+
+// A cgoUnsafe fact records that a function passes its arguments to C,
+// so calls to it are checked like cgo calls.
+type cgoUnsafe struct{}
+
+func (*cgoUnsafe) AFact()         {}
+func (*cgoUnsafe) String() string { return "cgoUnsafe" }
+
+// flags
+var safeFuncs, unsafeFuncs stringSetFlag
+
+func init() {
+	safeFuncs.Set("CBytes")
+	Analyzer.Flags.Var(&safeFuncs, "safe",
+		"comma-separated list of C functions or package-qualified Go functions whose calls are known to be safe")
+
+	unsafeFuncs.Set("")
+	Analyzer.Flags.Var(&unsafeFuncs, "unsafe",
+		"comma-separated list of C functions or package-qualified Go functions that pass their arguments to C")
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
+	// Export facts for the functions of this package that the flags
+	// declare safe or unsafe, making the declarations available when
+	// dependent packages are analyzed without the flags.
+	if len(safeFuncs) > 0 || len(unsafeFuncs) > 0 {
+		for _, file := range pass.Files {
+			for _, decl := range file.Decls {
+				decl, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+				if !ok || fn.Pkg() == nil {
+					continue
+				}
+				name := fn.Pkg().Path() + "." + fn.Name()
+				if safeFuncs[name] {
+					pass.ExportObjectFact(fn, new(cgoSafe))
+				}
+				if unsafeFuncs[name] {
+					pass.ExportObjectFact(fn, new(cgoUnsafe))
+				}
+			}
+		}
+	}
+
+	// Functions exported to C with a //export directive are called
+	// through a generated _cgoexp wrapper. C code must not receive a
+	// Go pointer from them, whether returned or written through an
+	// out-parameter.
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || !strings.HasPrefix(decl.Name.Name, "_cgoexp") || decl.Body == nil {
+				continue
+			}
+			if exp := exportedFunc(pass, decl); exp != nil {
+				checkExported(pass, exp)
+			}
+		}
+	}
+
+	// Find the locals through which a Go func value may flow to C: a
+	// variable assigned the address of a func value, behind any
+	// conversions, or a copy of such a variable. Iterate to a fixed
+	// point to handle chains of copies.
+	funcCarriers := make(map[types.Object]bool)
+	record := func(lhs ast.Expr, rhs ast.Expr) bool {
+		id, ok := lhs.(*ast.Ident)
+		if !ok {
+			return false
+		}
+		obj := pass.TypesInfo.Defs[id]
+		if obj == nil {
+			obj = pass.TypesInfo.Uses[id]
+		}
+		if obj == nil || funcCarriers[obj] || !carriesFuncValue(pass.TypesInfo, rhs, funcCarriers) {
+			return false
+		}
+		funcCarriers[obj] = true
+		return true
+	}
+	for {
+		again := false
+		inspect.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) != len(n.Rhs) {
+					return
+				}
+				for i := range n.Lhs {
+					if record(n.Lhs[i], n.Rhs[i]) {
+						again = true
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) != len(n.Values) {
+					return
+				}
+				for i := range n.Names {
+					if record(n.Names[i], n.Values[i]) {
+						again = true
+					}
+				}
+			}
+		})
+		if !again {
+			break
+		}
+	}
+
 	nodeFilter := []ast.Node{
 		(*ast.CallExpr)(nil),
 	}
@@ -49,11 +185,17 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 		call, name := findCall(pass.Fset, stack)
 		if call == nil {
-			return true // not a call we need to check
-		}
-
-		// A call to C.CBytes passes a pointer but is always safe.
-		if name == "CBytes" {
+			// Not a cgo call. Check for a call to a Go function
+			// declared, by flag or fact, to pass its arguments to C.
+			call = n.(*ast.CallExpr)
+			fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+			if fn == nil || !declared(pass, fn, unsafeFuncs, new(cgoUnsafe)) ||
+				declared(pass, fn, safeFuncs, new(cgoSafe)) {
+				return true // not a call we need to check
+			}
+			name = fn.Name()
+		} else if safeFuncs[name] && !unsafeFuncs[name] {
+			// A call such as C.CBytes passes a pointer but is safe.
 			return true
 		}
 
@@ -63,8 +205,18 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		for _, arg := range call.Args {
-			if !typeOKForCgoCall(cgoBaseType(pass.TypesInfo, arg), make(map[types.Type]bool)) {
-				pass.Reportf(arg.Pos(), "possibly passing Go type with embedded pointer to C")
+			if t := cgoBaseType(pass.TypesInfo, arg); !typeOKForCgoCall(t, make(map[types.Type]bool)) {
+				reportBadArg(pass, arg, t)
+				break
+			}
+
+			// A Go func value may also reach C through a local
+			// assigned from one, as in:
+			//	p := unsafe.Pointer(&callback)
+			//	C.register(p)
+			if id, ok := unwrapConversions(pass.TypesInfo, arg).(*ast.Ident); ok &&
+				funcCarriers[pass.TypesInfo.Uses[id]] {
+				pass.ReportRangef(arg, "possibly passing Go func value as C callback; pass a cgo.Handle instead")
 				break
 			}
 
@@ -74,17 +226,321 @@ func run(pass *analysis.Pass) (interface{}, error) {
 				arg = conv.Args[0]
 			}
 			if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
-				if !typeOKForCgoCall(cgoBaseType(pass.TypesInfo, u.X), make(map[types.Type]bool)) {
-					pass.Reportf(arg.Pos(), "possibly passing Go type with embedded pointer to C")
+				if t := cgoBaseType(pass.TypesInfo, u.X); !typeOKForCgoCall(t, make(map[types.Type]bool)) {
+					reportBadArg(pass, arg, t)
 					break
 				}
 			}
 		}
 		return true
 	})
+
+	inspect.Preorder([]ast.Node{(*ast.AssignStmt)(nil)}, func(n ast.Node) {
+		checkAssign(pass, n.(*ast.AssignStmt))
+	})
 	return nil, nil
 }
 
+// exportedFunc returns the declaration of the Go function that the
+// cgo-generated wrapper w calls, that is, the function that the user
+// named in a //export directive, or nil if it is not declared in this
+// package.
+func exportedFunc(pass *analysis.Pass, w *ast.FuncDecl) *ast.FuncDecl {
+	var obj *types.Func
+	ast.Inspect(w.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			obj, _ = typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		}
+		return obj == nil
+	})
+	if obj == nil {
+		return nil
+	}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && pass.TypesInfo.Defs[decl.Name] == obj {
+				return decl
+			}
+		}
+	}
+	return nil
+}
+
+// checkExported reports ways in which the exported function decl may
+// hand a Go pointer to its C caller: returning one, or storing one
+// through a pointer parameter received from C. Stores through C-typed
+// pointers are reported by checkAssign for all functions; here we also
+// treat the function's own unsafe.Pointer and C pointer parameters as
+// addressing C memory.
+func checkExported(pass *analysis.Pass, decl *ast.FuncDecl) {
+	info := pass.TypesInfo
+
+	// The parameters through which C hands the function an address.
+	params := make(map[types.Object]bool)
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			obj := info.Defs[name]
+			if obj == nil {
+				continue
+			}
+			switch t := obj.Type().Underlying().(type) {
+			case *types.Basic:
+				if t.Kind() == types.UnsafePointer {
+					params[obj] = true
+				}
+			case *types.Pointer:
+				if isCType(t.Elem()) {
+					params[obj] = true
+				}
+			}
+		}
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ReturnStmt:
+			for _, res := range n.Results {
+				if mayBeGoPointer(info, res) {
+					pass.ReportRangef(res, "possibly returning Go pointer from function exported to C")
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+			for i, lhs := range n.Lhs {
+				star, ok := lhs.(*ast.StarExpr)
+				if !ok || isCMemory(info, lhs) {
+					continue // not a store, or already reported by checkAssign
+				}
+				if isCParam(info, star.X, params) && mayBeGoPointer(info, n.Rhs[i]) {
+					pass.ReportRangef(n.Rhs[i], "possibly storing Go pointer in C memory")
+				}
+			}
+		}
+		return true
+	})
+}
+
+// isCParam reports whether e, possibly wrapped in conversions, names
+// one of the parameters in params.
+func isCParam(info *types.Info, e ast.Expr, params map[types.Object]bool) bool {
+	id, ok := unwrapConversions(info, e).(*ast.Ident)
+	return ok && params[info.Uses[id]]
+}
+
+// checkAssign reports assignments that may store a Go pointer into
+// C memory. The cgo rules forbid that however the memory was obtained,
+// but without tracking allocations we recognize only the clear cases:
+// the left-hand side dereferences a result of C.malloc, or names a
+// field of a C struct or union reached through a pointer.
+func checkAssign(pass *analysis.Pass, as *ast.AssignStmt) {
+	if len(as.Lhs) != len(as.Rhs) {
+		return // multi-valued call; its results carry no address
+	}
+	for i, lhs := range as.Lhs {
+		if !isCMemory(pass.TypesInfo, lhs) {
+			continue
+		}
+		if mayBeGoPointer(pass.TypesInfo, as.Rhs[i]) {
+			pass.ReportRangef(as.Rhs[i], "possibly storing Go pointer in C memory")
+		}
+	}
+}
+
+// isCMemory reports whether the expression lhs addresses memory that C
+// code may access: memory obtained from C.malloc, or a field of a C
+// struct or union reached through a pointer. Memory reached through a
+// C-typed pointer held in a Go variable may in fact be Go memory, so
+// the answer, like the rest of this checker, is a "possibly".
+func isCMemory(info *types.Info, lhs ast.Expr) bool {
+	switch lhs := lhs.(type) {
+	case *ast.SelectorExpr:
+		// p.f where p has type *C.struct_s.
+		t := info.Types[lhs.X].Type
+		if t == nil {
+			return false
+		}
+		ptr, ok := t.Underlying().(*types.Pointer)
+		return ok && isCStructType(ptr.Elem())
+	case *ast.StarExpr:
+		// *p where p has a C pointer type, or derives from C.malloc,
+		// as in *(*unsafe.Pointer)(C.malloc(8)).
+		t := info.Types[lhs.X].Type
+		if t == nil {
+			return false
+		}
+		if ptr, ok := t.Underlying().(*types.Pointer); ok && isCType(ptr.Elem()) {
+			return true
+		}
+		return isCMalloc(info, lhs.X)
+	}
+	return false
+}
+
+// isCMalloc reports whether e is a call to C.malloc, possibly wrapped
+// in conversions. The cgo tool rewrites C.malloc to a call of the
+// generated function _Cfunc__CMalloc.
+func isCMalloc(info *types.Info, e ast.Expr) bool {
+	for {
+		e = analysisutil.Unparen(e)
+		conv, ok := e.(*ast.CallExpr)
+		if !ok || len(conv.Args) != 1 {
+			break
+		}
+		if !info.Types[conv.Fun].IsType() && !isUnsafePointer(info, conv.Fun) {
+			break
+		}
+		e = conv.Args[0]
+	}
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := analysisutil.Unparen(call.Fun).(*ast.Ident)
+	return ok && id.Name == "_Cfunc__CMalloc"
+}
+
+// reportBadArg reports an argument of type t that may not be passed to
+// C, with advice specific to func values, for which the usual fix is
+// to pass a handle rather than the value itself.
+func reportBadArg(pass *analysis.Pass, arg ast.Expr, t types.Type) {
+	if isFuncValue(t) {
+		pass.ReportRangef(arg, "possibly passing Go func value as C callback; pass a cgo.Handle instead")
+	} else {
+		pass.ReportRangef(arg, "possibly passing Go type with embedded pointer to C")
+	}
+}
+
+// isFuncValue reports whether t is a func type or a pointer to one.
+func isFuncValue(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	_, ok := t.Underlying().(*types.Signature)
+	return ok
+}
+
+// carriesFuncValue reports whether e evaluates to a Go func value, the
+// address of one, or a copy of a variable recorded in carriers, behind
+// any conversions.
+func carriesFuncValue(info *types.Info, e ast.Expr, carriers map[types.Object]bool) bool {
+	switch e := unwrapConversions(info, e).(type) {
+	case *ast.UnaryExpr:
+		return e.Op == token.AND && isFuncValue(info.Types[e.X].Type)
+	case *ast.Ident:
+		return carriers[info.Uses[e]] || isFuncValue(info.Types[e].Type)
+	}
+	return false
+}
+
+// unwrapConversions removes any enclosing parentheses and type
+// conversions from e, as in (*C.char)(unsafe.Pointer(&b[0])).
+func unwrapConversions(info *types.Info, e ast.Expr) ast.Expr {
+	for {
+		e = analysisutil.Unparen(e)
+		conv, ok := e.(*ast.CallExpr)
+		if !ok || len(conv.Args) != 1 || !info.Types[conv.Fun].IsType() {
+			return e
+		}
+		e = conv.Args[0]
+	}
+}
+
+// mayBeGoPointer reports whether the value of e may be a pointer to Go
+// memory. Values of C pointer types are assumed to point to C memory
+// unless the address operation is visible in the expression.
+func mayBeGoPointer(info *types.Info, e ast.Expr) bool {
+	e = unwrapConversions(info, e)
+	if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+		return true // address of a Go variable
+	}
+	return hasGoPointer(info.Types[e].Type, make(map[types.Type]bool))
+}
+
+// hasGoPointer reports whether a value of type t contains a pointer to
+// Go memory. C types never do: C struct fields have C types, and a
+// value of C pointer type is assumed to point to C memory. m is used
+// to avoid infinite recursion on recursive types.
+func hasGoPointer(t types.Type, m map[types.Type]bool) bool {
+	if t == nil || m[t] {
+		return false
+	}
+	m[t] = true
+	if isCType(t) {
+		return false
+	}
+	switch t := t.Underlying().(type) {
+	case *types.Chan, *types.Map, *types.Signature, *types.Slice:
+		return true
+	case *types.Pointer:
+		return !isCType(t.Elem())
+	case *types.Array:
+		return hasGoPointer(t.Elem(), m)
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if hasGoPointer(t.Field(i).Type(), m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCType reports whether t is a type generated by cgo for a C type.
+func isCType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && strings.HasPrefix(named.Obj().Name(), "_Ctype_")
+}
+
+// isCStructType reports whether t is a C struct or union type, or a C
+// typedef for one.
+func isCStructType(t types.Type) bool {
+	if !isCType(t) {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// declared reports whether fn is named by the given flag, as
+// path.Name, or carries the given fact, exported when fn's own
+// package was analyzed.
+func declared(pass *analysis.Pass, fn *types.Func, flag stringSetFlag, fact analysis.Fact) bool {
+	if fn.Pkg() != nil && flag[fn.Pkg().Path()+"."+fn.Name()] {
+		return true
+	}
+	return pass.ImportObjectFact(fn, fact)
+}
+
+type stringSetFlag map[string]bool
+
+func (ss *stringSetFlag) String() string {
+	var items []string
+	for item := range *ss {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (ss *stringSetFlag) Set(s string) error {
+	m := make(map[string]bool) // clobber previous value
+	if s != "" {
+		for _, name := range strings.Split(s, ",") {
+			if name == "" {
+				continue
+			}
+			m[name] = true
+		}
+	}
+	*ss = m
+	return nil
+}
+
 // findCall returns the CallExpr that we need to check, which may not be
 // the same as the one we're currently visiting, due to code generation.
 // It also returns the name of the function, such as "f" for C.f(...).
@@ -203,6 +659,11 @@ func typeOKForCgoCall(t types.Type, m map[types.Type]bool) bool {
 		return true
 	}
 	m[t] = true
+	if tp, ok := t.(*types.TypeParam); ok {
+		// A type parameter stands for each type in its type set;
+		// the call is possibly unsafe if any of them is.
+		return termsOKForCgoCall(tp.Constraint(), m)
+	}
 	switch t := t.Underlying().(type) {
 	case *types.Chan, *types.Map, *types.Signature, *types.Slice:
 		return false
@@ -220,6 +681,32 @@ func typeOKForCgoCall(t types.Type, m map[types.Type]bool) bool {
 	return true
 }
 
+// termsOKForCgoCall reports whether every type term of the constraint
+// type t is OK to pass to a C function. An unbounded type set, such as
+// that of any, is accepted, as interface values are today.
+func termsOKForCgoCall(t types.Type, m map[types.Type]bool) bool {
+	switch t := t.(type) {
+	case *types.Union:
+		for i := 0; i < t.Len(); i++ {
+			if !typeOKForCgoCall(t.Term(i).Type(), m) {
+				return false
+			}
+		}
+	default:
+		if iface, ok := t.Underlying().(*types.Interface); ok {
+			// An embedded constraint contributes its own terms.
+			for i := 0; i < iface.NumEmbeddeds(); i++ {
+				if !termsOKForCgoCall(iface.EmbeddedType(i), m) {
+					return false
+				}
+			}
+		} else if !typeOKForCgoCall(t, m) { // a single-type term
+			return false
+		}
+	}
+	return true
+}
+
 func isUnsafePointer(info *types.Info, e ast.Expr) bool {
 	t := info.Types[e].Type
 	return t != nil && t.Underlying() == types.Typ[types.UnsafePointer]
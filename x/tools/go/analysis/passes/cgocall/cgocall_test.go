@@ -15,3 +15,20 @@ func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, cgocall.Analyzer, "a", "b")
 }
+
+func TestFlags(t *testing.T) {
+	for flag, value := range map[string]string{
+		"safe":   "CBytes,c.Audited",
+		"unsafe": "c.PassToC,c.Audited",
+	} {
+		if err := cgocall.Analyzer.Flags.Set(flag, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer func() {
+		cgocall.Analyzer.Flags.Set("safe", "CBytes")
+		cgocall.Analyzer.Flags.Set("unsafe", "")
+	}()
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, cgocall.Analyzer, "c", "d")
+}
@@ -21,8 +21,8 @@ func CgoTests() {
 	C.f(unsafe.Pointer(&m))                     // want "embedded pointer"
 
 	var f func()
-	C.f(*(*unsafe.Pointer)(unsafe.Pointer(&f))) // want "embedded pointer"
-	C.f(unsafe.Pointer(&f))                     // want "embedded pointer"
+	C.f(*(*unsafe.Pointer)(unsafe.Pointer(&f))) // want "func value as C callback"
+	C.f(unsafe.Pointer(&f))                     // want "func value as C callback"
 
 	var s []int
 	C.f(*(*unsafe.Pointer)(unsafe.Pointer(&s))) // want "embedded pointer"
@@ -0,0 +1,36 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the cgo checker's detection of Go
+// pointers stored in C memory.
+
+package a
+
+// #include <stdlib.h>
+// struct s { void *f; int *g; int n; };
+import "C"
+
+import "unsafe"
+
+func CgoStoreTests() {
+	var x int
+
+	p := (*C.struct_s)(C.malloc(C.sizeof_struct_s))
+	p.f = unsafe.Pointer(&x)           // want "storing Go pointer in C memory"
+	p.g = (*C.int)(unsafe.Pointer(&x)) // want "storing Go pointer in C memory"
+	p.n = 1                            // ok: not a pointer
+
+	*(*unsafe.Pointer)(C.malloc(8)) = unsafe.Pointer(&x) // want "storing Go pointer in C memory"
+
+	// The following cases are OK.
+	var q *C.int
+	p.g = q // a C pointer value is assumed to point to C memory
+
+	var s C.struct_s
+	s.f = unsafe.Pointer(&x) // s is a Go variable
+
+	p.f = C.malloc(4) // C memory
+
+	C.free(unsafe.Pointer(p))
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the cgo checker's handling of Go
+// functions exported to C.
+
+package a
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+//export GoCallback
+func GoCallback() *int {
+	var x int
+	return &x // want "returning Go pointer from function exported to C"
+}
+
+//export GoFill
+func GoFill(out unsafe.Pointer) {
+	var x int
+	*(*unsafe.Pointer)(out) = unsafe.Pointer(&x) // want "storing Go pointer in C memory"
+}
+
+//export GoName
+func GoName() *C.char {
+	return C.CString("ok") // ok: a C pointer value
+}
+
+func notExported(out unsafe.Pointer) {
+	var x int
+	*(*unsafe.Pointer)(out) = unsafe.Pointer(&x) // ok: not exported to C
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the cgo checker's handling of type
+// parameters.
+
+package a
+
+// void h(void *ptr) {}
+import "C"
+
+import "unsafe"
+
+type ints interface{ ~int | ~int64 }
+type intsOrSlices interface{ ~[]int | ~int }
+type embedsSlices interface{ intsOrSlices }
+
+func generic1[T ints](v T) {
+	C.h(unsafe.Pointer(&v)) // ok: every type in the set is an integer
+}
+
+func generic2[T intsOrSlices](v T) {
+	C.h(unsafe.Pointer(&v)) // want "embedded pointer"
+}
+
+func generic3[T embedsSlices](v T) {
+	C.h(unsafe.Pointer(&v)) // want "embedded pointer"
+}
+
+func generic4[T any](v T) {
+	C.h(unsafe.Pointer(&v)) // ok: unbounded type sets are accepted
+}
+
+func generic5[T intsOrSlices](v struct{ f T }) {
+	C.h(unsafe.Pointer(&v)) // want "embedded pointer"
+}
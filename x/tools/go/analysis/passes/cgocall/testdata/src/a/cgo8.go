@@ -0,0 +1,32 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the cgo checker's detection of Go func
+// values used as C callbacks.
+
+package a
+
+// void registerCallback(void *fn) {}
+import "C"
+
+import "unsafe"
+
+func callbacks() {
+	f := func() {}
+	C.registerCallback(unsafe.Pointer(&f)) // want "func value as C callback"
+
+	p := unsafe.Pointer(&f)
+	C.registerCallback(p) // want "func value as C callback"
+
+	q := p
+	C.registerCallback(q) // want "func value as C callback"
+
+	var fp *func()
+	C.registerCallback(unsafe.Pointer(fp)) // want "func value as C callback"
+
+	// The following cases are OK.
+	var i int
+	r := unsafe.Pointer(&i)
+	C.registerCallback(r)
+}
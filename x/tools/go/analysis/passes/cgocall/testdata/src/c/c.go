@@ -0,0 +1,20 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package c is a cgo shim whose PassToC function is declared, via the
+// unsafe flag, to pass its arguments to C.
+
+package c
+
+import "unsafe"
+
+func PassToC(p unsafe.Pointer) {} // want PassToC:"cgoUnsafe"
+
+func Audited(p unsafe.Pointer) {} // want Audited:"cgoSafe" Audited:"cgoUnsafe"
+
+func f() {
+	var s []int
+	PassToC(unsafe.Pointer(&s)) // want "embedded pointer"
+	Audited(unsafe.Pointer(&s)) // ok: declared safe
+}
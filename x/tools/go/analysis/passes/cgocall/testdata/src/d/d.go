@@ -0,0 +1,22 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package d uses the cgo shim package c; the facts exported for c
+// extend the check to d's calls.
+
+package d
+
+import (
+	"c"
+	"unsafe"
+)
+
+func g() {
+	var m map[string]string
+	c.PassToC(unsafe.Pointer(&m)) // want "embedded pointer"
+	c.Audited(unsafe.Pointer(&m)) // ok: declared safe
+
+	var i int
+	c.PassToC(unsafe.Pointer(&i)) // ok: no embedded pointer
+}
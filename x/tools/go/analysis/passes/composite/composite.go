@@ -66,7 +66,8 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			}
 			under = ptr.Elem().Underlying()
 		}
-		if _, ok := under.(*types.Struct); !ok {
+		strct, ok := under.(*types.Struct)
+		if !ok {
 			// skip non-struct composite literals
 			return
 		}
@@ -75,12 +76,29 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return
 		}
 
-		// check if the CompositeLit contains an unkeyed field
+		// check if the CompositeLit contains an unkeyed field,
+		// gathering the edits needed to key each one
 		allKeyValue := true
-		for _, e := range cl.Elts {
+		suggestedFixAvailable := len(cl.Elts) == strct.NumFields()
+		var missingKeys []analysis.TextEdit
+		for i, e := range cl.Elts {
 			if _, ok := e.(*ast.KeyValueExpr); !ok {
 				allKeyValue = false
-				break
+				if i >= strct.NumFields() {
+					break
+				}
+				field := strct.Field(i)
+				if !field.Exported() {
+					// Adding unexported field names for structs not defined
+					// locally will not work.
+					suggestedFixAvailable = false
+					break
+				}
+				missingKeys = append(missingKeys, analysis.TextEdit{
+					Pos:     e.Pos(),
+					End:     e.Pos(),
+					NewText: []byte(field.Name() + ": "),
+				})
 			}
 		}
 		if allKeyValue {
@@ -88,7 +106,17 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return
 		}
 
-		pass.Reportf(cl.Pos(), "%s composite literal uses unkeyed fields", typeName)
+		d := analysis.Diagnostic{
+			Pos:     cl.Pos(),
+			Message: typeName + " composite literal uses unkeyed fields",
+		}
+		if suggestedFixAvailable {
+			d.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Add field names to struct literal",
+				TextEdits: missingKeys,
+			}}
+		}
+		pass.Report(d)
 	})
 	return nil, nil
 }
@@ -13,5 +13,5 @@ import (
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, composite.Analyzer, "a")
+	analysistest.RunWithSuggestedFixes(t, testdata, composite.Analyzer, "a")
 }
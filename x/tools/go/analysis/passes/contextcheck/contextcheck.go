@@ -0,0 +1,138 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package contextcheck defines an Analyzer that checks conventions
+// for passing context.Context values.
+package contextcheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check conventions for passing context.Context
+
+This checker enforces the conventions described in the context package
+documentation: a Context should be the first parameter of a function,
+should not be stored inside a struct type, and functions that receive a
+Context should use it rather than create a detached one with
+context.Background or context.TODO.
+
+The struct field check may be disabled with the -structfield=false flag,
+for code bases that deliberately carry a Context in request-scoped
+types.`
+
+var checkStructFields = true
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "contextcheck",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkStructFields, "structfield", checkStructFields,
+		"check for context.Context stored in a struct field")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+		(*ast.StructType)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			checkParams(pass, n.Type)
+			if n.Body != nil {
+				checkDetachedContext(pass, n.Type, n.Body)
+			}
+		case *ast.FuncLit:
+			checkParams(pass, n.Type)
+			checkDetachedContext(pass, n.Type, n.Body)
+		case *ast.StructType:
+			if checkStructFields {
+				for _, f := range n.Fields.List {
+					if isContextType(pass.TypesInfo.TypeOf(f.Type)) {
+						pass.Reportf(f.Pos(), "context.Context should not be stored in a struct type; pass it as an argument instead")
+					}
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+// checkParams reports a context.Context parameter that is not the first
+// parameter of the function.
+func checkParams(pass *analysis.Pass, ftype *ast.FuncType) {
+	params := ftype.Params.List
+	for i, f := range params {
+		if !isContextType(pass.TypesInfo.TypeOf(f.Type)) {
+			continue
+		}
+		if i > 0 {
+			pass.Reportf(f.Pos(), "context.Context should be the first parameter of a function")
+		}
+		return // report at most once per signature
+	}
+}
+
+// checkDetachedContext reports uses of context.Background or
+// context.TODO inside a function that already has a Context parameter.
+// Nested function literals are visited on their own, so that a literal
+// deliberately detaching itself from the caller's Context (for example,
+// for background work) is not reported.
+func checkDetachedContext(pass *analysis.Pass, ftype *ast.FuncType, body *ast.BlockStmt) {
+	if !hasContextParam(pass, ftype) {
+		return
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		fn, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "context" {
+			return true
+		}
+		if name := fn.Name(); name == "Background" || name == "TODO" {
+			pass.Reportf(call.Pos(), "context.%s called in a function with a context.Context parameter; use the parameter", name)
+		}
+		return true
+	})
+}
+
+func hasContextParam(pass *analysis.Pass, ftype *ast.FuncType) bool {
+	for _, f := range ftype.Params.List {
+		if isContextType(pass.TypesInfo.TypeOf(f.Type)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isContextType(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Context" && obj.Pkg() != nil && obj.Pkg().Path() == "context"
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the contextcheck checker.
+
+package a
+
+import "context"
+
+func first(ctx context.Context, x int) {} // ok
+
+func notFirst(x int, ctx context.Context) {} // want "context.Context should be the first parameter of a function"
+
+func last(x, y int, ctx context.Context) {} // want "context.Context should be the first parameter of a function"
+
+func noContext(x int) {} // ok
+
+type request struct {
+	ctx context.Context // want "context.Context should not be stored in a struct type; pass it as an argument instead"
+	id  int
+}
+
+type handler struct {
+	name string
+}
+
+func detached(ctx context.Context) {
+	_ = context.Background() // want `context.Background called in a function with a context.Context parameter; use the parameter`
+	_ = context.TODO()       // want `context.TODO called in a function with a context.Context parameter; use the parameter`
+
+	// A nested literal without a Context parameter may detach
+	// deliberately, e.g. to outlive the request.
+	go func() {
+		_ = context.Background()
+	}()
+
+	f := func(ctx context.Context) {
+		_ = context.TODO() // want `context.TODO called in a function with a context.Context parameter; use the parameter`
+	}
+	f(ctx)
+}
+
+func noParam() {
+	_ = context.Background() // ok: no Context to use
+}
@@ -116,6 +116,23 @@ func SizeofMutex() {
 	Sizeof(mu) // want "call of Sizeof copies lock value: sync.Mutex"
 }
 
+func AlignofMutex() {
+	var mu sync.Mutex
+	unsafe.Alignof(mu)  // OK
+	unsafe1.Alignof(mu) // OK
+	Alignof(mu)         // OK
+}
+
+func OffsetofMutex() {
+	var s struct {
+		a  int
+		mu sync.Mutex
+	}
+	unsafe.Offsetof(s.mu)  // OK
+	unsafe1.Offsetof(s.mu) // OK
+	_ = s.a
+}
+
 // SyncTypesCheck checks copying of sync.* types except sync.Mutex
 func SyncTypesCheck() {
 	// sync.RWMutex copying
@@ -0,0 +1,227 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package copyproto defines an Analyzer that checks for protobuf
+// messages erroneously compared or copied by value.
+package copyproto
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/analysis/passes/internal/protoutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check for protobuf messages compared or copied by value
+
+Generated protocol buffer message structs carry internal state such
+as size caches and unknown-field storage. Copying a message value
+silently duplicates or discards that state, and comparing message
+values with == compares it too. Messages should be referred to
+through pointers, duplicated with proto.Clone, and compared with
+proto.Equal.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "copyproto",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.BinaryExpr)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+		(*ast.GenDecl)(nil),
+		(*ast.RangeStmt)(nil),
+		(*ast.SwitchStmt)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(node ast.Node) {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			checkAssign(pass, node)
+		case *ast.BinaryExpr:
+			checkCompare(pass, node)
+		case *ast.CallExpr:
+			checkCall(pass, node)
+		case *ast.FuncDecl:
+			checkFunc(pass, node.Name.Name, node.Recv, node.Type)
+		case *ast.FuncLit:
+			checkFunc(pass, "func", nil, node.Type)
+		case *ast.GenDecl:
+			checkGenDecl(pass, node)
+		case *ast.RangeStmt:
+			checkRange(pass, node)
+		case *ast.SwitchStmt:
+			checkSwitch(pass, node)
+		}
+	})
+	return nil, nil
+}
+
+// checkCompare checks for == and != applied to message values.
+func checkCompare(pass *analysis.Pass, e *ast.BinaryExpr) {
+	if e.Op != token.EQL && e.Op != token.NEQ {
+		return
+	}
+	if !isMessageValue(pass, e.X) && !isMessageValue(pass, e.Y) {
+		return
+	}
+	d := analysis.Diagnostic{
+		Pos:     e.Pos(),
+		End:     e.End(),
+		Message: fmt.Sprintf("comparison of protobuf message values with %s; use proto.Equal", e.Op),
+	}
+	// For *a == *b, comparing the pointed-to messages,
+	// proto.Equal(a, b) is a direct replacement.
+	if x, ok := e.X.(*ast.StarExpr); ok {
+		if y, ok := e.Y.(*ast.StarExpr); ok {
+			not := ""
+			if e.Op == token.NEQ {
+				not = "!"
+			}
+			d.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: "use proto.Equal",
+				TextEdits: []analysis.TextEdit{{
+					Pos: e.Pos(),
+					End: e.End(),
+					NewText: []byte(fmt.Sprintf("%sproto.Equal(%s, %s)", not,
+						analysisutil.Format(pass.Fset, x.X),
+						analysisutil.Format(pass.Fset, y.X))),
+				}},
+			}}
+		}
+	}
+	pass.Report(d)
+}
+
+// checkSwitch checks for switch statements whose tag is a message
+// value; each case comparison then compares messages with ==.
+func checkSwitch(pass *analysis.Pass, s *ast.SwitchStmt) {
+	if s.Tag != nil && isMessageValue(pass, s.Tag) {
+		pass.ReportRangef(s.Tag, "switch on protobuf message value compares messages with ==; use proto.Equal")
+	}
+}
+
+// checkAssign checks whether an assignment copies a message.
+func checkAssign(pass *analysis.Pass, as *ast.AssignStmt) {
+	for i, x := range as.Rhs {
+		if isBlank(as.Lhs[i]) {
+			continue // discarded, not copied
+		}
+		if copiesMessage(pass, x) {
+			pass.ReportRangef(x, "assignment copies protobuf message value to %v; use a pointer or proto.Clone",
+				analysisutil.Format(pass.Fset, as.Lhs[i]))
+		}
+	}
+}
+
+// checkGenDecl checks whether a message is copied in a variable declaration.
+func checkGenDecl(pass *analysis.Pass, gd *ast.GenDecl) {
+	if gd.Tok != token.VAR {
+		return
+	}
+	for _, spec := range gd.Specs {
+		valueSpec := spec.(*ast.ValueSpec)
+		for i, x := range valueSpec.Values {
+			if valueSpec.Names[i].Name == "_" {
+				continue // discarded, not copied
+			}
+			if copiesMessage(pass, x) {
+				pass.ReportRangef(x, "variable declaration copies protobuf message value to %v; use a pointer or proto.Clone",
+					valueSpec.Names[i].Name)
+			}
+		}
+	}
+}
+
+// checkCall checks whether a message is copied in the arguments to a
+// function call.
+func checkCall(pass *analysis.Pass, ce *ast.CallExpr) {
+	for _, x := range ce.Args {
+		if copiesMessage(pass, x) {
+			pass.ReportRangef(x, "call of %s copies protobuf message value",
+				analysisutil.Format(pass.Fset, ce.Fun))
+		}
+	}
+}
+
+// checkRange checks whether ranging over a slice, array, or map of
+// messages copies an element into the value variable each iteration.
+func checkRange(pass *analysis.Pass, r *ast.RangeStmt) {
+	if r.Value != nil && isMessageValue(pass, r.Value) {
+		pass.ReportRangef(r.Value, "range variable %v copies a protobuf message value each iteration",
+			analysisutil.Format(pass.Fset, r.Value))
+	}
+}
+
+// checkFunc checks whether a function declares message-valued
+// receivers, parameters, or results.
+func checkFunc(pass *analysis.Pass, name string, recv *ast.FieldList, typ *ast.FuncType) {
+	if recv != nil && len(recv.List) > 0 {
+		expr := recv.List[0].Type
+		if messageValueType(pass, expr) != nil {
+			pass.ReportRangef(expr, "%s passes protobuf message by value: use a pointer receiver", name)
+		}
+	}
+	if typ.Params != nil {
+		for _, field := range typ.Params.List {
+			if messageValueType(pass, field.Type) != nil {
+				pass.ReportRangef(field.Type, "%s passes protobuf message by value: use a pointer", name)
+			}
+		}
+	}
+}
+
+// copiesMessage reports whether evaluating x copies an existing
+// protobuf message value. Composite literals and conversions
+// construct a new value, and calls return one, so they are not
+// themselves copies.
+func copiesMessage(pass *analysis.Pass, x ast.Expr) bool {
+	x = analysisutil.Unparen(x)
+	switch x.(type) {
+	case *ast.CompositeLit, *ast.CallExpr:
+		return false
+	}
+	return isMessageValue(pass, x)
+}
+
+// isMessageValue reports whether the type of e is a generated
+// protobuf message struct type (not a pointer to one).
+func isMessageValue(pass *analysis.Pass, e ast.Expr) bool {
+	return messageValueType(pass, e) != nil
+}
+
+// messageValueType returns the type of e if it is a message struct
+// value type, or nil.
+func messageValueType(pass *analysis.Pass, e ast.Expr) types.Type {
+	t := pass.TypesInfo.TypeOf(e)
+	if t == nil {
+		return nil
+	}
+	if _, ok := t.Underlying().(*types.Pointer); ok {
+		return nil
+	}
+	if !protoutil.IsMessage(t) {
+		return nil
+	}
+	return t
+}
+
+// isBlank reports whether e is the blank identifier.
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
@@ -0,0 +1,62 @@
+// This file contains tests for the copyproto checker.
+
+package a
+
+// Msg resembles a generated protobuf message type.
+type Msg struct {
+	Name string
+}
+
+func (m *Msg) Reset()         {}
+func (m *Msg) String() string { return "" }
+func (m *Msg) ProtoMessage()  {}
+
+// Plain is an ordinary struct.
+type Plain struct {
+	Name string
+}
+
+func compare(m1, m2 *Msg, p1, p2 Plain) {
+	_ = *m1 == *m2 // want `comparison of protobuf message values with ==; use proto.Equal`
+	_ = *m1 != *m2 // want `comparison of protobuf message values with !=; use proto.Equal`
+	_ = m1 == m2   // ok: pointer comparison
+	_ = p1 == p2   // ok: not a protobuf message
+
+	switch *m1 { // want `switch on protobuf message value compares messages with ==; use proto.Equal`
+	case *m2:
+	}
+	switch m1 { // ok: pointer comparison
+	case m2:
+	}
+}
+
+func sink(Msg)      {} // want `sink passes protobuf message by value: use a pointer`
+func ptrSink(*Msg)  {}
+func (m Msg) Get()  {} // want `Get passes protobuf message by value: use a pointer receiver`
+func (m *Msg) Put() {}
+
+func copies(m *Msg, ms []Msg, p Plain) {
+	var v Msg
+
+	x := *m // want `assignment copies protobuf message value to x; use a pointer or proto.Clone`
+	x = v   // want `assignment copies protobuf message value to x; use a pointer or proto.Clone`
+	_ = x   // ok: discarded
+
+	var y = v // want `variable declaration copies protobuf message value to y; use a pointer or proto.Clone`
+	_ = y
+
+	ptrSink(&v)
+	sink(v) // want `call of sink copies protobuf message value`
+
+	for _, e := range ms { // want `range variable e copies a protobuf message value each iteration`
+		_ = e
+	}
+	for i := range ms { // ok: index only
+		_ = i
+	}
+
+	z := Msg{Name: "new"} // ok: constructs a new value
+	_ = z
+	q := p // ok: not a protobuf message
+	_ = q
+}
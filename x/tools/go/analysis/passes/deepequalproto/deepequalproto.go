@@ -0,0 +1,140 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deepequalproto defines an Analyzer that checks for the use
+// of reflect.DeepEqual, or cmp.Equal without the protocmp
+// transformer, with protocol buffer messages.
+package deepequalproto
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/protoutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check for generic deep comparisons of protobuf messages
+
+The deepequalproto checker looks for calls of the form:
+
+    reflect.DeepEqual(msg1, msg2)
+    cmp.Equal(msg1, msg2)
+    cmp.Diff(msg1, msg2)
+
+where the operands are generated protocol buffer messages. Such
+messages carry internal state (size caches, unknown fields) that
+makes generic deep comparison unreliable: use proto.Equal, or pass
+protocmp.Transform() as an option to the cmp functions.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "deepequalproto",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || len(call.Args) < 2 {
+			return
+		}
+		if !hasMessage(pass, call.Args[0]) && !hasMessage(pass, call.Args[1]) {
+			return
+		}
+		switch fn.FullName() {
+		case "reflect.DeepEqual":
+			pass.ReportRangef(call, "avoid using reflect.DeepEqual with protobuf messages; use proto.Equal instead")
+		case "github.com/google/go-cmp/cmp.Equal", "github.com/google/go-cmp/cmp.Diff":
+			if !hasProtocmpOption(pass, call.Args[2:]) {
+				pass.ReportRangef(call, "%s on protobuf messages without protocmp.Transform()", fn.Name())
+			}
+		}
+	})
+	return nil, nil
+}
+
+// hasMessage reports whether the type of e is, or contains, a
+// generated protobuf message type. Containment covers the types that
+// a deep comparison would descend into: struct fields, slice and
+// array elements, map keys and elements, and pointers.
+func hasMessage(pass *analysis.Pass, e ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[e]
+	if !ok { // no type info, assume good
+		return false
+	}
+	return containsMessage(tv.Type)
+}
+
+func containsMessage(typ types.Type) bool {
+	// Track types being processed, to avoid infinite recursion.
+	seen := make(map[types.Type]bool)
+
+	var check func(t types.Type) bool
+	check = func(t types.Type) bool {
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		if protoutil.IsMessage(t) {
+			return true
+		}
+		switch t := t.(type) {
+		case *types.Named:
+			return check(t.Underlying())
+		case *types.Pointer:
+			return check(t.Elem())
+		case *types.Array:
+			return check(t.Elem())
+		case *types.Slice:
+			return check(t.Elem())
+		case *types.Map:
+			return check(t.Key()) || check(t.Elem())
+		case *types.Struct:
+			for i := 0; i < t.NumFields(); i++ {
+				if check(t.Field(i).Type()) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return check(typ)
+}
+
+// hasProtocmpOption reports whether any of the option arguments
+// mentions an object from a protocmp package, such as a
+// protocmp.Transform() call.
+func hasProtocmpOption(pass *analysis.Pass, opts []ast.Expr) bool {
+	for _, opt := range opts {
+		found := false
+		ast.Inspect(opt, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if obj := pass.TypesInfo.Uses[id]; obj != nil && obj.Pkg() != nil {
+					path := obj.Pkg().Path()
+					if path == "protocmp" || strings.HasSuffix(path, "/protocmp") {
+						found = true
+						return false
+					}
+				}
+			}
+			return true
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+// This file contains tests for the deepequalproto checker.
+
+package a
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// Msg resembles a generated protobuf message type.
+type Msg struct {
+	Name string
+}
+
+func (m *Msg) Reset()         {}
+func (m *Msg) String() string { return "" }
+func (m *Msg) ProtoMessage()  {}
+
+// Plain is an ordinary struct.
+type Plain struct {
+	Name string
+}
+
+type wrapper struct {
+	msg *Msg
+}
+
+func comparisons(m1, m2 *Msg, p1, p2 *Plain, w1, w2 wrapper, ms1, ms2 []*Msg) {
+	reflect.DeepEqual(m1, m2)   // want `avoid using reflect.DeepEqual with protobuf messages; use proto.Equal instead`
+	reflect.DeepEqual(*m1, *m2) // want `avoid using reflect.DeepEqual with protobuf messages; use proto.Equal instead`
+	reflect.DeepEqual(w1, w2)   // want `avoid using reflect.DeepEqual with protobuf messages; use proto.Equal instead`
+	reflect.DeepEqual(ms1, ms2) // want `avoid using reflect.DeepEqual with protobuf messages; use proto.Equal instead`
+	reflect.DeepEqual(p1, p2)   // ok: not a protobuf message
+
+	cmp.Equal(m1, m2)                       // want `Equal on protobuf messages without protocmp.Transform\(\)`
+	cmp.Diff(m1, m2)                        // want `Diff on protobuf messages without protocmp.Transform\(\)`
+	cmp.Equal(m1, m2, protocmp.Transform()) // ok
+	cmp.Diff(m1, m2, protocmp.Transform())  // ok
+	cmp.Equal(p1, p2)                       // ok: not a protobuf message
+}
@@ -0,0 +1,8 @@
+// Minimal stub of github.com/google/go-cmp/cmp for testing.
+package cmp
+
+type Option interface{}
+
+func Equal(x, y interface{}, opts ...Option) bool { return false }
+
+func Diff(x, y interface{}, opts ...Option) string { return "" }
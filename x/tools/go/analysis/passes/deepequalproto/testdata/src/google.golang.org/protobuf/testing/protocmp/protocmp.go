@@ -0,0 +1,6 @@
+// Minimal stub of google.golang.org/protobuf/testing/protocmp for testing.
+package protocmp
+
+import "github.com/google/go-cmp/cmp"
+
+func Transform() cmp.Option { return nil }
@@ -0,0 +1,102 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deferloop defines an Analyzer that reports defer statements
+// inside loops.
+package deferloop
+
+import (
+	"fmt"
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check for defer statements inside loops
+
+A defer statement inside a loop does not run at the end of the
+iteration; the deferred calls accumulate and all run when the
+surrounding function returns. When the deferred call releases a
+resource, such as a file, database rows, or a lock, every iteration of
+the loop holds its resource until the function returns, which can
+exhaust file descriptors or deadlock the program.
+
+Diagnostics are reported under two categories: "resource" when the
+deferred call manifestly releases a resource (a Close, Unlock or
+RUnlock method), and "loop" for the remaining, merely surprising,
+cases.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "deferloop",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				find(pass, n.Body, false)
+			}
+		case *ast.FuncLit:
+			find(pass, n.Body, false)
+		}
+	})
+	return nil, nil
+}
+
+// find reports defer statements beneath n that execute within a loop.
+// Function literals are not descended into: a defer inside a literal
+// runs when the literal returns, even if the literal itself is called
+// in a loop, and each literal is visited in its own right.
+func find(pass *analysis.Pass, n ast.Node, inLoop bool) {
+	ast.Inspect(n, func(m ast.Node) bool {
+		switch m := m.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ForStmt:
+			find(pass, m.Body, true)
+			return false
+		case *ast.RangeStmt:
+			find(pass, m.Body, true)
+			return false
+		case *ast.DeferStmt:
+			if inLoop {
+				report(pass, m)
+			}
+		}
+		return true
+	})
+}
+
+func report(pass *analysis.Pass, d *ast.DeferStmt) {
+	if sel, ok := d.Call.Fun.(*ast.SelectorExpr); ok {
+		switch sel.Sel.Name {
+		case "Close", "Unlock", "RUnlock":
+			pass.Report(analysis.Diagnostic{
+				Pos:      d.Pos(),
+				Category: "resource",
+				Message: fmt.Sprintf("deferred %s call in loop holds the resource until the surrounding function returns",
+					analysisutil.Format(pass.Fset, d.Call.Fun)),
+			})
+			return
+		}
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:      d.Pos(),
+		Category: "loop",
+		Message:  "defer inside loop runs only when the surrounding function returns",
+	})
+}
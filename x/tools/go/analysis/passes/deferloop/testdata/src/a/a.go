@@ -0,0 +1,73 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the deferloop checker.
+
+package a
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+func openAll(names []string) error {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close() // want `deferred f.Close call in loop holds the resource until the surrounding function returns`
+	}
+	return nil
+}
+
+func lockAll(mus []*sync.Mutex) {
+	for i := 0; i < len(mus); i++ {
+		mus[i].Lock()
+		defer mus[i].Unlock() // want `deferred mus\[i\].Unlock call in loop holds the resource until the surrounding function returns`
+	}
+}
+
+func stylistic(n int) {
+	for i := 0; i < n; i++ {
+		defer fmt.Println(i) // want `defer inside loop runs only when the surrounding function returns`
+	}
+}
+
+func closurePerIteration(names []string) error {
+	for _, name := range names {
+		err := func() error {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			defer f.Close() // no report: runs when the literal returns
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func noLoop(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // no report
+	return nil
+}
+
+func literalInLoop(names []string) {
+	for range names {
+		go func() {
+			mu := new(sync.Mutex)
+			mu.Lock()
+			defer mu.Unlock() // no report
+		}()
+	}
+}
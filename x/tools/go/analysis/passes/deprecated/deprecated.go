@@ -0,0 +1,118 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package deprecated defines an Analyzer that reports uses of
+// identifiers whose doc comments carry a "Deprecated:" marker.
+package deprecated
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `report uses of deprecated identifiers
+
+By convention, a doc comment paragraph beginning "Deprecated:" marks
+the declared identifier as deprecated and tells users what to use
+instead. This checker records such markers as facts while analyzing
+the defining package and reports each use of a deprecated identifier
+from other packages, so deprecations are enforced across package and
+module boundaries.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "deprecated",
+	Doc:       Doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(isDeprecated)},
+	Run:       run,
+}
+
+// isDeprecated is a fact recording the message of an identifier's
+// "Deprecated:" doc paragraph.
+type isDeprecated struct{ Msg string }
+
+func (f *isDeprecated) AFact() {}
+
+func (f *isDeprecated) String() string { return "deprecated: " + f.Msg }
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// Pass 1: export a fact for each deprecated declaration of
+	// this package.
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if msg, ok := deprecationMessage(decl.Doc); ok {
+					if obj := pass.TypesInfo.Defs[decl.Name]; obj != nil {
+						pass.ExportObjectFact(obj, &isDeprecated{msg})
+					}
+				}
+			case *ast.GenDecl:
+				groupMsg, groupOK := deprecationMessage(decl.Doc)
+				for _, spec := range decl.Specs {
+					var names []*ast.Ident
+					var doc *ast.CommentGroup
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						names, doc = spec.Names, spec.Doc
+					case *ast.TypeSpec:
+						names, doc = []*ast.Ident{spec.Name}, spec.Doc
+					}
+					msg, ok := deprecationMessage(doc)
+					if !ok {
+						msg, ok = groupMsg, groupOK
+					}
+					if !ok {
+						continue
+					}
+					for _, name := range names {
+						if obj := pass.TypesInfo.Defs[name]; obj != nil {
+							pass.ExportObjectFact(obj, &isDeprecated{msg})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Pass 2: report uses of deprecated identifiers of other
+	// packages. The defining package may keep using them, as the
+	// standard library does.
+	nodeFilter := []ast.Node{
+		(*ast.Ident)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		id := n.(*ast.Ident)
+		obj := pass.TypesInfo.Uses[id]
+		if obj == nil || obj.Pkg() == nil || obj.Pkg() == pass.Pkg {
+			return
+		}
+		var fact isDeprecated
+		if pass.ImportObjectFact(obj, &fact) {
+			pass.ReportRangef(id, "%s is deprecated: %s", obj.Name(), fact.Msg)
+		}
+	})
+	return nil, nil
+}
+
+// deprecationMessage returns the message of the "Deprecated:"
+// paragraph of the doc comment, if any.
+func deprecationMessage(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, para := range strings.Split(doc.Text(), "\n\n") {
+		if strings.HasPrefix(para, "Deprecated: ") {
+			msg := strings.TrimPrefix(para, "Deprecated: ")
+			return strings.TrimSpace(strings.Replace(msg, "\n", " ", -1)), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,28 @@
+// This file contains the defining package for the deprecated checker tests.
+
+package a
+
+// OldFunc does a thing.
+//
+// Deprecated: use NewFunc instead.
+func OldFunc() {} // want OldFunc:"deprecated: use NewFunc instead."
+
+// NewFunc does the thing well.
+func NewFunc() {}
+
+// Deprecated: use Limit instead.
+const MaxSize = 1 << 10 // want MaxSize:"deprecated: use Limit instead."
+
+// Limit is the preferred name for MaxSize.
+const Limit = 1 << 10
+
+// OldType holds state.
+//
+// Deprecated: use NewType instead.
+type OldType struct{} // want OldType:"deprecated: use NewType instead."
+
+// NewType is the replacement for OldType.
+type NewType struct{}
+
+// internal use of deprecated identifiers is not reported.
+var _ = OldFunc
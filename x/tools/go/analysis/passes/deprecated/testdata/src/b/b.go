@@ -0,0 +1,12 @@
+// This file contains the using package for the deprecated checker tests.
+
+package b
+
+import "a"
+
+var _ = a.OldFunc // want `OldFunc is deprecated: use NewFunc instead.`
+var _ = a.NewFunc // ok
+var _ = a.MaxSize // want `MaxSize is deprecated: use Limit instead.`
+var _ = a.Limit   // ok
+var _ a.OldType   // want `OldType is deprecated: use NewType instead.`
+var _ a.NewType   // ok
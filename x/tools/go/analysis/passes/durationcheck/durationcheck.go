@@ -0,0 +1,102 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package durationcheck defines an Analyzer that detects
+// multiplication of two time.Duration values.
+package durationcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check for multiplication of two time.Duration values
+
+Multiplying two values of type time.Duration almost always indicates a
+bug, as in
+
+	interval := delay * time.Second
+
+where delay already holds a number of nanoseconds: the result is the
+square of a duration rather than a scaled one. Multiplying a duration
+by an untyped constant, or scaling a plain integer converted with
+time.Duration(n), is fine and is not reported.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "durationcheck",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.BinaryExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		expr := n.(*ast.BinaryExpr)
+		if expr.Op != token.MUL {
+			return
+		}
+		if isDurationValue(pass, expr.X) && isDurationValue(pass, expr.Y) {
+			pass.Reportf(expr.Pos(), "multiplication of two time.Duration values: %s", analysisutil.Format(pass.Fset, expr))
+		}
+	})
+	return nil, nil
+}
+
+// isDurationValue reports whether e is an operand of duration type that
+// contributes a duration, rather than a plain count, to a product:
+// a non-constant expression of type time.Duration, a constant of
+// declared type time.Duration such as time.Second, or a conversion
+// time.Duration(x) of a value that is itself a duration.
+func isDurationValue(pass *analysis.Pass, e ast.Expr) bool {
+	e = analysisutil.Unparen(e)
+	if !isDuration(pass.TypesInfo.TypeOf(e)) {
+		return false
+	}
+
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return false // untyped constant, e.g. 2 * time.Second
+	case *ast.CallExpr:
+		// A conversion time.Duration(x) expresses the intent to
+		// scale by x; report only if x is already a duration.
+		if tv, ok := pass.TypesInfo.Types[e.Fun]; ok && tv.IsType() && len(e.Args) == 1 {
+			return isDurationValue(pass, e.Args[0])
+		}
+	case *ast.Ident, *ast.SelectorExpr:
+		// An identifier for an untyped constant, such as a local
+		// "const n = 10", scales rather than multiplies.
+		var obj types.Object
+		if id, ok := e.(*ast.Ident); ok {
+			obj = pass.TypesInfo.ObjectOf(id)
+		} else {
+			obj = pass.TypesInfo.ObjectOf(e.(*ast.SelectorExpr).Sel)
+		}
+		if c, ok := obj.(*types.Const); ok {
+			if b, ok := c.Type().(*types.Basic); ok && b.Info()&types.IsUntyped != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isDuration(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Duration" && obj.Pkg() != nil && obj.Pkg().Path() == "time"
+}
@@ -0,0 +1,29 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the durationcheck checker.
+
+package a
+
+import "time"
+
+func hasError(d, e time.Duration, n int) {
+	_ = d * time.Second                // want `multiplication of two time.Duration values: d \* time.Second`
+	_ = time.Second * d                // want `multiplication of two time.Duration values: time.Second \* d`
+	_ = d * e                          // want `multiplication of two time.Duration values: d \* e`
+	_ = time.Duration(d) * time.Second // want `multiplication of two time.Duration values: time.Duration\(d\) \* time.Second`
+	_ = (d) * (time.Millisecond)       // want `multiplication of two time.Duration values`
+}
+
+func noError(d time.Duration, n int) {
+	_ = time.Duration(n) * time.Second // an int scaled to a duration
+	_ = 2 * time.Second
+	_ = time.Second * 2
+	_ = d * 2
+	const scale = 10
+	_ = scale * time.Second
+	_ = d / time.Second // division converts back to a count
+	_ = d + time.Second
+	_ = n * 2 // not durations at all
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fieldalignment defines an Analyzer that detects structs that
+// would occupy less memory if their fields were sorted.
+package fieldalignment
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `find structs that would occupy less memory if their fields were sorted
+
+This analyzer computes the memory layout of each struct type for the
+target architecture and reports those whose field order wastes space on
+padding. The suggested fix reorders the fields. Runs of fields separated
+by a documentation comment or a blank line are treated as groups and are
+reordered only within the group, so logically related fields stay
+together.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "fieldalignment",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// A unit is a run of identically typed fields declared by a single
+// ast.Field, the indivisible element of a reordering.
+type unit struct {
+	field *ast.Field
+	size  int64 // size of one declared field
+	align int64
+	names int64 // number of fields declared (>= 1)
+	group int   // index of the comment-delimited group
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.StructType)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		s := n.(*ast.StructType)
+		if tv, ok := pass.TypesInfo.Types[s]; ok {
+			checkStruct(pass, s, tv.Type.(*types.Struct))
+		}
+	})
+	return nil, nil
+}
+
+func checkStruct(pass *analysis.Pass, s *ast.StructType, styp *types.Struct) {
+	if len(s.Fields.List) < 2 {
+		return // nothing to reorder
+	}
+
+	// Gather the units, assigning each to a group. A documentation
+	// comment or a blank line starts a new group.
+	var units []unit
+	group := 0
+	prevLine := pass.Fset.Position(s.Fields.Opening).Line
+	for _, f := range s.Fields.List {
+		pos := f.Pos()
+		if f.Doc != nil {
+			pos = f.Doc.Pos()
+		}
+		if f.Doc != nil || pass.Fset.Position(pos).Line > prevLine+1 {
+			group++
+		}
+		prevLine = pass.Fset.Position(f.End()).Line
+		if f.Comment != nil {
+			prevLine = pass.Fset.Position(f.Comment.End()).Line
+		}
+
+		ftyp := pass.TypesInfo.TypeOf(f.Type)
+		if ftyp == nil {
+			return
+		}
+		names := int64(len(f.Names))
+		if names == 0 {
+			names = 1 // embedded field
+		}
+		units = append(units, unit{
+			field: f,
+			size:  pass.TypesSizes.Sizeof(ftyp),
+			align: pass.TypesSizes.Alignof(ftyp),
+			names: names,
+			group: group,
+		})
+	}
+
+	optimal := make([]unit, len(units))
+	copy(optimal, units)
+	sort.SliceStable(optimal, func(i, j int) bool {
+		a, b := optimal[i], optimal[j]
+		if a.group != b.group {
+			return a.group < b.group
+		}
+		// Zero-sized fields first, so that they never end up
+		// trailing the struct, then in order of decreasing
+		// alignment and size.
+		if za, zb := a.size == 0, b.size == 0; za != zb {
+			return za
+		}
+		if a.align != b.align {
+			return a.align > b.align
+		}
+		return a.size > b.size
+	})
+
+	cur, opt := sizeOf(units), sizeOf(optimal)
+	if opt >= cur {
+		return
+	}
+
+	d := analysis.Diagnostic{
+		Pos:     s.Pos(),
+		Message: fmt.Sprintf("struct of size %d could be %d", cur, opt),
+	}
+	if edits := reorderEdits(pass, units, optimal); edits != nil {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Sort fields to reduce padding",
+			TextEdits: edits,
+		}}
+	}
+	pass.Report(d)
+}
+
+// sizeOf computes the size of a struct laid out with the given units in
+// order, following the usual alignment rules.
+func sizeOf(units []unit) int64 {
+	var offset, maxAlign int64 = 0, 1
+	for _, u := range units {
+		if u.align > maxAlign {
+			maxAlign = u.align
+		}
+		for i := int64(0); i < u.names; i++ {
+			offset = roundUp(offset, u.align)
+			offset += u.size
+		}
+	}
+	return roundUp(offset, maxAlign)
+}
+
+func roundUp(offset, align int64) int64 {
+	if align > 1 {
+		offset = (offset + align - 1) &^ (align - 1)
+	}
+	return offset
+}
+
+// reorderEdits returns edits replacing each field declaration with the
+// one that belongs at its position in the optimal order. Documentation
+// comments stay where they are, marking the start of their group; line
+// comments travel with their field.
+func reorderEdits(pass *analysis.Pass, units, optimal []unit) []analysis.TextEdit {
+	tf := pass.Fset.File(units[0].field.Pos())
+	if tf == nil {
+		return nil
+	}
+	content, err := pass.ReadFile(tf.Name())
+	if err != nil {
+		return nil
+	}
+
+	span := func(u unit) (token.Pos, token.Pos) {
+		end := u.field.End()
+		if u.field.Comment != nil {
+			end = u.field.Comment.End()
+		}
+		return u.field.Pos(), end
+	}
+
+	var edits []analysis.TextEdit
+	for i := range units {
+		if units[i].field == optimal[i].field {
+			continue
+		}
+		pos, end := span(units[i])
+		newPos, newEnd := span(optimal[i])
+		edits = append(edits, analysis.TextEdit{
+			Pos:     pos,
+			End:     end,
+			NewText: content[tf.Offset(newPos):tf.Offset(newEnd)],
+		})
+	}
+	return edits
+}
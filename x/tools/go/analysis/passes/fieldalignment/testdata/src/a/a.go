@@ -0,0 +1,40 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the fieldalignment checker.
+// It uses only types whose size and alignment are the same
+// on all supported architectures.
+
+package a
+
+type Wasteful struct { // want "struct of size 12 could be 8"
+	a bool
+	b int32
+	c bool
+}
+
+type Packed struct { // no waste: fields already sorted
+	b int32
+	a bool
+	c bool
+}
+
+type Grouped struct { // no report: reordering across groups is not suggested
+	// flags
+	a bool
+
+	// counters
+	b int32
+	c bool
+}
+
+type InnerOuter struct { // want "struct of size 16 could be 12"
+	a bool
+	e int32
+	b struct { // inner struct is already packed
+		c int16
+		d int8
+	}
+	f int16
+}
@@ -0,0 +1,322 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package funcsummary defines an analyzer that summarizes, for each
+// function of a package, whether it may store its pointer arguments,
+// call C, or write memory its caller can observe. By itself, it does
+// not report any diagnostics.
+//
+// The summaries are exported as facts, so they are available when
+// dependent packages are analyzed. Analyzers that today must assume
+// the worst of every call may require this one instead and consult
+// the summary of the callee.
+//
+// The analysis is syntactic and deliberately conservative: a function
+// without a summary is assumed to store its arguments and to have side
+// effects, though not to call C, and any assignment through a pointer
+// or to a non-local variable counts as a side effect.
+package funcsummary
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "funcsummary",
+	Doc:        "summarize the externally visible effects of each function",
+	Run:        run,
+	FactTypes:  []analysis.Fact{new(summaryFact)},
+	ResultType: reflect.TypeOf(new(Result)),
+}
+
+// A Summary describes what a function may do to memory that its
+// caller can observe. The zero value describes a pure function.
+type Summary struct {
+	StoresArgs  bool // may store one of its pointer arguments
+	CallsC      bool // calls C, directly or through another function
+	SideEffects bool // may write memory visible outside the function
+}
+
+// A summaryFact is the Summary of a function, exported as a fact. A
+// recorded all-false Summary is meaningful: unlike a missing fact, it
+// promises that the function is pure.
+type summaryFact struct{ S Summary }
+
+func (*summaryFact) AFact() {}
+
+func (f *summaryFact) String() string {
+	var parts []string
+	if f.S.StoresArgs {
+		parts = append(parts, "storesArgs")
+	}
+	if f.S.CallsC {
+		parts = append(parts, "callsC")
+	}
+	if f.S.SideEffects {
+		parts = append(parts, "sideEffects")
+	}
+	if len(parts) == 0 {
+		return "pure"
+	}
+	return strings.Join(parts, ",")
+}
+
+// A Result holds the summaries of the functions declared by the
+// package being analyzed and of the functions they call.
+type Result struct {
+	m map[*types.Func]*Summary
+}
+
+// Summary returns the summary of fn, which may be declared in another
+// package, or nil if none was computed for it.
+func (r *Result) Summary(fn *types.Func) *Summary { return r.m[fn] }
+
+// worst is the summary assumed for a function with no recorded fact.
+var worst = &Summary{StoresArgs: true, SideEffects: true}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	result := &Result{m: make(map[*types.Func]*Summary)}
+
+	// The declarations of this package's functions.
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+					decls[fn] = decl
+					result.m[fn] = new(Summary)
+				}
+			}
+		}
+	}
+
+	// lookup returns the summary of fn from the summaries computed so
+	// far for this package, or from the facts of the packages already
+	// analyzed.
+	lookup := func(fn *types.Func) *Summary {
+		if s, ok := result.m[fn]; ok {
+			return s
+		}
+		var fact summaryFact
+		if pass.ImportObjectFact(fn, &fact) {
+			s := fact.S
+			result.m[fn] = &s
+			return &s
+		}
+		return worst
+	}
+
+	// Summaries of mutually recursive functions depend on each other;
+	// iterate to a fixed point. Flags only ever change from false to
+	// true, so the iteration terminates.
+	for {
+		changed := false
+		for fn, decl := range decls {
+			if analyze(pass, decl, result.m[fn], lookup) {
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	for fn := range decls {
+		pass.ExportObjectFact(fn, &summaryFact{S: *result.m[fn]})
+	}
+
+	return result, nil
+}
+
+// analyze updates s, the summary of the function declared by decl, and
+// reports whether it changed.
+func analyze(pass *analysis.Pass, decl *ast.FuncDecl, s *Summary, lookup func(*types.Func) *Summary) bool {
+	info := pass.TypesInfo
+	changed := false
+	set := func(b *bool) {
+		if !*b {
+			*b = true
+			changed = true
+		}
+	}
+
+	// tainted holds the pointer-like parameters of the function,
+	// including its receiver, and the locals to which one of them may
+	// have been assigned.
+	tainted := make(map[types.Object]bool)
+	addFields := func(list *ast.FieldList) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				if obj := info.Defs[name]; obj != nil && pointerLike(obj.Type()) {
+					tainted[obj] = true
+				}
+			}
+		}
+	}
+	addFields(decl.Recv)
+	addFields(decl.Type.Params)
+
+	mentionsTainted := func(e ast.Node) bool {
+		found := false
+		ast.Inspect(e, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && tainted[info.Uses[id]] {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+
+	// Grow the tainted set through assignments to locals.
+	for {
+		grown := false
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			var names []*ast.Ident
+			var carries bool
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					id, ok := lhs.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					if len(n.Lhs) == len(n.Rhs) {
+						if mentionsTainted(n.Rhs[i]) {
+							names = append(names, id)
+						}
+					} else if carries || mentionsTainted(n.Rhs[0]) {
+						carries = true
+						names = append(names, id)
+					}
+				}
+			case *ast.ValueSpec:
+				for _, value := range n.Values {
+					carries = carries || mentionsTainted(value)
+				}
+				if carries {
+					names = n.Names
+				}
+			}
+			for _, id := range names {
+				obj := info.Defs[id]
+				if obj == nil {
+					obj = info.Uses[id]
+				}
+				if obj != nil && !tainted[obj] && pointerLike(obj.Type()) {
+					tainted[obj] = true
+					grown = true
+				}
+			}
+			return true
+		})
+		if !grown {
+			break
+		}
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				if isLocal(pass, lhs) {
+					continue
+				}
+				set(&s.SideEffects)
+				if len(n.Lhs) == len(n.Rhs) && mentionsTainted(n.Rhs[i]) {
+					set(&s.StoresArgs)
+				}
+			}
+		case *ast.IncDecStmt:
+			if !isLocal(pass, n.X) {
+				set(&s.SideEffects)
+			}
+		case *ast.SendStmt:
+			set(&s.SideEffects)
+			if mentionsTainted(n.Value) {
+				set(&s.StoresArgs)
+			}
+		case *ast.FuncLit:
+			// The literal may escape with everything it captures.
+			if mentionsTainted(n) {
+				set(&s.StoresArgs)
+			}
+		case *ast.CallExpr:
+			fun := analysisutil.Unparen(n.Fun)
+			if info.Types[fun].IsType() {
+				break // a conversion, not a call
+			}
+			if id, ok := fun.(*ast.Ident); ok {
+				if strings.HasPrefix(id.Name, "_Cfunc_") {
+					// A call into C, in a cgo-processed file.
+					set(&s.CallsC)
+					set(&s.SideEffects)
+					if mentionsTainted(n) {
+						set(&s.StoresArgs)
+					}
+					break
+				}
+				if _, ok := info.Uses[id].(*types.Builtin); ok {
+					break // builtins do not retain their arguments
+				}
+			}
+			cs := worst
+			if callee, ok := typeutil.Callee(info, n).(*types.Func); ok {
+				cs = lookup(callee)
+			}
+			if cs.SideEffects {
+				set(&s.SideEffects)
+			}
+			if cs.CallsC {
+				set(&s.CallsC)
+			}
+			if cs.StoresArgs && mentionsTainted(n) {
+				set(&s.StoresArgs)
+			}
+		}
+		return true
+	})
+
+	return changed
+}
+
+// isLocal reports whether e is an identifier for a variable declared
+// within the current function, including its parameters and results,
+// or the blank identifier.
+func isLocal(pass *analysis.Pass, e ast.Expr) bool {
+	id, ok := analysisutil.Unparen(e).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if id.Name == "_" {
+		return true
+	}
+	obj := pass.TypesInfo.Uses[id]
+	if obj == nil {
+		obj = pass.TypesInfo.Defs[id]
+	}
+	v, ok := obj.(*types.Var)
+	return ok && v.Parent() != pass.Pkg.Scope()
+}
+
+// pointerLike reports whether a value of type t can carry a pointer.
+func pointerLike(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan,
+		*types.Signature, *types.Interface:
+		return true
+	case *types.Basic:
+		return t.Underlying() == types.Typ[types.UnsafePointer]
+	}
+	return false
+}
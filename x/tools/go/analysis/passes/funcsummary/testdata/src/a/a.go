@@ -0,0 +1,60 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the funcsummary analyzer.
+
+package a
+
+import "unsafe"
+
+var global *int
+
+func Pure(x int) int { return x + 1 } // want Pure:"pure"
+
+func LocalOnly(p *int) int { // want LocalOnly:"pure"
+	x := *p
+	return x + 1
+}
+
+func StoreArg(p *int) { // want StoreArg:"storesArgs,sideEffects"
+	global = p
+}
+
+func Wrapper(p *int) { // want Wrapper:"storesArgs,sideEffects"
+	StoreArg(p)
+}
+
+func Alias(p *int) { // want Alias:"storesArgs,sideEffects"
+	q := p
+	global = q
+}
+
+func WriteGlobal() { // want WriteGlobal:"sideEffects"
+	*global = 1
+}
+
+func Sends(c chan *int, p *int) { // want Sends:"storesArgs,sideEffects"
+	c <- p
+}
+
+func Captures(p *int) func() { // want Captures:"storesArgs,sideEffects"
+	return func() { global = p }
+}
+
+func (t *T) Set(v int) { // want Set:"sideEffects"
+	t.x = v
+}
+
+type T struct{ x int }
+
+// _Cfunc_use stands in for a function generated by cgo.
+func _Cfunc_use(p unsafe.Pointer) {} // want _Cfunc_use:"pure"
+
+func UsesC(p unsafe.Pointer) { // want UsesC:"storesArgs,callsC,sideEffects"
+	_Cfunc_use(p)
+}
+
+func CallsCIndirectly() { // want CallsCIndirectly:"callsC,sideEffects"
+	UsesC(nil)
+}
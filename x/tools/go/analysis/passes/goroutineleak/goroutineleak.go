@@ -0,0 +1,315 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package goroutineleak inspects the SSA form of a package and reports
+// goroutines that can block forever on a channel operation.
+package goroutineleak
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+const doc = `check for goroutines that block forever on a local channel
+
+The goroutineleak checker looks for unbuffered channels that are
+created by a function, used on one side by a single goroutine started
+in that function, and used on the other side by the function itself.
+If the goroutine sends on the channel and the function can return
+without receiving (or vice versa), the goroutine blocks forever and
+its resources are never reclaimed. A typical example is:
+
+	c := make(chan error)
+	go func() { c <- work() }()
+	if err := setup(); err != nil {
+		return err // goroutine above is leaked
+	}
+	return <-c
+
+The analysis is deliberately conservative: channels that escape the
+function, are used in select statements, or are shared by several
+goroutines are not reported.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "goroutineleak",
+	Doc:      doc,
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	for _, fn := range ssainput.SrcFuncs {
+		runFunc(pass, fn)
+	}
+	return nil, nil
+}
+
+// chanUses describes every use of one local channel.
+type chanUses struct {
+	escapes     bool
+	parentSends []ssa.Instruction
+	parentRecvs []ssa.Instruction
+	goInstr     *ssa.Go // the single goroutine using the channel
+	goSends     int
+	goRecvs     int
+}
+
+func runFunc(pass *analysis.Pass, fn *ssa.Function) {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			mk, ok := instr.(*ssa.MakeChan)
+			if !ok {
+				continue
+			}
+			size, ok := mk.Size.(*ssa.Const)
+			if !ok || size.Int64() != 0 {
+				continue // buffered, or size unknown
+			}
+			check(pass, fn, mk)
+		}
+	}
+}
+
+func check(pass *analysis.Pass, fn *ssa.Function, mk *ssa.MakeChan) {
+	var uses chanUses
+	name := "channel"
+
+	// The channel may be used as an SSA register, or spilled to an
+	// alloc if it is captured by the goroutine's closure.
+	values := []ssa.Value{mk}
+	if refs := mk.Referrers(); refs != nil && len(*refs) == 1 {
+		if st, ok := (*refs)[0].(*ssa.Store); ok && st.Val == mk {
+			alloc, ok := st.Addr.(*ssa.Alloc)
+			if !ok {
+				return // stored somewhere odd; escapes
+			}
+			if alloc.Comment != "" {
+				name = alloc.Comment
+			}
+			values = values[:0]
+			for _, ref := range *alloc.Referrers() {
+				switch ref := ref.(type) {
+				case *ssa.Store:
+					if ref != st {
+						return // reassigned; give up
+					}
+				case *ssa.UnOp:
+					if ref.Op == token.MUL {
+						values = append(values, ref) // load
+						continue
+					}
+					return
+				case *ssa.MakeClosure:
+					if !classifyClosure(fn, ref, bindingIndex(ref, alloc), &uses) {
+						return
+					}
+				default:
+					return // alloc address escapes
+				}
+			}
+		}
+	}
+
+	for _, v := range values {
+		refs := v.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, ref := range *refs {
+			switch ref := ref.(type) {
+			case *ssa.Send:
+				if ref.Chan != v {
+					return // channel itself is sent somewhere
+				}
+				uses.parentSends = append(uses.parentSends, ref)
+			case *ssa.UnOp:
+				if ref.Op != token.ARROW {
+					return
+				}
+				uses.parentRecvs = append(uses.parentRecvs, ref)
+			case *ssa.Go:
+				// go f(..., c, ...) with a static callee.
+				callee, _ := ref.Call.Value.(*ssa.Function)
+				if callee == nil {
+					return
+				}
+				i := argIndex(ref, v)
+				if i < 0 || !classifyGoroutine(ref, callee, callee.Params[i], &uses) {
+					return
+				}
+			default:
+				return // call argument, select, store, return, ...
+			}
+		}
+	}
+
+	if uses.goInstr == nil {
+		return // no goroutine involved; not our pattern
+	}
+
+	switch {
+	case uses.goSends > 0 && uses.goRecvs == 0 && len(uses.parentSends) == 0:
+		if leaks(uses.goInstr, uses.parentRecvs) {
+			pass.Reportf(uses.goInstr.Pos(), "goroutine sends to %s, but the function can return without receiving from it: the goroutine leaks", name)
+		}
+	case uses.goRecvs > 0 && uses.goSends == 0 && len(uses.parentRecvs) == 0:
+		if leaks(uses.goInstr, uses.parentSends) {
+			pass.Reportf(uses.goInstr.Pos(), "goroutine receives from %s, but the function can return without sending to it: the goroutine leaks", name)
+		}
+	}
+}
+
+// classifyClosure handles a channel captured by a function literal: the
+// literal must be started, once, as a goroutine. It reports whether the
+// channel's uses remain analyzable.
+func classifyClosure(fn *ssa.Function, mc *ssa.MakeClosure, i int, uses *chanUses) bool {
+	refs := mc.Referrers()
+	if i < 0 || refs == nil || len(*refs) != 1 {
+		return false
+	}
+	g, ok := (*refs)[0].(*ssa.Go)
+	if !ok {
+		return false // deferred or called literal; not a goroutine
+	}
+	callee := mc.Fn.(*ssa.Function)
+	return classifyGoroutine(g, callee, callee.FreeVars[i], uses)
+}
+
+// classifyGoroutine records the sends and receives that the goroutine g
+// performs on the channel held by root (a free variable holding the
+// channel's address, or a parameter holding the channel itself). It
+// reports whether all uses are plain sends and receives.
+func classifyGoroutine(g *ssa.Go, callee *ssa.Function, root ssa.Value, uses *chanUses) bool {
+	if uses.goInstr != nil && uses.goInstr != g {
+		return false // two goroutines share the channel; give up
+	}
+	uses.goInstr = g
+
+	values := []ssa.Value{root}
+	if _, ok := root.(*ssa.FreeVar); ok {
+		values = values[:0]
+		refs := root.Referrers()
+		if refs == nil {
+			return true
+		}
+		for _, ref := range *refs {
+			load, ok := ref.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL {
+				return false
+			}
+			values = append(values, load)
+		}
+	}
+
+	for _, v := range values {
+		refs := v.Referrers()
+		if refs == nil {
+			continue
+		}
+		for _, ref := range *refs {
+			switch ref := ref.(type) {
+			case *ssa.Send:
+				if ref.Chan != v {
+					return false
+				}
+				uses.goSends++
+			case *ssa.UnOp:
+				if ref.Op != token.ARROW {
+					return false
+				}
+				uses.goRecvs++
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// leaks reports whether some path from the go statement to a return
+// avoids every one of the parent's counterpart channel operations.
+func leaks(g *ssa.Go, counters []ssa.Instruction) bool {
+	counter := make(map[*ssa.BasicBlock]bool)
+	for _, c := range counters {
+		counter[c.Block()] = true
+	}
+
+	// Does the block containing the go statement perform a
+	// counterpart operation after it?
+	b := g.Block()
+	after := false
+	for _, instr := range b.Instrs {
+		if instr == g {
+			after = true
+			continue
+		}
+		if !after {
+			continue
+		}
+		for _, c := range counters {
+			if instr == c {
+				return false // covered on every path through this block
+			}
+		}
+		if _, ok := instr.(*ssa.Return); ok {
+			return true
+		}
+	}
+
+	// Search the successors for a return not preceded by a
+	// counterpart operation.
+	seen := make(map[*ssa.BasicBlock]bool)
+	var visit func(b *ssa.BasicBlock) bool
+	visit = func(b *ssa.BasicBlock) bool {
+		if seen[b] {
+			return false
+		}
+		seen[b] = true
+		if counter[b] {
+			return false // path is covered from here on
+		}
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*ssa.Return); ok {
+				return true
+			}
+		}
+		for _, succ := range b.Succs {
+			if visit(succ) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, succ := range b.Succs {
+		if visit(succ) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindingIndex returns the index of v among the closure's bindings, or -1.
+func bindingIndex(mc *ssa.MakeClosure, v ssa.Value) int {
+	for i, b := range mc.Bindings {
+		if b == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// argIndex returns the index of v among the go statement's call
+// arguments, or -1.
+func argIndex(g *ssa.Go, v ssa.Value) int {
+	for i, arg := range g.Call.Args {
+		if arg == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the goroutineleak checker.
+
+package a
+
+func leakOnEarlyReturn(fail bool) int {
+	c := make(chan int)
+	go func() { // want `goroutine sends to c, but the function can return without receiving from it: the goroutine leaks`
+		c <- work()
+	}()
+	if fail {
+		return 0
+	}
+	return <-c
+}
+
+func neverReceives() {
+	c := make(chan int)
+	go func() { // want `goroutine sends to c, but the function can return without receiving from it: the goroutine leaks`
+		c <- work()
+	}()
+}
+
+func receiverLeaks(fail bool) {
+	c := make(chan int)
+	go func() { // want `goroutine receives from c, but the function can return without sending to it: the goroutine leaks`
+		use(<-c)
+	}()
+	if fail {
+		return
+	}
+	c <- work()
+}
+
+func noLeak(fail bool) int {
+	c := make(chan int)
+	go func() {
+		c <- work()
+	}()
+	if fail {
+		return <-c
+	}
+	return <-c
+}
+
+func buffered(fail bool) int {
+	c := make(chan int, 1)
+	go func() {
+		c <- work()
+	}()
+	if fail {
+		return 0
+	}
+	return <-c
+}
+
+func escapes(fail bool) {
+	c := make(chan int)
+	go func() {
+		c <- work()
+	}()
+	if fail {
+		return // no report: the channel is handed to drain
+	}
+	drain(c)
+}
+
+func selectNotAnalyzed(done chan bool, fail bool) {
+	c := make(chan int)
+	go func() {
+		c <- work()
+	}()
+	if fail {
+		return // no report: select is not analyzed
+	}
+	select {
+	case <-c:
+	case <-done:
+	}
+}
+
+func byParameter(fail bool) int {
+	c := make(chan int)
+	go send(c) // want `goroutine sends to channel, but the function can return without receiving from it: the goroutine leaks`
+	if fail {
+		return 0
+	}
+	return <-c
+}
+
+func send(c chan int)  { c <- work() }
+func drain(c chan int) { <-c }
+func work() int        { return 0 }
+func use(int)          {}
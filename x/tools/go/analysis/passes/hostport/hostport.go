@@ -0,0 +1,150 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hostport defines an Analyzer that checks for network
+// addresses constructed with fmt.Sprintf instead of
+// net.JoinHostPort.
+package hostport
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check for addresses built with Sprintf("%s:%d", host, port)
+
+An address constructed with fmt.Sprintf("%s:%d", host, port) or
+"%s:%s" is wrong when host is an IPv6 literal such as "::1", which
+must be bracketed as "[::1]:80". The checker reports such Sprintf
+calls whose result is used as the address of net.Dial, net.Listen,
+and similar functions, and suggests net.JoinHostPort, which inserts
+the brackets when they are needed.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "hostport",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// The functions of the net package whose second argument is a
+// host:port address.
+var addrFuncs = map[string]bool{
+	"net.Dial":           true,
+	"net.DialTimeout":    true,
+	"net.Listen":         true,
+	"net.ResolveTCPAddr": true,
+	"net.ResolveUDPAddr": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// sprintfs records local variables holding the result of a
+	// host:port Sprintf, so that an address built a statement or
+	// two before it is dialed is still recognized.
+	sprintfs := make(map[types.Object]*ast.CallExpr)
+	reported := make(map[*ast.CallExpr]bool)
+
+	report := func(call *ast.CallExpr) {
+		if reported[call] {
+			return
+		}
+		reported[call] = true
+		format, _ := stringConstant(pass, call.Args[0])
+		d := analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("address constructed with fmt.Sprintf(%q) does not handle IPv6 literals; use net.JoinHostPort", format),
+		}
+		// For the %s:%s form, net.JoinHostPort is a direct replacement.
+		if format == "%s:%s" && len(call.Args) == 3 {
+			d.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: "use net.JoinHostPort",
+				TextEdits: []analysis.TextEdit{{
+					Pos: call.Pos(),
+					End: call.End(),
+					NewText: []byte(fmt.Sprintf("net.JoinHostPort(%s, %s)",
+						analysisutil.Format(pass.Fset, call.Args[1]),
+						analysisutil.Format(pass.Fset, call.Args[2]))),
+				}},
+			}}
+		}
+		pass.Report(d)
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, rhs := range n.Rhs {
+				if i >= len(n.Lhs) {
+					break
+				}
+				call, ok := hostPortSprintf(pass, rhs)
+				if !ok {
+					continue
+				}
+				if id, ok := n.Lhs[i].(*ast.Ident); ok {
+					if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+						sprintfs[obj] = call
+					}
+				}
+			}
+
+		case *ast.CallExpr:
+			fn, ok := typeutil.Callee(pass.TypesInfo, n).(*types.Func)
+			if !ok || !addrFuncs[fn.FullName()] || len(n.Args) < 2 {
+				return
+			}
+			addr := n.Args[1]
+			if call, ok := hostPortSprintf(pass, addr); ok {
+				report(call)
+			} else if id, ok := addr.(*ast.Ident); ok {
+				if call := sprintfs[pass.TypesInfo.Uses[id]]; call != nil {
+					report(call)
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+// hostPortSprintf reports whether e is a call of fmt.Sprintf with a
+// host:port format, and returns the call.
+func hostPortSprintf(pass *analysis.Pass, e ast.Expr) (*ast.CallExpr, bool) {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) < 1 {
+		return nil, false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.FullName() != "fmt.Sprintf" {
+		return nil, false
+	}
+	format, ok := stringConstant(pass, call.Args[0])
+	if !ok {
+		return nil, false
+	}
+	return call, format == "%s:%d" || format == "%s:%s"
+}
+
+// stringConstant returns the value of e if it is a constant string.
+func stringConstant(pass *analysis.Pass, e ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
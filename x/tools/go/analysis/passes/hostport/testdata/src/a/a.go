@@ -0,0 +1,28 @@
+// This file contains tests for the hostport checker.
+
+package a
+
+import (
+	"fmt"
+	"net"
+)
+
+func direct(host string, port int, sport string) {
+	net.Dial("tcp", fmt.Sprintf("%s:%d", host, port))   // want `address constructed with fmt.Sprintf\("%s:%d"\) does not handle IPv6 literals; use net.JoinHostPort`
+	net.Dial("tcp", fmt.Sprintf("%s:%s", host, sport))  // want `address constructed with fmt.Sprintf\("%s:%s"\) does not handle IPv6 literals; use net.JoinHostPort`
+	net.Listen("tcp", fmt.Sprintf("%s:%d", host, port)) // want `address constructed with fmt.Sprintf\("%s:%d"\) does not handle IPv6 literals; use net.JoinHostPort`
+
+	net.Dial("tcp", net.JoinHostPort(host, sport))       // ok
+	net.Dial("tcp", fmt.Sprintf("%s on %d", host, port)) // ok: not a host:port format
+}
+
+func viaVariable(host string, port int) {
+	addr := fmt.Sprintf("%s:%d", host, port) // want `address constructed with fmt.Sprintf\("%s:%d"\) does not handle IPv6 literals; use net.JoinHostPort`
+	net.Dial("tcp", addr)
+}
+
+func unused(host string, port int) {
+	// Not reported: the result is never used as a network address.
+	label := fmt.Sprintf("%s:%d", host, port)
+	fmt.Println(label)
+}
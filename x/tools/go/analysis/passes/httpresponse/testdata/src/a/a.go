@@ -83,3 +83,19 @@ func badClientDo() {
 		log.Fatal(err)
 	}
 }
+
+func badHTTPPost() {
+	res, err := http.Post("http://foo.com", "text/plain", nil)
+	defer res.Body.Close() // want "using res before checking for errors"
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func badHTTPPostForm() {
+	res, err := http.PostForm("http://foo.com", nil)
+	defer res.Body.Close() // want "using res before checking for errors"
+	if err != nil {
+		log.Fatal(err)
+	}
+}
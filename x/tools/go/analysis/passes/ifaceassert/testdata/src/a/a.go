@@ -0,0 +1,39 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the ifaceassert checker.
+
+package a
+
+import "io"
+
+type Reader interface {
+	Read() // conflicts with io.Reader.Read
+}
+
+type Closer interface {
+	Close() error
+}
+
+func assertions(r Reader, c Closer, v interface{}) {
+	_ = r.(io.Reader)    // want `^impossible type assertion: no type can implement both a.Reader and io.Reader \(conflicting types for Read method\)$`
+	_, _ = r.(io.Reader) // want `^impossible type assertion: no type can implement both a.Reader and io.Reader \(conflicting types for Read method\)$`
+
+	_ = c.(io.Closer) // no report: signatures agree
+	_ = v.(io.Reader) // no report: empty interface can hold any type
+	_ = r.(Closer)    // no report: method sets do not overlap
+}
+
+func typeSwitch(r Reader) {
+	switch r.(type) {
+	case io.Reader: // want `^impossible type assertion: no type can implement both a.Reader and io.Reader \(conflicting types for Read method\)$`
+	case io.Closer:
+	}
+
+	switch v := r.(type) {
+	case io.ReadCloser: // want `^impossible type assertion: no type can implement both a.Reader and io.ReadCloser \(conflicting types for Read method\)$`
+		_ = v
+	case interface{}:
+	}
+}
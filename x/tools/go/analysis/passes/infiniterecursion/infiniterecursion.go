@@ -0,0 +1,265 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package infiniterecursion defines an Analyzer that reports
+// functions that unconditionally call themselves.
+package infiniterecursion
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `report functions that unconditionally call themselves
+
+A function whose every path to a return statement passes through a
+call to itself with unchanged arguments can never terminate; the
+mistake otherwise surfaces only as a stack overflow at runtime. The
+check also reports the simplest form of mutual recursion, in which
+two functions of the same package unconditionally call one another.
+
+Paths that end in a call to a function that never returns, such as
+panic, count as a way out and suppress the report, as does any
+assignment to a parameter before the recursive call.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "infiniterecursion",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
+	Run:      run,
+}
+
+// funcInfo records the recursion-relevant calls of one declared function.
+type funcInfo struct {
+	decl  *ast.FuncDecl
+	fn    *types.Func
+	graph *cfg.CFG
+
+	// Static calls in live blocks whose arguments are the caller's
+	// own parameters, unchanged and in declaration order.
+	callees    []*types.Func // keys of the maps below, in source order
+	callBlocks map[*types.Func]map[*cfg.Block]bool
+	callSites  map[*types.Func][]*ast.CallExpr
+
+	// A parameter (or the receiver) is reassigned or has its
+	// address taken, so "unchanged arguments" cannot be assumed.
+	modifiesParams bool
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+
+	// Gather the qualifying call sites of each declared function.
+	infos := make(map[*types.Func]*funcInfo)
+	var order []*funcInfo
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		decl := n.(*ast.FuncDecl)
+		if decl.Body == nil {
+			return
+		}
+		fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			return
+		}
+		info := &funcInfo{decl: decl, fn: fn, graph: cfgs.FuncDecl(decl)}
+		info.findCalls(pass)
+		infos[fn] = info
+		order = append(order, info)
+	})
+
+	for _, info := range order {
+		if info.modifiesParams {
+			continue
+		}
+		for _, callee := range info.callees {
+			if callee == info.fn {
+				// Direct recursion.
+				if allPathsCall(info.graph, info.callBlocks[callee]) {
+					for _, call := range info.callSites[callee] {
+						pass.ReportRangef(call, "infinite recursion: %s always calls itself with unchanged arguments", info.fn.Name())
+					}
+				}
+				continue
+			}
+
+			// Mutual recursion: f always calls g, and g always
+			// calls f back. Both directions pass each function's
+			// parameters through unchanged, so the cycle repeats
+			// with the original arguments. Each function reports
+			// its own call sites.
+			other, ok := infos[callee]
+			if !ok || other.modifiesParams {
+				continue
+			}
+			if allPathsCall(info.graph, info.callBlocks[callee]) &&
+				allPathsCall(other.graph, other.callBlocks[info.fn]) {
+				for _, call := range info.callSites[callee] {
+					pass.ReportRangef(call, "infinite recursion: %s and %s always call each other with unchanged arguments", info.fn.Name(), callee.Name())
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findCalls records the static calls of info's function whose
+// arguments are its own parameters, unchanged, and whether any
+// parameter may be modified.
+func (info *funcInfo) findCalls(pass *analysis.Pass) {
+	// Collect the parameter and receiver objects.
+	sig := info.fn.Type().(*types.Signature)
+	params := make(map[types.Object]bool)
+	for i := 0; i < sig.Params().Len(); i++ {
+		params[sig.Params().At(i)] = true
+	}
+	if recv := sig.Recv(); recv != nil {
+		params[recv] = true
+	}
+	isParam := func(e ast.Expr) bool {
+		id, ok := e.(*ast.Ident)
+		return ok && params[pass.TypesInfo.Uses[id]]
+	}
+
+	// Does the body (including function literals, which may capture
+	// a parameter) reassign a parameter or take its address?
+	ast.Inspect(info.decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if isParam(lhs) {
+					info.modifiesParams = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if isParam(n.X) {
+				info.modifiesParams = true
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.AND && isParam(n.X) {
+				info.modifiesParams = true
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN && (isParam(n.Key) || isParam(n.Value)) {
+				info.modifiesParams = true
+			}
+		}
+		return true
+	})
+	if info.modifiesParams {
+		return // the call sites won't be used
+	}
+
+	// Record qualifying calls, block by block, so that the path
+	// analysis can tell which blocks recurse. The calls of go and
+	// defer statements do not grow the stack on this path, and a
+	// function literal has its own control flow, so both are
+	// exempt; their argument expressions are still examined.
+	info.callBlocks = make(map[*types.Func]map[*cfg.Block]bool)
+	info.callSites = make(map[*types.Func][]*ast.CallExpr)
+	goDefer := make(map[*ast.CallExpr]bool)
+	for _, b := range info.graph.Blocks {
+		if !b.Live {
+			continue
+		}
+		for _, n := range b.Nodes {
+			ast.Inspect(n, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.GoStmt:
+					goDefer[n.Call] = true
+				case *ast.DeferStmt:
+					goDefer[n.Call] = true
+				case *ast.CallExpr:
+					if goDefer[n] {
+						break
+					}
+					callee := typeutil.StaticCallee(pass.TypesInfo, n)
+					if callee == nil || !unchangedArgs(pass, n, sig) {
+						break
+					}
+					if info.callBlocks[callee] == nil {
+						info.callees = append(info.callees, callee)
+						info.callBlocks[callee] = make(map[*cfg.Block]bool)
+					}
+					info.callBlocks[callee][b] = true
+					info.callSites[callee] = append(info.callSites[callee], n)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// unchangedArgs reports whether the call passes the calling
+// function's own parameters, in declaration order, so that the callee
+// runs with exactly the caller's arguments. For a method call, the
+// receiver must likewise be the caller's receiver.
+func unchangedArgs(pass *analysis.Pass, call *ast.CallExpr, caller *types.Signature) bool {
+	if call.Ellipsis.IsValid() != caller.Variadic() {
+		return false
+	}
+	if len(call.Args) != caller.Params().Len() {
+		return false
+	}
+	for i, arg := range call.Args {
+		id, ok := arg.(*ast.Ident)
+		if !ok || pass.TypesInfo.Uses[id] != caller.Params().At(i) {
+			return false
+		}
+	}
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok && pass.TypesInfo.Selections[sel] != nil {
+		// Method call: the receiver argument must be the
+		// caller's receiver.
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || caller.Recv() == nil || pass.TypesInfo.Uses[id] != caller.Recv() {
+			return false
+		}
+	}
+	return true
+}
+
+// allPathsCall reports whether every path from the entry of the graph
+// to a return statement passes through one of the given blocks before
+// its end. A reachable path that falls off the end of a block with no
+// successors — a call to panic or another function that never
+// returns — counts as a way out.
+func allPathsCall(g *cfg.CFG, hasCall map[*cfg.Block]bool) bool {
+	if len(hasCall) == 0 {
+		return false
+	}
+	seen := make([]bool, len(g.Blocks))
+	var escapes func(b *cfg.Block) bool
+	escapes = func(b *cfg.Block) bool {
+		if seen[b.Index] {
+			return false
+		}
+		seen[b.Index] = true
+		if hasCall[b] {
+			return false // recursion happens before this block ends
+		}
+		if b.Return() != nil || len(b.Succs) == 0 {
+			return true
+		}
+		for _, succ := range b.Succs {
+			if escapes(succ) {
+				return true
+			}
+		}
+		return false
+	}
+	return !escapes(g.Blocks[0])
+}
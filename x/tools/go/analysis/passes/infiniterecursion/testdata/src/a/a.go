@@ -0,0 +1,102 @@
+// This file contains tests for the infiniterecursion checker.
+
+package a
+
+func loops() {
+	loops() // want `infinite recursion: loops always calls itself with unchanged arguments`
+}
+
+func loopsValue(x int) int {
+	return loopsValue(x) // want `infinite recursion: loopsValue always calls itself with unchanged arguments`
+}
+
+func loopsVariadic(xs ...int) {
+	loopsVariadic(xs...) // want `infinite recursion: loopsVariadic always calls itself with unchanged arguments`
+}
+
+func inLoop() {
+	for {
+		inLoop() // want `infinite recursion: inLoop always calls itself with unchanged arguments`
+	}
+}
+
+type T struct{ next *T }
+
+func (t *T) spin() {
+	t.spin() // want `infinite recursion: spin always calls itself with unchanged arguments`
+}
+
+// A base case on some path is a way out.
+func countdown(x int) {
+	if x > 0 {
+		countdown(x - 1)
+	}
+}
+
+// The argument changes, so the recursion may bottom out.
+func walk(t *T) {
+	walk(t.next)
+}
+
+// One path returns without recursing.
+func escape(x int) int {
+	if x == 0 {
+		return 0
+	}
+	return escape(x)
+}
+
+// A path ending in panic counts as a way out.
+func mustPanic(x int) {
+	if x < 0 {
+		panic("negative")
+	}
+	mustPanic(x)
+}
+
+// The parameter is reassigned before the call.
+func decrements(x int) {
+	x--
+	decrements(x)
+}
+
+// A go statement does not grow this goroutine's stack.
+func spawns() {
+	go spawns()
+}
+
+// A deferred call runs only once the frame returns.
+func defers() {
+	defer defers()
+}
+
+// Recursion by way of a function literal is dynamic and
+// is not detected.
+func viaLit() {
+	f := func(g func()) { g() }
+	f(func() { viaLit() })
+}
+
+// Mutual recursion: each function unconditionally calls the other.
+func ping(x int) {
+	pong(x) // want `infinite recursion: ping and pong always call each other with unchanged arguments`
+}
+
+func pong(x int) {
+	ping(x) // want `infinite recursion: pong and ping always call each other with unchanged arguments`
+}
+
+// Mutual recursion with a base case is not reported.
+func even(x int) bool {
+	if x == 0 {
+		return true
+	}
+	return odd(x - 1)
+}
+
+func odd(x int) bool {
+	if x == 0 {
+		return false
+	}
+	return even(x - 1)
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protoutil defines helpers for recognizing generated
+// protocol buffer message types, shared by the passes that check
+// their use.
+package protoutil
+
+import "go/types"
+
+// IsMessage reports whether t is (or is a pointer to) a generated
+// protocol buffer message type: a named struct type whose pointer
+// method set includes either the ProtoReflect method of the v2 API
+// or the Reset, String, and ProtoMessage methods of the v1
+// proto.Message interface.
+func IsMessage(t types.Type) bool {
+	named, ok := deref(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	ms := types.NewMethodSet(types.NewPointer(named))
+	if hasMethod(ms, "ProtoReflect") {
+		return true
+	}
+	return hasMethod(ms, "Reset") && hasMethod(ms, "String") && hasMethod(ms, "ProtoMessage")
+}
+
+// deref returns the type of the variable pointed to by t,
+// if t's underlying type is a pointer; otherwise it returns t.
+func deref(t types.Type) types.Type {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func hasMethod(ms *types.MethodSet, name string) bool {
+	for i := 0; i < ms.Len(); i++ {
+		if ms.At(i).Obj().Name() == name {
+			return true
+		}
+	}
+	return false
+}
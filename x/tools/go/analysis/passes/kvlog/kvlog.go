@@ -0,0 +1,210 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kvlog defines an Analyzer that checks the key-value argument
+// lists of structured logging calls.
+package kvlog
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check key-value argument lists of structured logging calls
+
+Structured logging functions in the style of log/slog accept a variadic
+list of alternating keys and values. This checker reports calls with an
+odd number of such arguments (a key missing its value), keys that are
+neither strings nor slog.Attr values, and keys duplicated within a
+single call.
+
+A function whose own variadic arguments are forwarded to a structured
+logging call is recognized as a logging wrapper, recorded with a fact,
+and its callers are checked in the same way.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "kvlog",
+	Doc:       Doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(isKVLogger)},
+	Run:       run,
+}
+
+// isKVLogger is a fact recording that a function's variadic parameter
+// holds alternating logging keys and values.
+type isKVLogger struct{}
+
+func (*isKVLogger) AFact()         {}
+func (*isKVLogger) String() string { return "kvLogger" }
+
+// kvFuncs lists the log/slog functions and methods whose trailing
+// variadic arguments are key-value pairs.
+var kvFuncs = map[string]bool{
+	"Debug":        true,
+	"DebugContext": true,
+	"Info":         true,
+	"InfoContext":  true,
+	"Warn":         true,
+	"WarnContext":  true,
+	"Error":        true,
+	"ErrorContext": true,
+	"Log":          true,
+	"With":         true,
+	"Group":        true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	// Find wrappers that forward their variadic parameter to a
+	// key-value logging call, iterating until a fixed point so
+	// that wrappers of wrappers are found regardless of order.
+	for {
+		found := false
+		for _, f := range pass.Files {
+			for _, decl := range f.Decls {
+				decl, ok := decl.(*ast.FuncDecl)
+				if !ok || decl.Body == nil {
+					continue
+				}
+				fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+				if !ok || isKVFunc(pass, fn) {
+					continue
+				}
+				if forwardsKV(pass, decl, fn) {
+					pass.ExportObjectFact(fn, new(isKVLogger))
+					found = true
+				}
+			}
+		}
+		if !found {
+			break
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		if fn == nil || !isKVFunc(pass, fn) {
+			return
+		}
+		checkCall(pass, call, fn)
+	})
+	return nil, nil
+}
+
+// isKVFunc reports whether fn's variadic arguments are logging
+// key-value pairs: either a known log/slog function or a function
+// recorded as a wrapper.
+func isKVFunc(pass *analysis.Pass, fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !sig.Variadic() {
+		return false
+	}
+	if fn.Pkg() != nil && fn.Pkg().Path() == "log/slog" && kvFuncs[fn.Name()] {
+		return true
+	}
+	return pass.ImportObjectFact(fn, new(isKVLogger))
+}
+
+// forwardsKV reports whether decl forwards its own variadic parameter,
+// with an ellipsis, as the key-value arguments of a logging call.
+func forwardsKV(pass *analysis.Pass, decl *ast.FuncDecl, fn *types.Func) bool {
+	sig := fn.Type().(*types.Signature)
+	if !sig.Variadic() {
+		return false
+	}
+	vparam := sig.Params().At(sig.Params().Len() - 1)
+
+	forwards := false
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !call.Ellipsis.IsValid() || len(call.Args) == 0 {
+			return true
+		}
+		callee := typeutil.StaticCallee(pass.TypesInfo, call)
+		if callee == nil || !isKVFunc(pass, callee) {
+			return true
+		}
+		if id, ok := call.Args[len(call.Args)-1].(*ast.Ident); ok &&
+			pass.TypesInfo.ObjectOf(id) == vparam {
+			forwards = true
+			return false
+		}
+		return true
+	})
+	return forwards
+}
+
+func checkCall(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func) {
+	if call.Ellipsis.IsValid() {
+		return // argument list not known statically
+	}
+	sig := fn.Type().(*types.Signature)
+	kvStart := sig.Params().Len() - 1
+	if len(call.Args) < kvStart {
+		return
+	}
+	args := call.Args[kvStart:]
+
+	seen := make(map[string]bool)
+	i := 0
+	for i < len(args) {
+		key := args[i]
+		t := pass.TypesInfo.TypeOf(key)
+		if isAttr(t) {
+			i++
+			continue
+		}
+		if isInterface(t) {
+			return // cannot tell whether this is a key or an Attr
+		}
+		if !isString(t) {
+			pass.Reportf(key.Pos(), "%s arg %s should be a string or a slog.Attr",
+				fn.Name(), analysisutil.Format(pass.Fset, key))
+			i += 2
+			continue
+		}
+		if tv, ok := pass.TypesInfo.Types[key]; ok && tv.Value != nil {
+			k := constant.StringVal(tv.Value)
+			if seen[k] {
+				pass.Reportf(key.Pos(), "duplicate key %q in call to %s", k, fn.Name())
+			}
+			seen[k] = true
+		}
+		if i == len(args)-1 {
+			pass.Reportf(key.Pos(), "call to %s missing a final value", fn.Name())
+			return
+		}
+		i += 2
+	}
+}
+
+func isAttr(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "Attr" && obj.Pkg() != nil && obj.Pkg().Path() == "log/slog"
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+func isInterface(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Interface)
+	return ok
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the kvlog checker.
+
+package a
+
+import "log/slog"
+
+func direct() {
+	slog.Info("m", "k", 1)                    // ok
+	slog.Info("m", "k", 1, "l", 2)            // ok
+	slog.Info("m", slog.Int("k", 1), "l", 2)  // ok: Attr consumes one argument
+	slog.Info("m", "k")                       // want `call to Info missing a final value`
+	slog.Info("m", "k", 1, "l")               // want `call to Info missing a final value`
+	slog.Info("m", 42, 1)                     // want `Info arg 42 should be a string or a slog.Attr`
+	slog.Info("m", "k", 1, "k", 2)            // want `duplicate key "k" in call to Info`
+	slog.Warn("m", "k", 1, "l", 2, "k", 3)    // want `duplicate key "k" in call to Warn`
+	slog.Default().Error("m", "k")            // want `call to Error missing a final value`
+	slog.With("k", 1, "k", 2)                 // want `duplicate key "k" in call to With`
+	slog.Log(nil, slog.LevelInfo, "m", "k")   // want `call to Log missing a final value`
+	slog.Info("m", "k", 1, "l", 2, "m", "k")  // ok: "m" and "k" are key and value here
+	slog.Error("m", "err", errorValue, "err") // want `duplicate key "err" in call to Error` `call to Error missing a final value`
+}
+
+var errorValue error
+
+func unknown(kv []interface{}, v interface{}) {
+	slog.Info("m", kv...)    // ok: argument list not known statically
+	slog.Info("m", v, "x")   // ok: v may be a slog.Attr
+	slog.Info("m", "k", "k") // ok: second "k" is a value
+}
+
+// logInfo forwards its variadic arguments to slog.Info, so its callers
+// are checked too.
+func logInfo(msg string, kv ...interface{}) { // want logInfo:"kvLogger"
+	slog.Info(msg, kv...)
+}
+
+// logDeep wraps the wrapper.
+func logDeep(msg string, kv ...interface{}) { // want logDeep:"kvLogger"
+	logInfo(msg, kv...)
+}
+
+func useWrappers() {
+	logInfo("m", "k", 1)    // ok
+	logInfo("m", "k")       // want `call to logInfo missing a final value`
+	logDeep("m", 7, 1)      // want `logDeep arg 7 should be a string or a slog.Attr`
+	logDeep("m", "k", 1)    // ok
+	logInfo("m", "k", "k2") // ok
+}
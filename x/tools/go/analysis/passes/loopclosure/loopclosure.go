@@ -8,6 +8,7 @@ package loopclosure
 
 import (
 	"go/ast"
+	"go/types"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
@@ -28,9 +29,10 @@ const Doc = `check references to loop variables from within nested functions
 
 This analyzer checks for references to loop variables from within a
 function literal inside the loop body. It checks only instances where
-the function literal is called in a defer or go statement that is the
-last statement in the loop body, as otherwise we would need whole
-program analysis.
+the function literal is started asynchronously by the last statement
+in the loop body—called in a defer or go statement, passed to
+(golang.org/x/sync/errgroup.Group).Go, or run as a parallel subtest by
+(*testing.T).Run—as otherwise we would need whole program analysis.
 
 For example:
 
@@ -49,6 +51,14 @@ var Analyzer = &analysis.Analyzer{
 	Run:      run,
 }
 
+// flags
+var checkParallelSubtests = true
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkParallelSubtests, "parallelsubtests", checkParallelSubtests,
+		"also check subtests started by (*testing.T).Run that call t.Parallel")
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
@@ -87,25 +97,26 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return
 		}
 
-		// Inspect a go or defer statement
-		// if it's the last one in the loop body.
-		// (We give up if there are following statements,
+		// Inspect statements that start a function literal
+		// asynchronously, if the statement is the last one in the
+		// loop body. (We give up if there are following statements,
 		// because it's hard to prove go isn't followed by wait,
 		// or defer by return.)
 		if len(body.List) == 0 {
 			return
 		}
-		var last *ast.CallExpr
+		var lit *ast.FuncLit
 		switch s := body.List[len(body.List)-1].(type) {
 		case *ast.GoStmt:
-			last = s.Call
+			lit, _ = s.Call.Fun.(*ast.FuncLit)
 		case *ast.DeferStmt:
-			last = s.Call
-		default:
-			return
+			lit, _ = s.Call.Fun.(*ast.FuncLit)
+		case *ast.ExprStmt:
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				lit = asyncFuncLit(pass, call)
+			}
 		}
-		lit, ok := last.Fun.(*ast.FuncLit)
-		if !ok {
+		if lit == nil {
 			return
 		}
 		ast.Inspect(lit.Body, func(n ast.Node) bool {
@@ -128,3 +139,68 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	})
 	return nil, nil
 }
+
+// asyncFuncLit returns the function literal started asynchronously by
+// call, if any. It recognizes (golang.org/x/sync/errgroup.Group).Go,
+// whose argument runs on a new goroutine, and, unless disabled by the
+// -parallelsubtests flag, (*testing.T).Run with a subtest that calls
+// t.Parallel and therefore returns before the subtest body completes.
+func asyncFuncLit(pass *analysis.Pass, call *ast.CallExpr) *ast.FuncLit {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok {
+		return nil
+	}
+	if isMethod(fn, "golang.org/x/sync/errgroup", "Group", "Go") && len(call.Args) == 1 {
+		lit, _ := call.Args[0].(*ast.FuncLit)
+		return lit
+	}
+	if checkParallelSubtests && isMethod(fn, "testing", "T", "Run") && len(call.Args) == 2 {
+		if lit, ok := call.Args[1].(*ast.FuncLit); ok && callsParallel(pass, lit) {
+			return lit
+		}
+	}
+	return nil
+}
+
+// isMethod reports whether fn is the method recv.name declared in the
+// package with the given path.
+func isMethod(fn *types.Func, path, recv, name string) bool {
+	if fn.Name() != name {
+		return false
+	}
+	r := fn.Type().(*types.Signature).Recv()
+	if r == nil {
+		return false
+	}
+	t := r.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == recv && obj.Pkg() != nil && obj.Pkg().Path() == path
+}
+
+// callsParallel reports whether the subtest body calls (*testing.T).Parallel.
+func callsParallel(pass *analysis.Pass, lit *ast.FuncLit) bool {
+	found := false
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+				if fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func); ok &&
+					isMethod(fn, "testing", "T", "Parallel") {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
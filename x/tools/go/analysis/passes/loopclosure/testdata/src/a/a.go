@@ -6,6 +6,12 @@
 
 package testdata
 
+import (
+	"testing"
+
+	"golang.org/x/sync/errgroup"
+)
+
 func _() {
 	var s []int
 	for i, v := range s {
@@ -88,3 +94,32 @@ func _() {
 		}()
 	}
 }
+
+// Cases that rely on recognizing calls that start the function
+// literal asynchronously.
+func _(list []int) {
+	var group errgroup.Group
+	for _, v := range list {
+		group.Go(func() error {
+			print(v) // want "loop variable v captured by func literal"
+			return nil
+		})
+	}
+}
+
+func _(t *testing.T, list []int) {
+	for _, v := range list {
+		t.Run("subtest", func(t *testing.T) {
+			t.Parallel()
+			print(v) // want "loop variable v captured by func literal"
+		})
+	}
+
+	// Without a call to t.Parallel, Run does not return until the
+	// subtest has completed, so there is nothing to report.
+	for _, v := range list {
+		t.Run("subtest", func(t *testing.T) {
+			print(v)
+		})
+	}
+}
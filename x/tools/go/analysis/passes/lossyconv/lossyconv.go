@@ -0,0 +1,148 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lossyconv defines an Analyzer that checks for integer
+// conversions that may silently truncate or change sign.
+package lossyconv
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check for lossy integer conversions
+
+A conversion between integer types whose destination cannot represent
+every value of the source, such as int64 to int32 or int to uint16,
+silently truncates or changes the sign of out-of-range values.
+This checker reports such conversions unless the operand is a
+constant, which the compiler already checks.
+
+The -untrusted flag restricts reporting to conversions of values
+derived from untrusted inputs — the results of len and cap, and
+values decoded by encoding/binary — where an attacker may control
+the out-of-range value.`
+
+var untrusted = false
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "lossyconv",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&untrusted, "untrusted", untrusted,
+		"report only conversions of values derived from untrusted inputs (len, cap, encoding/binary)")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if len(call.Args) != 1 {
+			return
+		}
+		tv, ok := pass.TypesInfo.Types[call.Fun]
+		if !ok || !tv.IsType() {
+			return // not a conversion
+		}
+		arg := call.Args[0]
+		if v, ok := pass.TypesInfo.Types[arg]; ok && v.Value != nil {
+			return // constant, range-checked by the compiler
+		}
+		dstType := tv.Type
+		srcType := pass.TypesInfo.TypeOf(arg)
+		dst := basicInt(dstType)
+		src := basicInt(srcType)
+		if dst == nil || src == nil {
+			return
+		}
+		reason := lossy(pass.TypesSizes, src, dst)
+		if reason == "" {
+			return
+		}
+		if untrusted && !isUntrusted(pass, arg) {
+			return
+		}
+		pass.ReportRangef(call, "conversion from %s to %s may %s", srcType, dstType, reason)
+	})
+	return nil, nil
+}
+
+// lossy returns a description of how a conversion from src to dst
+// can lose information ("truncate", "change sign", or "truncate or
+// change sign"), or "" if every source value is representable.
+func lossy(sizes types.Sizes, src, dst *types.Basic) string {
+	srcBits := sizes.Sizeof(src) * 8
+	dstBits := sizes.Sizeof(dst) * 8
+	srcSigned := src.Info()&types.IsUnsigned == 0
+	dstSigned := dst.Info()&types.IsUnsigned == 0
+
+	truncates := dstBits < srcBits ||
+		// An unsigned source needs one extra bit in a signed destination.
+		(dstBits == srcBits && dstSigned && !srcSigned)
+	changesSign := srcSigned && !dstSigned
+
+	switch {
+	case truncates && changesSign:
+		return "truncate or change sign"
+	case truncates:
+		return "truncate"
+	case changesSign:
+		return "change sign"
+	}
+	return ""
+}
+
+// basicInt returns the underlying basic integer type of t, or nil if
+// t is not a fixed-size or platform-size integer type.
+func basicInt(t types.Type) *types.Basic {
+	if t == nil {
+		return nil
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || b.Info()&types.IsInteger == 0 || b.Info()&types.IsUntyped != 0 {
+		return nil
+	}
+	return b
+}
+
+// isUntrusted reports whether the expression derives from an
+// untrusted input: it mentions a len or cap call, or a call into
+// encoding/binary.
+func isUntrusted(pass *analysis.Pass, e ast.Expr) bool {
+	found := false
+	ast.Inspect(e, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := call.Fun.(*ast.Ident); ok {
+			if b, ok := pass.TypesInfo.Uses[id].(*types.Builtin); ok &&
+				(b.Name() == "len" || b.Name() == "cap") {
+				found = true
+				return false
+			}
+		}
+		if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok {
+			if pkg := fn.Pkg(); pkg != nil && pkg.Path() == "encoding/binary" {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
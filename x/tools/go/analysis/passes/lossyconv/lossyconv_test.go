@@ -0,0 +1,26 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lossyconv_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/lossyconv"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, lossyconv.Analyzer, "a")
+}
+
+func TestUntrusted(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := lossyconv.Analyzer.Flags.Set("untrusted", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer lossyconv.Analyzer.Flags.Set("untrusted", "false")
+	analysistest.Run(t, testdata, lossyconv.Analyzer, "b")
+}
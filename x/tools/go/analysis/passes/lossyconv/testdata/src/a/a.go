@@ -0,0 +1,20 @@
+// This file contains tests for the lossyconv checker.
+
+package a
+
+type myInt32 int32
+
+func conversions(i int, i64 int64, i32 int32, u16 uint16, u64 uint64) {
+	_ = int32(i64)   // want `conversion from int64 to int32 may truncate`
+	_ = uint16(i)    // want `conversion from int to uint16 may truncate or change sign`
+	_ = uint64(i64)  // want `conversion from int64 to uint64 may change sign`
+	_ = int64(u64)   // want `conversion from uint64 to int64 may truncate`
+	_ = myInt32(i64) // want `conversion from int64 to a.myInt32 may truncate`
+
+	_ = int64(i32)       // ok: widening, same sign
+	_ = int64(u16)       // ok: signed destination is wider
+	_ = uint64(u16)      // ok: widening, same sign
+	_ = int32(i32)       // ok: identity
+	_ = int64(1<<40 + 1) // ok: constant, checked by the compiler
+	_ = float64(i64)     // ok: not an integer conversion
+}
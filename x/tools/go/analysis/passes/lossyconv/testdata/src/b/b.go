@@ -0,0 +1,14 @@
+// This file contains tests for the lossyconv checker in -untrusted mode.
+
+package b
+
+import "encoding/binary"
+
+func conversions(i64 int64, buf []byte) {
+	_ = int32(i64) // ok in -untrusted mode: not derived from input
+
+	_ = uint16(len(buf))                     // want `conversion from int to uint16 may truncate or change sign`
+	_ = int8(cap(buf))                       // want `conversion from int to int8 may truncate`
+	_ = int32(binary.BigEndian.Uint64(buf))  // want `conversion from uint64 to int32 may truncate`
+	_ = uint32(binary.BigEndian.Uint64(buf)) // want `conversion from uint64 to uint32 may truncate`
+}
@@ -21,8 +21,8 @@ import (
 const Doc = `check cancel func returned by context.WithCancel is called
 
 The cancelation function returned by context.WithCancel, WithTimeout,
-and WithDeadline must be called or the new context will remain live
-until its parent context is cancelled.
+WithDeadline and WithCancelCause must be called or the new context
+will remain live until its parent context is cancelled.
 (The background context is never cancelled.)`
 
 var Analyzer = &analysis.Analyzer{
@@ -171,11 +171,11 @@ func hasImport(pkg *types.Package, path string) bool {
 }
 
 // isContextWithCancel reports whether n is one of the qualified identifiers
-// context.With{Cancel,Timeout,Deadline}.
+// context.With{Cancel,Timeout,Deadline,CancelCause}.
 func isContextWithCancel(info *types.Info, n ast.Node) bool {
 	if sel, ok := n.(*ast.SelectorExpr); ok {
 		switch sel.Sel.Name {
-		case "WithCancel", "WithTimeout", "WithDeadline":
+		case "WithCancel", "WithTimeout", "WithDeadline", "WithCancelCause":
 			if x, ok := sel.X.(*ast.Ident); ok {
 				if pkgname, ok := info.Uses[x].(*types.PkgName); ok {
 					return pkgname.Imported().Path() == contextPackage
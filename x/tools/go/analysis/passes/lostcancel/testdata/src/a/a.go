@@ -42,6 +42,7 @@ func _() {
 	ctx, _ := context.WithCancel(bg)               // want "the cancel function returned by context.WithCancel should be called, not discarded, to avoid a context leak"
 	ctx, _ = context.WithTimeout(bg, 0)            // want "the cancel function returned by context.WithTimeout should be called, not discarded, to avoid a context leak"
 	ctx, _ = context.WithDeadline(bg, time.Time{}) // want "the cancel function returned by context.WithDeadline should be called, not discarded, to avoid a context leak"
+	ctx, _ = context.WithCancelCause(bg)           // want "the cancel function returned by context.WithCancelCause should be called, not discarded, to avoid a context leak"
 	_ = ctx
 }
 
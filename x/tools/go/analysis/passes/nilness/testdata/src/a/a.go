@@ -0,0 +1,93 @@
+package a
+
+type X struct{ f, g int }
+
+func f(x, y *X) {
+	if x == nil {
+		print(x.f) // want "nil dereference in field selection"
+	}
+	if x == nil {
+		print(1)
+	} else {
+		print(x.f) // no error: x is non-nil here
+	}
+	if y != nil {
+		print(y.g) // no error: y is non-nil here
+	}
+	print(*x) // no error: the state of x is unknown
+}
+
+func f2(ptr *[3]int, i interface{}) {
+	if ptr != nil {
+		print(ptr[:])
+		*ptr = [3]int{}
+		print(*ptr)
+	} else {
+		print(ptr[:]) // want "nil dereference in slice operation"
+		*ptr = [3]int{} // want "nil dereference in store"
+		print(*ptr) // want "nil dereference in load"
+
+		if ptr != nil { // want "impossible condition: nil != nil"
+			// Dominated by ptr==nil and ptr!=nil,
+			// this block is unreachable.
+			// We do not report errors within it.
+			print(*ptr)
+		}
+	}
+
+	if i != nil {
+		print(i.(interface{ f() }))
+	} else {
+		print(i.(interface{ f() })) // want "nil dereference in type assertion"
+	}
+}
+
+func g() error
+
+func f3() error {
+	err := g()
+	if err != nil {
+		return err
+	}
+
+	if err != nil && err.Error() == "foo" { // want "impossible condition: nil != nil"
+		return err
+	}
+	return nil
+}
+
+func h(err error, b bool) {
+	if err != nil && b {
+		return
+	} else if err != nil {
+		panic(err)
+	}
+}
+
+func i(*X) error {
+	panic(3)
+}
+
+func f4(x *X) {
+	if x == nil {
+		panic(x)
+	}
+}
+
+func f5(m map[string]*X) {
+	var x *X
+	if x == nil { // want "tautological condition: nil == nil"
+		print(0)
+	}
+	x = m["a"]
+	if x == nil {
+		x = &X{}
+	}
+	print(x.f) // no error: x is non-nil here
+}
+
+func f6(fn func() int) {
+	if fn == nil {
+		print(fn()) // want "nil dereference in dynamic function call"
+	}
+}
@@ -278,6 +278,9 @@ func checkPrintfFwd(pass *analysis.Pass, w *printfWrapper, call *ast.CallExpr, k
 //
 // The -funcs flag adds to this set.
 var isPrint = stringSet{
+	"fmt.Append":   true,
+	"fmt.Appendf":  true,
+	"fmt.Appendln": true,
 	"fmt.Errorf":   true,
 	"fmt.Fprint":   true,
 	"fmt.Fprintf":  true,
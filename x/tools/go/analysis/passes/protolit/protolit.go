@@ -0,0 +1,126 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protolit defines an Analyzer that checks for fragile
+// construction of protobuf messages: unkeyed composite literals, and
+// writes to the internal XXX_ fields of generated message types.
+package protolit
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/protoutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check for fragile construction of protobuf messages
+
+Generated protobuf message structs gain, lose, and reorder fields
+whenever their .proto file changes and the code is regenerated.
+A positional (unkeyed) composite literal of a message type then
+silently assigns values to the wrong fields, or fails to compile.
+Likewise, the XXX_ fields of a generated struct are internal book-
+keeping whose layout is not part of the generated API. This checker
+reports both patterns, and suggests the keyed form of a literal
+where the field names are known.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "protolit",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.CompositeLit)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			checkXXXWrite(pass, n)
+		case *ast.CompositeLit:
+			checkLiteral(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+// checkLiteral checks a composite literal of a message type for
+// unkeyed elements and for keys naming internal XXX_ fields.
+func checkLiteral(pass *analysis.Pass, cl *ast.CompositeLit) {
+	typ := pass.TypesInfo.Types[cl].Type
+	if typ == nil || !protoutil.IsMessage(typ) {
+		return
+	}
+	strct, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return
+	}
+
+	// Gather the edits needed to key each unkeyed element.
+	unkeyed := false
+	suggestedFixAvailable := len(cl.Elts) == strct.NumFields()
+	var missingKeys []analysis.TextEdit
+	for i, e := range cl.Elts {
+		kv, ok := e.(*ast.KeyValueExpr)
+		if !ok {
+			unkeyed = true
+			if i >= strct.NumFields() {
+				break
+			}
+			field := strct.Field(i)
+			if !field.Exported() {
+				suggestedFixAvailable = false
+				break
+			}
+			missingKeys = append(missingKeys, analysis.TextEdit{
+				Pos:     e.Pos(),
+				End:     e.Pos(),
+				NewText: []byte(field.Name() + ": "),
+			})
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && strings.HasPrefix(key.Name, "XXX_") {
+			pass.ReportRangef(kv, "composite literal sets internal field %s of protobuf message", key.Name)
+		}
+	}
+	if !unkeyed {
+		return
+	}
+
+	d := analysis.Diagnostic{
+		Pos:     cl.Pos(),
+		End:     cl.End(),
+		Message: typ.String() + " composite literal uses unkeyed fields; they break when the message is regenerated",
+	}
+	if suggestedFixAvailable {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Add field names to struct literal",
+			TextEdits: missingKeys,
+		}}
+	}
+	pass.Report(d)
+}
+
+// checkXXXWrite checks for assignments to the XXX_ fields of a
+// message.
+func checkXXXWrite(pass *analysis.Pass, as *ast.AssignStmt) {
+	for _, lhs := range as.Lhs {
+		sel, ok := lhs.(*ast.SelectorExpr)
+		if !ok || !strings.HasPrefix(sel.Sel.Name, "XXX_") {
+			continue
+		}
+		if t := pass.TypesInfo.TypeOf(sel.X); t != nil && protoutil.IsMessage(t) {
+			pass.ReportRangef(lhs, "direct assignment to internal field %s of protobuf message", sel.Sel.Name)
+		}
+	}
+}
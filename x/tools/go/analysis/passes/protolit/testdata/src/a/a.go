@@ -0,0 +1,38 @@
+// This file contains tests for the protolit checker.
+
+package a
+
+// Msg resembles a generated protobuf message type.
+type Msg struct {
+	Name             string
+	Value            int32
+	XXX_sizecache    int32
+	XXX_unrecognized []byte
+}
+
+func (m *Msg) Reset()         {}
+func (m *Msg) String() string { return "" }
+func (m *Msg) ProtoMessage()  {}
+
+// Plain is an ordinary struct.
+type Plain struct {
+	Name  string
+	Value int32
+}
+
+func literals() {
+	_ = &Msg{Name: "a", Value: 1} // ok: keyed
+
+	_ = &Msg{"a", 1, 0, nil} // want `a.Msg composite literal uses unkeyed fields; they break when the message is regenerated`
+
+	_ = &Msg{Name: "a", XXX_sizecache: 8} // want `composite literal sets internal field XXX_sizecache of protobuf message`
+
+	_ = &Plain{"a", 1} // ok: not a protobuf message
+}
+
+func writes(m *Msg, p *Plain) {
+	m.Name = "b"             // ok
+	m.XXX_sizecache = 0      // want `direct assignment to internal field XXX_sizecache of protobuf message`
+	m.XXX_unrecognized = nil // want `direct assignment to internal field XXX_unrecognized of protobuf message`
+	p.Value = 2              // ok
+}
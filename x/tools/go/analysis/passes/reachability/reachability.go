@@ -0,0 +1,116 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reachability defines an analyzer that summarizes which
+// exported functions a package declares and which exported functions
+// it references, as a package fact. By itself, it does not report any
+// diagnostics.
+//
+// The facts accumulate across the import graph: each package's fact
+// also covers its transitive dependencies, so they compose under
+// incremental drivers such as unitchecker, where a package is analyzed
+// before its dependents. A dead-code reporter running over a module's
+// root packages can report the declared functions that no package in
+// the module references.
+//
+// The summary is deliberately conservative: any reference to a
+// function, not only a call, makes it used, and methods are excluded
+// altogether, as they may be called through an interface.
+package reachability
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "reachability",
+	Doc:        "summarize declared and referenced exported functions",
+	Run:        run,
+	FactTypes:  []analysis.Fact{new(reachFact)},
+	ResultType: reflect.TypeOf(new(Result)),
+}
+
+// A reachFact is a package fact recording the exported functions
+// declared, and the exported functions referenced, by a package and
+// its transitive dependencies. Functions are named as path.Name;
+// elements are sorted and unique.
+type reachFact struct {
+	Declared []string
+	Used     []string
+}
+
+func (*reachFact) AFact() {}
+
+func (f *reachFact) String() string {
+	return fmt.Sprintf("declared(%s) used(%s)",
+		strings.Join(f.Declared, " "), strings.Join(f.Used, " "))
+}
+
+// A Result holds the reachability summary for the current package and
+// its transitive dependencies. A reporter can diagnose the elements of
+// Declared that are absent from Used.
+type Result struct {
+	Declared map[string]bool // exported functions declared
+	Used     map[string]bool // exported functions referenced
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	result := &Result{
+		Declared: make(map[string]bool),
+		Used:     make(map[string]bool),
+	}
+
+	// Accumulate the facts of the imported packages.
+	for _, imp := range pass.Pkg.Imports() {
+		var fact reachFact
+		if pass.ImportPackageFact(imp, &fact) {
+			for _, name := range fact.Declared {
+				result.Declared[name] = true
+			}
+			for _, name := range fact.Used {
+				result.Used[name] = true
+			}
+		}
+	}
+
+	// Record the exported functions this package declares.
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		if fn, ok := scope.Lookup(name).(*types.Func); ok && fn.Exported() {
+			result.Declared[fn.FullName()] = true
+		}
+	}
+
+	// Record every exported function this package references,
+	// including its own: a function used only by its declaring
+	// package is not dead, merely over-exported.
+	for _, obj := range pass.TypesInfo.Uses {
+		if fn, ok := obj.(*types.Func); ok && fn.Exported() &&
+			fn.Type().(*types.Signature).Recv() == nil {
+			result.Used[fn.FullName()] = true
+		}
+	}
+
+	pass.ExportPackageFact(&reachFact{
+		Declared: sorted(result.Declared),
+		Used:     sorted(result.Used),
+	})
+
+	return result, nil
+}
+
+func sorted(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
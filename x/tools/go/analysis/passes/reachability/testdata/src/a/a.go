@@ -0,0 +1,15 @@
+// want package:`declared\(a\.Dead a\.Live\) used\(\)`
+
+package a
+
+func Live() {}
+
+func Dead() {}
+
+func internal() {} // unexported: not summarized
+
+type T struct{}
+
+// Method is exported, but methods are not summarized: they may be
+// called through an interface.
+func (T) Method() {}
@@ -0,0 +1,12 @@
+// want package:`declared\(a\.Dead a\.Live b\.Indirect b\.Report\) used\(a\.Live b\.Indirect\)`
+
+package b
+
+import "a"
+
+func Report() { a.Live() }
+
+// Indirect is referenced only as a value, which also counts as a use.
+func Indirect() {}
+
+var handler = Indirect
@@ -0,0 +1,56 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the sigchanyzer checker.
+
+package a
+
+import (
+	"os"
+	"os/signal"
+)
+
+func unbuffered() {
+	c := make(chan os.Signal)
+	signal.Notify(c, os.Interrupt) // want "misuse of unbuffered os.Signal channel as argument to signal.Notify"
+}
+
+func zeroBuffer() {
+	c := make(chan os.Signal, 0)
+	signal.Notify(c, os.Interrupt) // want "misuse of unbuffered os.Signal channel as argument to signal.Notify"
+}
+
+func bufferTooSmall() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, os.Kill) // want "os.Signal channel buffer of size 1 is smaller than the 2 signals passed to signal.Notify"
+}
+
+func bufferBigEnough() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, os.Kill) // no report
+}
+
+func singleSignal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt) // no report
+}
+
+func allSignals() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c) // no report: relaying all signals, buffer choice is up to the caller
+}
+
+func spread(sigs ...os.Signal) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...) // no report: number of signals not known statically
+}
+
+func notMake(c chan os.Signal) {
+	signal.Notify(c, os.Interrupt) // no report: buffer size unknown
+}
+
+func declared() {
+	var c chan os.Signal = make(chan os.Signal)
+	signal.Notify(c, os.Interrupt) // want "misuse of unbuffered os.Signal channel as argument to signal.Notify"
+}
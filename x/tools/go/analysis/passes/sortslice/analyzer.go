@@ -0,0 +1,61 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sortslice defines an Analyzer that checks for calls
+// to sort.Slice that do not use a slice type as first argument.
+package sortslice
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check the argument type of sort.Slice
+
+sort.Slice requires an argument of a slice type. Check that
+the interface{} value passed to sort.Slice is actually a slice.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "sortslice",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if fn == nil {
+			return
+		}
+
+		fnName := fn.FullName()
+		switch fnName {
+		case "sort.Slice", "sort.SliceStable", "sort.SliceIsSorted":
+		default:
+			return
+		}
+
+		arg := call.Args[0]
+		typ := pass.TypesInfo.Types[arg].Type
+		switch typ.Underlying().(type) {
+		case *types.Slice, *types.Interface:
+			return
+		}
+
+		pass.Reportf(call.Pos(), "%s's argument must be a slice; is called with %s", fnName, typ.String())
+	})
+	return nil, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the sortslice checker.
+
+package a
+
+import "sort"
+
+// IncorrectSort tries to sort an integer.
+func IncorrectSort() {
+	i := 5
+	sortFn := func(i, j int) bool { return false }
+	sort.Slice(i, sortFn)         // want "sort.Slice's argument must be a slice; is called with int"
+	sort.SliceStable(i, sortFn)   // want "sort.SliceStable's argument must be a slice; is called with int"
+	sort.SliceIsSorted(i, sortFn) // want "sort.SliceIsSorted's argument must be a slice; is called with int"
+}
+
+// IncorrectPointer tries to sort a pointer to a slice.
+func IncorrectPointer() {
+	s := []int{2, 3, 5}
+	sortFn := func(i, j int) bool { return s[i] < s[j] }
+	sort.Slice(&s, sortFn)       // want `sort.Slice's argument must be a slice; is called with \*\[\]int`
+	sort.SliceStable(&s, sortFn) // want `sort.SliceStable's argument must be a slice; is called with \*\[\]int`
+}
+
+// CorrectSort sorts integers. It should not produce a diagnostic.
+func CorrectSort() {
+	s := []int{2, 3, 5, 6}
+	sortFn := func(i, j int) bool { return s[i] < s[j] }
+	sort.Slice(s, sortFn)
+	sort.SliceStable(s, sortFn)
+	sort.SliceIsSorted(s, sortFn)
+}
+
+// CorrectInterface sorts an interface value. It should not produce a
+// diagnostic: the value may well contain a slice.
+func CorrectInterface(s interface{}) {
+	sort.Slice(s, func(i, j int) bool { return false })
+}
@@ -66,7 +66,9 @@ var Analyzer = &analysis.Analyzer{
 // rest has to match.
 var canonicalMethods = map[string]struct{ args, results []string }{
 	// "Flush": {{}, {"error"}}, // http.Flusher and jpeg.writer conflict
+	"As":            {[]string{"interface{}"}, []string{"bool"}},                       // errors.As target
 	"Format":        {[]string{"=fmt.State", "rune"}, []string{}},                      // fmt.Formatter
+	"Is":            {[]string{"error"}, []string{"bool"}},                             // errors.Is target
 	"GobDecode":     {[]string{"[]byte"}, []string{"error"}},                           // gob.GobDecoder
 	"GobEncode":     {[]string{}, []string{"[]byte", "error"}},                         // gob.GobEncoder
 	"MarshalJSON":   {[]string{}, []string{"[]byte", "error"}},                         // json.Marshaler
@@ -78,6 +80,7 @@ var canonicalMethods = map[string]struct{ args, results []string }{
 	"Seek":          {[]string{"=int64", "int"}, []string{"int64", "error"}},           // io.Seeker
 	"UnmarshalJSON": {[]string{"[]byte"}, []string{"error"}},                           // json.Unmarshaler
 	"UnmarshalXML":  {[]string{"*xml.Decoder", "xml.StartElement"}, []string{"error"}}, // xml.Unmarshaler
+	"Unwrap":        {[]string{}, []string{"error"}},                                   // errors.Unwrap
 	"UnreadByte":    {[]string{}, []string{"error"}},
 	"UnreadRune":    {[]string{}, []string{"error"}},
 	"WriteByte":     {[]string{"byte"}, []string{"error"}},                // jpeg.writer (matching bufio.Writer)
@@ -127,6 +130,16 @@ func canonicalMethod(pass *analysis.Pass, id *ast.Ident, t *ast.FuncType) {
 		return
 	}
 
+	// Special case: Unwrap has two possible signatures, returning
+	// either a single error or, for multi-errors, a slice of them.
+	if id.Name == "Unwrap" {
+		if len(args) == 0 &&
+			(matchParams(pass, []string{"error"}, results, "") ||
+				matchParams(pass, []string{"[]error"}, results, "")) {
+			return
+		}
+	}
+
 	// Everything must match.
 	if !matchParams(pass, expect.args, args, "") || !matchParams(pass, expect.results, results, "") {
 		expectFmt := id.Name + "(" + argjoin(expect.args) + ")"
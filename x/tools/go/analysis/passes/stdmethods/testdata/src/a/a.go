@@ -21,3 +21,16 @@ func (U) GobDecode() {} // want `should have signature GobDecode\(\[\]byte\) err
 type I interface {
 	ReadByte() byte // want "should have signature ReadByte"
 }
+
+type V int // V does not implement error.
+
+func (V) Unwrap() int       { return 0 }     // want `should have signature Unwrap\(\) error`
+func (V) Is(int) bool       { return false } // want `should have signature Is\(error\) bool`
+func (V) As(int) bool       { return false } // want `should have signature As\(interface\{\}\) bool`
+func (V) Unwrap2() []error  { return nil }   // no error: not the canonical name
+func (V) WrapUnwrap() error { return nil }   // no error: not the canonical name
+func (V) IsNot(error) bool  { return false } // no error: not the canonical name
+
+type W int // Unwrap may also return a slice of errors.
+
+func (W) Unwrap() []error { return nil } // no error: multi-error form
@@ -0,0 +1,30 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the stringintconv checker.
+
+package a
+
+type A string
+
+type B = string
+
+type C int
+
+type D = uintptr
+
+func StringTest(i int, u uint, r rune, b byte, C C, d D, s string, S A) {
+	var _ string = string(i) // want `^conversion from int to string yields a string of one rune, not a string of digits \(did you mean fmt\.Sprint\(x\)\?\)$`
+	var _ string = string(u) // want `^conversion from uint to string yields a string of one rune, not a string of digits.*$`
+	var _ string = string(r)
+	var _ string = string(b)
+	var _ string = string(C) // want `^conversion from C \(int\) to string yields a string of one rune, not a string of digits.*$`
+	var _ string = string(d) // want `^conversion from uintptr to string yields a string of one rune, not a string of digits.*$`
+	var _ string = string(s)
+	var _ string = string(S)
+	var _ A = A(i) // want `^conversion from int to A \(string\) yields a string of one rune, not a string of digits.*$`
+	var _ A = A(b)
+	var _ B = B(i) // want `^conversion from int to B \(string\) yields a string of one rune, not a string of digits.*$`
+	var _ B = B(r)
+}
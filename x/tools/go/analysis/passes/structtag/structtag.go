@@ -23,7 +23,8 @@ import (
 
 const Doc = `check that struct field tags conform to reflect.StructTag.Get
 
-Also report certain struct tags (json, xml) used with unexported fields.`
+Also report certain struct tags (json, xml) used with unexported fields,
+and json or xml names that are repeated within a struct.`
 
 var Analyzer = &analysis.Analyzer{
 	Name:             "structtag",
@@ -0,0 +1,54 @@
+// This file contains tests for the testinggoroutine checker.
+
+package a
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBad(t *testing.T) {
+	go func() {
+		t.Fatal("oops")        // want `call to \(\*T\).Fatal from a non-test goroutine`
+		t.Fatalf("oops %d", 1) // want `call to \(\*T\).Fatalf from a non-test goroutine`
+		t.FailNow()            // want `call to \(\*T\).FailNow from a non-test goroutine`
+		t.Skip("nope")         // want `call to \(\*T\).Skip from a non-test goroutine`
+	}()
+}
+
+func TestNested(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if true {
+			t.Fatal("oops") // want `call to \(\*T\).Fatal from a non-test goroutine`
+		}
+	}()
+	wg.Wait()
+}
+
+func TestGood(t *testing.T) {
+	t.Fatal("fine from the test goroutine itself")
+}
+
+func TestErrorOK(t *testing.T) {
+	go func() {
+		t.Error("Error is fine") // ok
+		t.Log("so is Log")       // ok
+	}()
+}
+
+func BenchmarkBad(b *testing.B) {
+	go func() {
+		b.Fatal("oops") // want `call to \(\*B\).Fatal from a non-test goroutine`
+	}()
+}
+
+func helper(t *testing.T) {
+	// Not a test function: named functions may be called from the
+	// test goroutine, so the checker is silent here.
+	go func() {
+		t.Fatal("unreported")
+	}()
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package testinggoroutine defines an Analyzer that checks for calls
+// to Fatal from within a goroutine started by a test.
+package testinggoroutine
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `report calls to (*testing.T).Fatal from goroutines started by a test.
+
+Functions that abort a test, such as Fatal, Fatalf, and FailNow, and
+the corresponding Skip variants, must be called from the goroutine
+running the test or benchmark function, not from other goroutines
+created during the test, as the testing package documents. This
+checker detects calls to these functions that occur within a
+goroutine started by the test.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "testinggoroutine",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var forbidden = map[string]bool{
+	"FailNow": true,
+	"Fatal":   true,
+	"Fatalf":  true,
+	"Skip":    true,
+	"Skipf":   true,
+	"SkipNow": true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.FuncDecl)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		fn := n.(*ast.FuncDecl)
+		if !isTestFunc(fn) {
+			return
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			g, ok := n.(*ast.GoStmt)
+			if !ok {
+				return true
+			}
+			// Only a function literal launched directly by
+			// the go statement can be analyzed soundly; a
+			// named function may be called from elsewhere too.
+			if lit, ok := g.Call.Fun.(*ast.FuncLit); ok {
+				checkGoroutine(pass, lit.Body)
+			}
+			return true
+		})
+	})
+	return nil, nil
+}
+
+// checkGoroutine reports forbidden testing calls within the body of
+// a goroutine started by a test.
+func checkGoroutine(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !forbidden[sel.Sel.Name] {
+			return true
+		}
+		if kind, ok := testingType(pass, sel.X); ok {
+			pass.ReportRangef(call, "call to (*%s).%s from a non-test goroutine", kind, sel.Sel.Name)
+		}
+		return true
+	})
+}
+
+// isTestFunc reports whether fn looks like a test or benchmark
+// function: a Test or Benchmark name with a single parameter.
+func isTestFunc(fn *ast.FuncDecl) bool {
+	if fn.Body == nil || fn.Recv != nil {
+		return false
+	}
+	if !strings.HasPrefix(fn.Name.Name, "Test") && !strings.HasPrefix(fn.Name.Name, "Benchmark") {
+		return false
+	}
+	return len(fn.Type.Params.List) == 1
+}
+
+// testingType returns "T" or "B" if the type of e is *testing.T or
+// *testing.B.
+func testingType(pass *analysis.Pass, e ast.Expr) (string, bool) {
+	t := pass.TypesInfo.TypeOf(e)
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return "", false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return "", false
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != "testing" {
+		return "", false
+	}
+	switch obj.Name() {
+	case "T", "B":
+		return obj.Name(), true
+	}
+	return "", false
+}
@@ -76,3 +76,9 @@ func TesttooManyNames(a, b *testing.T) {} // OK because it takes too many names.
 func TestnoTParam(string) {} // OK because it doesn't take a *testing.T
 
 func BenchmarkbadSuffix(*testing.B) {} // want "first letter after 'Benchmark' must not be lowercase"
+
+func FuzzOKSuffix(*testing.F) {} // OK because first char after "Fuzz" is Uppercase.
+
+func FuzzbadSuffix(*testing.F) {} // want "first letter after 'Fuzz' must not be lowercase"
+
+func Fuzz(*testing.F) {} // OK "Fuzz" on its own is considered a fuzz target.
@@ -18,9 +18,9 @@ import (
 
 const Doc = `check for common mistaken usages of tests and examples
 
-The tests checker walks Test, Benchmark and Example functions checking
-malformed names, wrong signatures and examples documenting non-existent
-identifiers.`
+The tests checker walks Test, Benchmark, Fuzz and Example functions
+checking malformed names, wrong signatures and examples documenting
+non-existent identifiers.`
 
 var Analyzer = &analysis.Analyzer{
 	Name: "tests",
@@ -47,6 +47,8 @@ func run(pass *analysis.Pass) (interface{}, error) {
 				checkTest(pass, fn, "Test")
 			case strings.HasPrefix(fn.Name.Name, "Benchmark"):
 				checkTest(pass, fn, "Benchmark")
+			case strings.HasPrefix(fn.Name.Name, "Fuzz"):
+				checkTest(pass, fn, "Fuzz")
 			}
 		}
 	}
@@ -0,0 +1,33 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the timeformat checker.
+
+package a
+
+import "time"
+
+const transposed = "2006-02-01"
+
+func hasError(t time.Time) {
+	t.Format("2006-02-01")                     // want `2006-02-01 should be 2006-01-02`
+	t.Format("2006-02-01 15:04:05")            // want `2006-02-01 should be 2006-01-02`
+	t.Format(transposed)                       // want `2006-02-01 should be 2006-01-02`
+	time.Parse("2006-02-01", "2021-12-31")     // want `2006-02-01 should be 2006-01-02`
+	t.Format("2006-01-02 08:04")               // want `08 is not a component of the reference time and will be reproduced literally in the output`
+	time.Parse("2006-01-02 15:08", "whatever") // want `08 is not a component of the reference time`
+}
+
+func noError(t time.Time) {
+	t.Format("2006-01-02")
+	t.Format("2006-01-02 15:04:05")
+	t.Format("15:04:05.000")
+	t.Format(time.RFC3339)
+	t.Format("2006-01-02T15:04:05.999999999Z07:00")
+	time.Parse(time.RFC1123, "whatever")
+
+	// A non-constant layout is not checked.
+	layout := "2006-02-01"
+	t.Format(layout)
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package timeformat defines an Analyzer that checks for the use
+// of time.Format or time.Parse calls with a bad format.
+package timeformat
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const badFormat = "2006-02-01"
+const goodFormat = "2006-01-02"
+
+const Doc = `check for calls of (time.Time).Format or time.Parse with 2006-02-01
+
+The timeformat checker looks for time formats with the 2006-02-01 (yyyy-dd-mm)
+format. Internationally, "yyyy-dd-mm" does not occur in common calendar date
+standards, and so it is more likely that 2006-01-02 (yyyy-mm-dd) was intended.
+
+It also reports layouts containing numbers that are not components of the
+reference time Mon Jan 2 15:04:05 MST 2006, such as an hour written 08
+instead of 03 or 15, which time.Format reproduces literally.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "timeformat",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return
+		}
+		if !isTimeDotFormat(fn) && !isTimeDotParse(fn) {
+			return
+		}
+		if len(call.Args) > 0 {
+			arg := call.Args[0]
+			badAt := badFormatAt(pass.TypesInfo, arg)
+			if badAt > -1 {
+				// Check if it's a literal string, otherwise we can't suggest a fix.
+				if lit, ok := arg.(*ast.BasicLit); ok {
+					layout, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						return
+					}
+					pass.Report(analysis.Diagnostic{
+						Pos:     arg.Pos(),
+						Message: badFormat + " should be " + goodFormat,
+						SuggestedFixes: []analysis.SuggestedFix{{
+							Message: "Replace " + badFormat + " with " + goodFormat,
+							TextEdits: []analysis.TextEdit{{
+								Pos:     lit.Pos(),
+								End:     lit.End(),
+								NewText: []byte(strconv.Quote(strings.Replace(layout, badFormat, goodFormat, 1))),
+							}},
+						}},
+					})
+				} else {
+					pass.Reportf(arg.Pos(), badFormat+" should be "+goodFormat)
+				}
+				return
+			}
+			if lit, ok := arg.(*ast.BasicLit); ok {
+				layout, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					return
+				}
+				if bad := invalidComponent(layout); bad != "" {
+					pass.Reportf(arg.Pos(), "%s is not a component of the reference time and will be reproduced literally in the output", bad)
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+func isTimeDotFormat(f *types.Func) bool {
+	if f.Name() != "Format" || f.Pkg() == nil || f.Pkg().Path() != "time" {
+		return false
+	}
+	sig, ok := f.Type().(*types.Signature)
+	if !ok {
+		return false
+	}
+	// Verify that the receiver is time.Time.
+	recv := sig.Recv()
+	if recv == nil {
+		return false
+	}
+	named, ok := recv.Type().(*types.Named)
+	return ok && named.Obj().Name() == "Time"
+}
+
+func isTimeDotParse(f *types.Func) bool {
+	return f.FullName() == "time.Parse"
+}
+
+// badFormatAt return the start of a bad format in e or -1 if no bad format is found.
+func badFormatAt(info *types.Info, e ast.Expr) int {
+	tv, ok := info.Types[e]
+	if !ok { // no type info, assume good
+		return -1
+	}
+
+	t, ok := tv.Type.(*types.Basic)
+	if !ok || t.Info()&types.IsString == 0 {
+		return -1
+	}
+
+	if tv.Value == nil {
+		return -1
+	}
+
+	return strings.Index(constant.StringVal(tv.Value), badFormat)
+}
+
+// validComponents are the numeric components of the reference time,
+// including the numeric time zone and day-of-year forms, longest first
+// so that decomposition prefers the longer match.
+var validComponents = []string{
+	"070000", "2006", "0700", "002",
+	"15", "01", "02", "03", "04", "05", "06", "07", "00",
+	"1", "2", "3", "4", "5",
+}
+
+// invalidComponent returns the first run of digits in layout that cannot
+// be decomposed into components of the reference time, or "" if there is
+// none. A run of 0s or 9s following a period or comma denotes fractional
+// seconds and is valid.
+func invalidComponent(layout string) string {
+	for i := 0; i < len(layout); i++ {
+		if !isDigit(layout[i]) {
+			continue
+		}
+		j := i
+		for j < len(layout) && isDigit(layout[j]) {
+			j++
+		}
+		run := layout[i:j]
+		if i > 0 && (layout[i-1] == '.' || layout[i-1] == ',') && allSame(run) {
+			// fractional seconds, e.g. ".000" or ",999"
+			i = j
+			continue
+		}
+		if !decomposable(run) {
+			return run
+		}
+		i = j
+	}
+	return ""
+}
+
+func isDigit(b byte) bool { return '0' <= b && b <= '9' }
+
+func allSame(run string) bool {
+	if run[0] != '0' && run[0] != '9' {
+		return false
+	}
+	for i := 1; i < len(run); i++ {
+		if run[i] != run[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func decomposable(run string) bool {
+	if run == "" {
+		return true
+	}
+	for _, c := range validComponents {
+		if strings.HasPrefix(run, c) && decomposable(run[len(c):]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the uncheckederr checker.
+
+package a
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func statements(f *os.File, w io.Writer, r io.Reader) {
+	os.Remove("x")  // want `error result of os.Remove call is not checked`
+	os.Chdir("dir") // want `error result of os.Chdir call is not checked`
+	f.Close()       // want `error result of \(\*os.File\).Close call is not checked`
+	io.Copy(w, r)   // want `error result of io.Copy call is not checked`
+
+	fmt.Println("x") // ok: fmt is not in the checked set
+
+	if err := os.Remove("x"); err != nil { // ok: error is checked
+		panic(err)
+	}
+	_ = os.Remove("x")    // ok: explicit discard (see the blank flag)
+	n, _ := io.Copy(w, r) // ok: explicit discard (see the blank flag)
+	_ = n
+	s := fmt.Sprint("x") // ok: no error result
+	_ = s
+}
+
+// remove wraps a checked function, so its callers are checked too.
+func remove(name string) error { // want remove:"mustCheckError"
+	return os.Remove(name)
+}
+
+// removeDeep wraps the wrapper.
+func removeDeep(name string) error { // want removeDeep:"mustCheckError"
+	return remove(name)
+}
+
+func useWrappers() {
+	remove("x")     // want `error result of a.remove call is not checked`
+	removeDeep("x") // want `error result of a.removeDeep call is not checked`
+
+	if err := remove("x"); err != nil { // ok
+		panic(err)
+	}
+}
+
+// logged consumes the error itself, so it is not a wrapper.
+func logged(name string) {
+	if err := os.Remove(name); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func useLogged() {
+	logged("x") // ok: no error result
+}
@@ -0,0 +1,22 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the uncheckederr checker with the
+// blank flag set.
+
+package b
+
+import (
+	"io"
+	"os"
+)
+
+func blanks(w io.Writer, r io.Reader) {
+	_ = os.Remove("x")    // want `error result of os.Remove call is discarded with _`
+	n, _ := io.Copy(w, r) // want `error result of io.Copy call is discarded with _`
+	_ = n
+
+	_, err := io.Copy(w, r) // ok: only the count is discarded
+	_ = err
+}
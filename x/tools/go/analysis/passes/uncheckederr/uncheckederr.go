@@ -0,0 +1,219 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package uncheckederr defines an analyzer that reports calls whose
+// error result is discarded.
+package uncheckederr
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `check for discarded error results
+
+Calling a function such as os.Remove as a statement silently discards
+its error result, so the caller never learns that the operation failed.
+This analyzer reports such calls for a configurable set of functions:
+every function of the packages named by the -packages flag, plus the
+functions and methods named individually by the -funcs flag.
+
+By default an explicit assignment to the blank identifier, as in
+
+	_ = os.Remove(name)
+
+is accepted as a deliberate discard; the -blank flag reports those too.
+
+A function whose body is a single tail call returning the error of a
+checked function is itself checked, even across packages, making the
+analysis modular.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "uncheckederr",
+	Doc:       Doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(mustCheckError)},
+	Run:       run,
+}
+
+// A mustCheckError fact is exported for each function of this package
+// whose body merely tail-calls a function whose error result must be
+// checked, extending the check to wrappers defined in dependent
+// packages.
+type mustCheckError struct{}
+
+func (*mustCheckError) AFact()         {}
+func (*mustCheckError) String() string { return "mustCheckError" }
+
+// flags
+var (
+	packages, funcs stringSetFlag
+	blank           bool
+)
+
+func init() {
+	packages.Set("io,os")
+	Analyzer.Flags.Var(&packages, "packages",
+		"comma-separated list of packages all of whose error results must be checked")
+
+	funcs.Set("")
+	Analyzer.Flags.Var(&funcs, "funcs",
+		"comma-separated list of functions or methods (e.g. (io.Closer).Close) whose error results must be checked")
+
+	Analyzer.Flags.BoolVar(&blank, "blank", blank,
+		"report discards using the blank identifier, as in _ = f()")
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	// mustCheck reports whether the error result of fn must be checked.
+	mustCheck := func(fn *types.Func) bool {
+		if !returnsError(fn) {
+			return false
+		}
+		if fn.Pkg() != nil && packages[fn.Pkg().Path()] {
+			return true
+		}
+		if funcs[fn.FullName()] {
+			return true
+		}
+		return pass.ImportObjectFact(fn, new(mustCheckError))
+	}
+
+	// callee returns the function or method statically called by call,
+	// if any.
+	callee := func(call *ast.CallExpr) *types.Func {
+		fn, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		return fn
+	}
+
+	// Export a fact for each function of this package whose body is
+	// just a tail call returning the error of a checked function, so
+	// that the check extends to wrappers used in dependent packages.
+	// Iterate to a fixed point to handle chains of wrappers.
+	for {
+		again := false
+		for _, file := range pass.Files {
+			for _, decl := range file.Decls {
+				decl, ok := decl.(*ast.FuncDecl)
+				if !ok || decl.Body == nil || len(decl.Body.List) != 1 {
+					continue
+				}
+				ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+				if !ok || len(ret.Results) != 1 {
+					continue
+				}
+				call, ok := analysisutil.Unparen(ret.Results[0]).(*ast.CallExpr)
+				if !ok {
+					continue
+				}
+				target := callee(call)
+				if target == nil || !mustCheck(target) {
+					continue
+				}
+				fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+				if !ok || !returnsError(fn) || pass.ImportObjectFact(fn, new(mustCheckError)) {
+					continue
+				}
+				pass.ExportObjectFact(fn, new(mustCheckError))
+				again = true
+			}
+		}
+		if !again {
+			break
+		}
+	}
+
+	nodeFilter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.ExprStmt)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		var call *ast.CallExpr
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			call, _ = analysisutil.Unparen(n.X).(*ast.CallExpr)
+		case *ast.AssignStmt:
+			// v, _ := f() or _ = f(): the error result, and only
+			// the error result, is assigned to the blank identifier.
+			if !blank || len(n.Rhs) != 1 {
+				return
+			}
+			call, _ = analysisutil.Unparen(n.Rhs[0]).(*ast.CallExpr)
+			if call == nil || len(n.Lhs) != results(pass, call) || !isBlank(n.Lhs[len(n.Lhs)-1]) {
+				return
+			}
+		}
+		if call == nil || pass.TypesInfo.Types[analysisutil.Unparen(call.Fun)].IsType() {
+			return // not a call, or a conversion
+		}
+		fn := callee(call)
+		if fn == nil || !mustCheck(fn) {
+			return
+		}
+		if _, ok := n.(*ast.AssignStmt); ok {
+			pass.Reportf(call.Lparen, "error result of %s call is discarded with _", fn.FullName())
+		} else {
+			pass.Reportf(call.Lparen, "error result of %s call is not checked", fn.FullName())
+		}
+	})
+	return nil, nil
+}
+
+// returnsError reports whether the last result of fn has type error.
+func returnsError(fn *types.Func) bool {
+	res := fn.Type().(*types.Signature).Results()
+	return res.Len() > 0 && types.Identical(res.At(res.Len()-1).Type(), errorType)
+}
+
+// results returns the number of results of the function called by call.
+func results(pass *analysis.Pass, call *ast.CallExpr) int {
+	switch t := pass.TypesInfo.Types[call].Type.(type) {
+	case *types.Tuple:
+		return t.Len()
+	default:
+		return 1
+	}
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+type stringSetFlag map[string]bool
+
+func (ss *stringSetFlag) String() string {
+	var items []string
+	for item := range *ss {
+		items = append(items, item)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func (ss *stringSetFlag) Set(s string) error {
+	m := make(map[string]bool) // clobber previous value
+	if s != "" {
+		for _, name := range strings.Split(s, ",") {
+			if name == "" {
+				continue
+			}
+			m[name] = true
+		}
+	}
+	*ss = m
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uncheckederr_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/passes/uncheckederr"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, uncheckederr.Analyzer, "a")
+}
+
+func TestBlank(t *testing.T) {
+	testdata := analysistest.TestData()
+	if err := uncheckederr.Analyzer.Flags.Set("blank", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer uncheckederr.Analyzer.Flags.Set("blank", "false")
+	analysistest.Run(t, testdata, uncheckederr.Analyzer, "b")
+}
@@ -0,0 +1,38 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the unmarshal checker.
+
+package a
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+)
+
+func _(r io.Reader) {
+	var v map[string]string
+	var data []byte
+
+	json.Unmarshal(data, v)  // want "call of Unmarshal passes non-pointer as second argument"
+	json.Unmarshal(data, &v) // OK
+	xml.Unmarshal(data, v)   // want "call of Unmarshal passes non-pointer as second argument"
+	xml.Unmarshal(data, &v)  // OK
+
+	json.NewDecoder(r).Decode(v)  // want "call of Decode passes non-pointer"
+	json.NewDecoder(r).Decode(&v) // OK
+	gob.NewDecoder(r).Decode(v)   // want "call of Decode passes non-pointer"
+	gob.NewDecoder(r).Decode(&v)  // OK
+	xml.NewDecoder(r).Decode(v)   // want "call of Decode passes non-pointer"
+	xml.NewDecoder(r).Decode(&v)  // OK
+
+	var p *map[string]string
+	json.Unmarshal(data, p)  // OK: a pointer, albeit possibly nil
+	json.Unmarshal(data, *p) // want "call of Unmarshal passes non-pointer as second argument"
+
+	var i interface{}
+	json.Unmarshal(data, i) // OK: an interface might hold a pointer
+}
@@ -79,7 +79,7 @@ func isSafeUintptr(info *types.Info, x ast.Expr) bool {
 			pt, ok := info.Types[x.X].Type.(*types.Pointer)
 			if ok {
 				t, ok := pt.Elem().(*types.Named)
-				if ok && t.Obj().Pkg().Path() == "reflect" {
+				if ok && t.Obj().Pkg() != nil && t.Obj().Pkg().Path() == "reflect" {
 					switch t.Obj().Name() {
 					case "StringHeader", "SliceHeader":
 						return true
@@ -99,7 +99,7 @@ func isSafeUintptr(info *types.Info, x ast.Expr) bool {
 			switch sel.Sel.Name {
 			case "Pointer", "UnsafeAddr":
 				t, ok := info.Types[sel.X].Type.(*types.Named)
-				if ok && t.Obj().Pkg().Path() == "reflect" && t.Obj().Name() == "Value" {
+				if ok && t.Obj().Pkg() != nil && t.Obj().Pkg().Path() == "reflect" && t.Obj().Name() == "Value" {
 					return true
 				}
 			}
@@ -24,4 +24,18 @@ func _() {
 
 	fmt.Sprint("")  // want "result of fmt.Sprint call not used"
 	fmt.Sprintf("") // want "result of fmt.Sprintf call not used"
+
+	Errorf("")  // want "result of a.Errorf call not used"
+	errorf2("") // want "result of a.errorf2 call not used"
+}
+
+// Errorf is a wrapper whose result must be used,
+// as its body is just a tail call to fmt.Errorf.
+func Errorf(format string, args ...interface{}) error { // want Errorf:"mustUseResult"
+	return fmt.Errorf(format, args...)
+}
+
+// errorf2 wraps a wrapper.
+func errorf2(format string, args ...interface{}) error { // want errorf2:"mustUseResult"
+	return Errorf(format, args...)
 }
@@ -0,0 +1,12 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package b
+
+import "a"
+
+func _() {
+	a.Errorf("") // want "result of a.Errorf call not used"
+	_ = a.Errorf("")
+}
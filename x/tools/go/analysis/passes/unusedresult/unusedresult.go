@@ -17,33 +17,39 @@ import (
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
-// TODO(adonovan): make this analysis modular: export a mustUseResult
-// fact for each function that tail-calls one of the functions that we
-// check, and check those functions too.
-
 const Doc = `check for unused results of calls to some functions
 
 Some functions like fmt.Errorf return a result and have no side effects,
 so it is always a mistake to discard the result. This analyzer reports
 calls to certain functions in which the result of the call is ignored.
 
-The set of functions may be controlled using flags.`
+The set of functions may be controlled using flags. In addition, a
+function whose body is a single tail call to a checked function is
+itself checked, even across packages, making the analysis modular.`
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "unusedresult",
-	Doc:      Doc,
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
-	Run:      run,
+	Name:      "unusedresult",
+	Doc:       Doc,
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	FactTypes: []analysis.Fact{new(mustUseResult)},
+	Run:       run,
 }
 
+// A mustUseResult fact is exported for each function of this package
+// whose body merely tail-calls a function whose result must be used,
+// extending the check to wrappers defined in dependent packages.
+type mustUseResult struct{}
+
+func (*mustUseResult) AFact()         {}
+func (*mustUseResult) String() string { return "mustUseResult" }
+
 // flags
 var funcs, stringMethods stringSetFlag
 
 func init() {
-	// TODO(adonovan): provide a comment syntax to allow users to
-	// add their functions to this set using facts.
 	funcs.Set("errors.New,fmt.Errorf,fmt.Sprintf,fmt.Sprint,sort.Reverse")
 	Analyzer.Flags.Var(&funcs, "funcs",
 		"comma-separated list of functions whose results must be used")
@@ -56,6 +62,51 @@ func init() {
 func run(pass *analysis.Pass) (interface{}, error) {
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
+	// mustUse reports whether results of calls to fn must be used.
+	mustUse := func(fn *types.Func) bool {
+		if fn.Pkg() != nil && funcs[fn.Pkg().Path()+"."+fn.Name()] {
+			return true
+		}
+		return pass.ImportObjectFact(fn, new(mustUseResult))
+	}
+
+	// Export a fact for each function of this package whose body is
+	// just a tail call to a must-use function, so that the check
+	// extends to wrappers used in dependent packages.
+	// Iterate to a fixed point to handle chains of wrappers.
+	for {
+		again := false
+		for _, file := range pass.Files {
+			for _, decl := range file.Decls {
+				decl, ok := decl.(*ast.FuncDecl)
+				if !ok || decl.Body == nil || len(decl.Body.List) != 1 {
+					continue
+				}
+				ret, ok := decl.Body.List[0].(*ast.ReturnStmt)
+				if !ok || len(ret.Results) != 1 {
+					continue
+				}
+				call, ok := analysisutil.Unparen(ret.Results[0]).(*ast.CallExpr)
+				if !ok {
+					continue
+				}
+				callee := typeutil.StaticCallee(pass.TypesInfo, call)
+				if callee == nil || !mustUse(callee) {
+					continue
+				}
+				fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+				if !ok || pass.ImportObjectFact(fn, new(mustUseResult)) {
+					continue
+				}
+				pass.ExportObjectFact(fn, new(mustUseResult))
+				again = true
+			}
+		}
+		if !again {
+			break
+		}
+	}
+
 	nodeFilter := []ast.Node{
 		(*ast.ExprStmt)(nil),
 	}
@@ -70,6 +121,14 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return // a conversion, not a call
 		}
 
+		// Wrappers around the configured set, marked by facts,
+		// possibly defined in other packages.
+		if callee := typeutil.StaticCallee(pass.TypesInfo, call); callee != nil &&
+			pass.ImportObjectFact(callee, new(mustUseResult)) {
+			pass.Reportf(call.Lparen, "result of %s call not used", callee.FullName())
+			return
+		}
+
 		selector, ok := fun.(*ast.SelectorExpr)
 		if !ok {
 			return // neither a method call nor a qualified ident
@@ -0,0 +1,84 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the waitgroup checker.
+
+package a
+
+import "sync"
+
+func addInGoroutine() {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		go func() {
+			wg.Add(1) // want "WaitGroup.Add called from inside new goroutine"
+			defer wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func addBeforeGoroutine() {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+		}()
+	}
+	wg.Wait()
+}
+
+func waitUnderLock(mu *sync.Mutex) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		wg.Done()
+	}()
+	mu.Lock()
+	wg.Wait() // want "WaitGroup.Wait called while holding mu, which is locked by a goroutine started in this function \\(possible deadlock\\)"
+	mu.Unlock()
+}
+
+func waitAfterUnlock(mu *sync.Mutex) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		wg.Done()
+	}()
+	mu.Lock()
+	mu.Unlock()
+	wg.Wait() // no report: lock released before Wait
+}
+
+func waitUnderUnrelatedLock(mu *sync.Mutex) {
+	var other sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		wg.Done()
+	}()
+	other.Lock()
+	wg.Wait() // no report: goroutine does not use other
+	other.Unlock()
+}
+
+func waitUnderRWLock(mu *sync.RWMutex) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		mu.Lock()
+		defer mu.Unlock()
+		wg.Done()
+	}()
+	mu.RLock()
+	wg.Wait() // want "WaitGroup.Wait called while holding mu"
+	mu.RUnlock()
+}
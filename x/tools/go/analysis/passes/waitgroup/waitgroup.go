@@ -0,0 +1,196 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package waitgroup defines an Analyzer that detects simple misuses
+// of sync.WaitGroup.
+package waitgroup
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/analysis/passes/internal/analysisutil"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check for misuses of sync.WaitGroup
+
+This checker detects two error-prone patterns. The first is a call to
+WaitGroup.Add inside the goroutine it is meant to account for; the Add
+races with the corresponding Wait, which may return before the
+goroutine has been counted. The second is a call to WaitGroup.Wait
+made while holding a mutex that the waited-for goroutines also
+acquire, a likely deadlock.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "waitgroup",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.GoStmt)(nil),
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			checkGoroutineAdd(pass, n)
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkWaitUnderLock(pass, n.Body)
+			}
+		case *ast.FuncLit:
+			checkWaitUnderLock(pass, n.Body)
+		}
+	})
+	return nil, nil
+}
+
+// checkGoroutineAdd reports a WaitGroup.Add that is the first statement
+// of a newly started goroutine. Such an Add is not ordered before the
+// corresponding Wait, which may therefore return too early.
+func checkGoroutineAdd(pass *analysis.Pass, g *ast.GoStmt) {
+	lit, ok := g.Call.Fun.(*ast.FuncLit)
+	if !ok || len(lit.Body.List) == 0 {
+		return
+	}
+	if stmt, ok := lit.Body.List[0].(*ast.ExprStmt); ok {
+		if call, ok := stmt.X.(*ast.CallExpr); ok && isSyncMethod(pass, call, "WaitGroup", "Add") {
+			pass.Reportf(call.Pos(), "WaitGroup.Add called from inside new goroutine")
+		}
+	}
+}
+
+// checkWaitUnderLock reports a WaitGroup.Wait made while a mutex is
+// held, if a goroutine started in the same function acquires that
+// mutex: the Wait then cannot complete until the lock is released,
+// and the lock cannot be released until the Wait completes.
+//
+// Only straight-line statement sequences are analyzed, so a lock
+// released on another path is conservatively forgotten.
+func checkWaitUnderLock(pass *analysis.Pass, body *ast.BlockStmt) {
+	// The mutexes locked by goroutines started in this function,
+	// by the source text of their receiver expressions.
+	goLocks := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		g, ok := n.(*ast.GoStmt)
+		if !ok {
+			return true
+		}
+		lit, ok := g.Call.Fun.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		ast.Inspect(lit.Body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if name, method := mutexMethod(pass, call); method == "Lock" || method == "RLock" {
+					goLocks[name] = true
+				}
+			}
+			return true
+		})
+		return true
+	})
+	if len(goLocks) == 0 {
+		return
+	}
+
+	var checkList func(list []ast.Stmt)
+	checkList = func(list []ast.Stmt) {
+		var held []string // mutexes locked so far, in order
+		for _, stmt := range list {
+			switch stmt := stmt.(type) {
+			case *ast.ExprStmt:
+				call, ok := stmt.X.(*ast.CallExpr)
+				if !ok {
+					continue
+				}
+				name, method := mutexMethod(pass, call)
+				switch method {
+				case "Lock", "RLock":
+					held = append(held, name)
+				case "Unlock", "RUnlock":
+					for i, h := range held {
+						if h == name {
+							held = append(held[:i], held[i+1:]...)
+							break
+						}
+					}
+				}
+				if isSyncMethod(pass, call, "WaitGroup", "Wait") {
+					for _, h := range held {
+						if goLocks[h] {
+							pass.Reportf(call.Pos(), "WaitGroup.Wait called while holding %s, which is locked by a goroutine started in this function (possible deadlock)", h)
+							break
+						}
+					}
+				}
+			case *ast.BlockStmt:
+				checkList(stmt.List)
+			case *ast.IfStmt:
+				checkList(stmt.Body.List)
+				if b, ok := stmt.Else.(*ast.BlockStmt); ok {
+					checkList(b.List)
+				}
+			case *ast.ForStmt:
+				checkList(stmt.Body.List)
+			case *ast.RangeStmt:
+				checkList(stmt.Body.List)
+			}
+		}
+	}
+	checkList(body.List)
+}
+
+// mutexMethod, if call is a method call on a sync.Mutex or
+// sync.RWMutex, returns the source text of the receiver expression and
+// the method name.
+func mutexMethod(pass *analysis.Pass, call *ast.CallExpr) (recv, method string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", ""
+	}
+	if isSyncMethod(pass, call, "Mutex") || isSyncMethod(pass, call, "RWMutex") {
+		return analysisutil.Format(pass.Fset, sel.X), sel.Sel.Name
+	}
+	return "", ""
+}
+
+// isSyncMethod reports whether call invokes a method of the named sync
+// type, optionally requiring a particular method name.
+func isSyncMethod(pass *analysis.Pass, call *ast.CallExpr, typeName string, methodName ...string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Func)
+	if !ok {
+		return false
+	}
+	if len(methodName) > 0 && fn.Name() != methodName[0] {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == typeName && obj.Pkg() != nil && obj.Pkg().Path() == "sync"
+}
@@ -64,7 +64,5 @@ func Main(a *analysis.Analyzer) {
 		os.Exit(1)
 	}
 
-	if err := checker.Run(args, []*analysis.Analyzer{a}); err != nil {
-		log.Fatal(err)
-	}
+	os.Exit(checker.Run(args, []*analysis.Analyzer{a}))
 }
@@ -0,0 +1,117 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inspector
+
+// This file defines the package-level comment and directive index,
+// so that the many analyzers that honor comment directives such as
+// //go:generate, //line, and //nolint need not each build their own
+// ast.CommentMap per file.
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// A Directive is a comment directive: a comment whose text begins,
+// with no space after the "//", with a lowercase tool name and a
+// colon, such as //go:generate or //nolint:cgocall. The old-style
+// space-separated forms //line, //extern, and //export are also
+// recognized.
+type Directive struct {
+	Node ast.Node     // node whose comment group holds the directive, or nil if unassociated
+	C    *ast.Comment // the directive comment itself
+	Tool string       // e.g. "go" in //go:generate
+	Name string       // e.g. "generate" in //go:generate; "" for the old-style forms
+	Args string       // the rest of the line, without surrounding space
+}
+
+// Comments returns the comment groups associated with n, in the
+// manner of ast.NewCommentMap, for any node of the files supplied to
+// New. The fset must be the file set that was used to parse the
+// files. The maps for all files are built in a single pass on the
+// first call; subsequent calls are constant-time map lookups.
+func (in *Inspector) Comments(fset *token.FileSet, n ast.Node) []*ast.CommentGroup {
+	in.commentsOnce.Do(func() { in.buildComments(fset) })
+	return in.comments[n]
+}
+
+// Directives returns the comment directives of the package in
+// position order. Each directive records the node with which its
+// comment group is associated, so a directive discovered during one
+// traversal can be related back to the declaration or statement it
+// governs.
+func (in *Inspector) Directives(fset *token.FileSet) []Directive {
+	in.commentsOnce.Do(func() { in.buildComments(fset) })
+	return in.directives
+}
+
+func (in *Inspector) buildComments(fset *token.FileSet) {
+	in.comments = make(map[ast.Node][]*ast.CommentGroup)
+	owner := make(map[*ast.CommentGroup]ast.Node)
+	for i := 0; i < len(in.events); {
+		ev := in.events[i]
+		// Each top-level event is the push of an *ast.File;
+		// see SplitByFile.
+		f := ev.node.(*ast.File)
+		for node, groups := range ast.NewCommentMap(fset, f, f.Comments) {
+			in.comments[node] = groups
+			for _, g := range groups {
+				owner[g] = node
+			}
+		}
+		for _, g := range f.Comments {
+			for _, c := range g.List {
+				if d, ok := parseDirective(c); ok {
+					d.Node = owner[g]
+					in.directives = append(in.directives, d)
+				}
+			}
+		}
+		i = ev.index
+	}
+}
+
+// parseDirective interprets c as a comment directive,
+// reporting whether it is one.
+func parseDirective(c *ast.Comment) (Directive, bool) {
+	if !strings.HasPrefix(c.Text, "//") {
+		return Directive{}, false // a /* */ comment cannot be a directive
+	}
+	text := c.Text[2:]
+
+	// The old-style space-separated forms, e.g. //line a.go:1.
+	for _, tool := range [...]string{"line", "extern", "export"} {
+		if strings.HasPrefix(text, tool+" ") {
+			return Directive{
+				C:    c,
+				Tool: tool,
+				Args: strings.TrimSpace(text[len(tool)+1:]),
+			}, true
+		}
+	}
+
+	// The conventional form //tool:name args, where tool and
+	// name are nonempty and contain no spaces.
+	colon := strings.IndexByte(text, ':')
+	if colon <= 0 || colon+1 >= len(text) {
+		return Directive{}, false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := text[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return Directive{}, false
+		}
+	}
+	name := text[colon+1:]
+	args := ""
+	if i := strings.IndexAny(name, " \t"); i >= 0 {
+		name, args = name[:i], strings.TrimSpace(name[i+1:])
+	}
+	return Directive{C: c, Tool: text[:colon], Name: name, Args: args}, true
+}
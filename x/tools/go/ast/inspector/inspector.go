@@ -36,17 +36,90 @@ package inspector
 
 import (
 	"go/ast"
+	"sync"
 )
 
 // An Inspector provides methods for inspecting
 // (traversing) the syntax trees of a package.
 type Inspector struct {
 	events []event
+
+	// lazily built index of each node's parent; see Parent.
+	parentsOnce sync.Once
+	parents     map[ast.Node]ast.Node
+
+	// lazily built comment and directive index; see Comments.
+	commentsOnce sync.Once
+	comments     map[ast.Node][]*ast.CommentGroup
+	directives   []Directive
+
+	// memoized per-mask lists of matching push events; see Preorder.
+	filteredMu sync.Mutex
+	filtered   map[uint64][]int
 }
 
 // New returns an Inspector for the specified syntax trees.
+//
+// The files may be any subset of a package's files: an interactive
+// tool that re-runs cheap analyses on each keystroke may build an
+// Inspector for just the file being edited, or build one for the
+// whole package and update it with ReplaceFile.
 func New(files []*ast.File) *Inspector {
-	return &Inspector{traverse(files)}
+	return &Inspector{events: traverse(files)}
+}
+
+// ReplaceFile replaces the events of the file old with a fresh
+// traversal of the file new, which typically holds a re-parsed
+// version of the same source file. Only the replaced file is
+// re-traversed; the events of the other files are retained.
+// Lazily built indexes such as Parent and Comments are discarded and
+// rebuilt on next use.
+//
+// ReplaceFile must not be called concurrently with any traversal.
+// It panics if old is not among the files of the Inspector.
+func (in *Inspector) ReplaceFile(old, new *ast.File) {
+	for i := 0; i < len(in.events); {
+		ev := in.events[i]
+		// Each top-level event is the push of an *ast.File whose
+		// matching pop ends the file's segment of the event list.
+		if ev.node != old {
+			i = ev.index
+			continue
+		}
+		seg := traverse([]*ast.File{new})
+		delta := len(seg) - (ev.index - i)
+		for j := range seg {
+			if seg[j].index > 0 {
+				seg[j].index += i
+			}
+		}
+		events := make([]event, 0, len(in.events)+delta)
+		events = append(events, in.events[:i]...)
+		events = append(events, seg...)
+		for _, ev := range in.events[ev.index:] {
+			if ev.index > 0 {
+				ev.index += delta
+			}
+			events = append(events, ev)
+		}
+		in.events = events
+		in.invalidate()
+		return
+	}
+	panic("inspector.ReplaceFile: file not found")
+}
+
+// invalidate discards the memoized indexes after the event list has
+// changed.
+func (in *Inspector) invalidate() {
+	in.parentsOnce = sync.Once{}
+	in.parents = nil
+	in.commentsOnce = sync.Once{}
+	in.comments = nil
+	in.directives = nil
+	in.filteredMu.Lock()
+	in.filtered = nil
+	in.filteredMu.Unlock()
 }
 
 // An event represents a push or a pop
@@ -69,14 +142,70 @@ func (in *Inspector) Preorder(types []ast.Node, f func(ast.Node)) {
 	// check, Preorder is almost twice as fast as Nodes. The two
 	// features seem to contribute similar slowdowns (~1.4x each).
 
+	// The list of events matching each distinct type mask is
+	// memoized, so that when several analyzers sharing one
+	// Inspector (as in a multichecker) filter by the same types,
+	// the event list is scanned in full only once per mask.
 	mask := maskOf(types)
-	for i := 0; i < len(in.events); {
-		ev := in.events[i]
-		if ev.typ&mask != 0 {
+	if mask == 1<<64-1 {
+		// Unfiltered traversal: a memoized index would be as
+		// large as the event list itself, so scan directly.
+		for i := 0; i < len(in.events); i++ {
+			ev := in.events[i]
 			if ev.index > 0 {
 				f(ev.node)
 			}
 		}
+		return
+	}
+	for _, i := range in.pushEvents(mask) {
+		f(in.events[i].node)
+	}
+}
+
+// pushEvents returns the indexes of the push events matching the
+// given type mask, computing and memoizing them on first use.
+func (in *Inspector) pushEvents(mask uint64) []int {
+	in.filteredMu.Lock()
+	indexes, ok := in.filtered[mask]
+	if !ok {
+		for i := 0; i < len(in.events); i++ {
+			ev := in.events[i]
+			if ev.typ&mask != 0 && ev.index > 0 {
+				indexes = append(indexes, i)
+			}
+		}
+		if in.filtered == nil {
+			in.filtered = make(map[uint64][]int)
+		}
+		in.filtered[mask] = indexes
+	}
+	in.filteredMu.Unlock()
+	return indexes
+}
+
+// PreorderSkip visits nodes like Preorder, but f reports whether the
+// traversal should descend into the children of n. Returning false
+// skips the node's entire subtree at the cost of a single jump in
+// the event list, so analyzers that prune large irrelevant subtrees
+// (generated files, function bodies they don't care about) avoid
+// visiting their nodes altogether.
+//
+// Unlike Nodes, there are no postorder calls to f, which makes
+// PreorderSkip cheaper when pruning is all that is needed.
+//
+// Note that pruning decisions can be made only at nodes matching the
+// types filter; an unmatched node's children are always visited.
+func (in *Inspector) PreorderSkip(types []ast.Node, f func(n ast.Node) (descend bool)) {
+	mask := maskOf(types)
+	for i := 0; i < len(in.events); {
+		ev := in.events[i]
+		if ev.index > 0 && ev.typ&mask != 0 {
+			if !f(ev.node) {
+				i = ev.index // jump to corresponding pop + 1
+				continue
+			}
+		}
 		i++
 	}
 }
@@ -140,6 +269,92 @@ func (in *Inspector) WithStack(types []ast.Node, f func(n ast.Node, push bool, s
 	}
 }
 
+// Parent returns the node enclosing n, or false if n is not among
+// the nodes of the files supplied to New. The parent of an *ast.File
+// is nil.
+//
+// Parent allows a node discovered outside a WithStack traversal (for
+// example from a types.Info position) to be related back to its
+// context without a second walk of the syntax trees. The first call
+// builds an index of all nodes in a single scan of the event list;
+// subsequent calls are constant-time map lookups.
+func (in *Inspector) Parent(n ast.Node) (ast.Node, bool) {
+	in.parentsOnce.Do(in.buildParents)
+	parent, ok := in.parents[n]
+	return parent, ok
+}
+
+// Path returns the stack of nodes enclosing n: the same slice that a
+// WithStack traversal would supply when visiting n, with the
+// outermost node (an *ast.File) first and n itself last.
+// It returns nil if n is not among the nodes of the files supplied
+// to New.
+func (in *Inspector) Path(n ast.Node) []ast.Node {
+	in.parentsOnce.Do(in.buildParents)
+	if _, ok := in.parents[n]; !ok {
+		return nil
+	}
+	var path []ast.Node
+	for ; n != nil; n = in.parents[n] {
+		path = append(path, n)
+	}
+	// Reverse into outermost-first order.
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func (in *Inspector) buildParents() {
+	in.parents = make(map[ast.Node]ast.Node)
+	var stack []ast.Node
+	for _, ev := range in.events {
+		if ev.index > 0 {
+			// push
+			if len(stack) > 0 {
+				in.parents[ev.node] = stack[len(stack)-1]
+			} else {
+				in.parents[ev.node] = nil
+			}
+			stack = append(stack, ev.node)
+		} else {
+			// pop
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// SplitByFile returns one Inspector per file supplied to New, in the
+// same order, each built from a copy of the corresponding portion of
+// the receiver's pre-built event list; no re-traversal of the syntax
+// trees is performed.
+//
+// Traversals of distinct returned inspectors are independent, so a
+// CPU-bound analyzer can run them on separate goroutines to scale
+// across cores on packages with many files.
+func (in *Inspector) SplitByFile() []*Inspector {
+	var result []*Inspector
+	for i := 0; i < len(in.events); {
+		ev := in.events[i]
+		// Each top-level event is the push of an *ast.File whose
+		// matching pop ends the file's segment of the event list.
+		//
+		// The segment must be copied so that the push events'
+		// indexes, which are offsets into the receiver's event list,
+		// can be rebased to the start of the file.
+		events := make([]event, ev.index-i)
+		copy(events, in.events[i:ev.index])
+		for j := range events {
+			if events[j].index > 0 {
+				events[j].index -= i
+			}
+		}
+		result = append(result, &Inspector{events: events})
+		i = ev.index
+	}
+	return result
+}
+
 // traverse builds the table of events representing a traversal.
 func traverse(files []*ast.File) []event {
 	// Preallocate approximate number of events
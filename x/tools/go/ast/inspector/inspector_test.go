@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"golang.org/x/tools/go/ast/inspector"
@@ -93,6 +94,289 @@ func TestInspectPruning(t *testing.T) {
 	compare(t, nodesA, nodesB)
 }
 
+// TestPreorderSkip compares PreorderSkip against ast.Inspect,
+// pruning descent within ast.CallExpr nodes.
+func TestInspectPreorderSkip(t *testing.T) {
+	inspect := inspector.New(netFiles)
+
+	var nodesA []ast.Node
+	inspect.PreorderSkip(nil, func(n ast.Node) bool {
+		nodesA = append(nodesA, n)
+		_, isCall := n.(*ast.CallExpr)
+		return !isCall // don't descend into function calls
+	})
+	var nodesB []ast.Node
+	for _, f := range netFiles {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if n != nil {
+				nodesB = append(nodesB, n)
+				_, isCall := n.(*ast.CallExpr)
+				return !isCall // don't descend into function calls
+			}
+			return false
+		})
+	}
+	compare(t, nodesA, nodesB)
+}
+
+// TestSplitByFile checks that per-file traversals, run concurrently,
+// together visit the same nodes as one traversal of the whole package.
+func TestSplitByFile(t *testing.T) {
+	inspect := inspector.New(netFiles)
+
+	parts := inspect.SplitByFile()
+	if len(parts) != len(netFiles) {
+		t.Fatalf("SplitByFile returned %d inspectors for %d files", len(parts), len(netFiles))
+	}
+
+	nodesA := make([][]ast.Node, len(parts))
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Add(1)
+		go func(i int, part *inspector.Inspector) {
+			defer wg.Done()
+			part.Preorder(nil, func(n ast.Node) {
+				nodesA[i] = append(nodesA[i], n)
+			})
+		}(i, part)
+	}
+	wg.Wait()
+
+	var flat []ast.Node
+	for _, nodes := range nodesA {
+		flat = append(flat, nodes...)
+	}
+	var nodesB []ast.Node
+	inspect.Preorder(nil, func(n ast.Node) {
+		nodesB = append(nodesB, n)
+	})
+	compare(t, flat, nodesB)
+
+	// Nodes and WithStack follow the push events' pop indexes when
+	// pruning, which must be rebased to each file's own event list.
+	// Check that a pruning traversal of the sub-inspectors visits the
+	// same nodes as one of the whole package.
+	types := []ast.Node{(*ast.CallExpr)(nil), (*ast.FuncDecl)(nil)}
+	pruned := func(visit func(types []ast.Node, f func(n ast.Node, push bool) bool)) (nodes []ast.Node) {
+		visit(types, func(n ast.Node, push bool) bool {
+			if push {
+				nodes = append(nodes, n)
+			}
+			_, isCall := n.(*ast.CallExpr)
+			return !isCall // prune below calls
+		})
+		return nodes
+	}
+	var flatNodes []ast.Node
+	for _, part := range parts {
+		flatNodes = append(flatNodes, pruned(part.Nodes)...)
+	}
+	compare(t, flatNodes, pruned(inspect.Nodes))
+
+	var flatStacks []ast.Node
+	for _, part := range parts {
+		part.WithStack(types, func(n ast.Node, push bool, stack []ast.Node) bool {
+			if push {
+				if _, ok := stack[0].(*ast.File); !ok {
+					t.Errorf("stack[0] = %T, want *ast.File", stack[0])
+				}
+				flatStacks = append(flatStacks, n)
+			}
+			_, isCall := n.(*ast.CallExpr)
+			return !isCall
+		})
+	}
+	compare(t, flatStacks, pruned(inspect.Nodes))
+}
+
+// TestPath compares Parent and Path lookups against the stacks
+// observed during a WithStack traversal.
+func TestPath(t *testing.T) {
+	inspect := inspector.New(netFiles)
+
+	inspect.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return false
+		}
+		path := inspect.Path(n)
+		compare(t, path, stack)
+
+		parent, ok := inspect.Parent(n)
+		if !ok {
+			t.Errorf("Parent(%v) not found", n)
+		} else if want := stack[len(stack)-2]; parent != want {
+			t.Errorf("Parent(%v) = %v, want %v", n, parent, want)
+		}
+		return false
+	})
+
+	// Unknown nodes are reported as such.
+	unknown := &ast.BadExpr{}
+	if path := inspect.Path(unknown); path != nil {
+		t.Errorf("Path(unknown) = %v, want nil", path)
+	}
+	if _, ok := inspect.Parent(unknown); ok {
+		t.Errorf("Parent(unknown) succeeded unexpectedly")
+	}
+}
+
+// TestForEach compares the typed ForEach traversal against an
+// equivalent filtered Preorder traversal.
+func TestForEach(t *testing.T) {
+	inspect := inspector.New(netFiles)
+
+	var nodesA []ast.Node
+	inspector.ForEach(inspect, func(call *ast.CallExpr) {
+		nodesA = append(nodesA, call)
+	})
+	var nodesB []ast.Node
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		nodesB = append(nodesB, n)
+	})
+	compare(t, nodesA, nodesB)
+
+	// Instantiation with an interface type visits all implementations.
+	var exprsA []ast.Node
+	inspector.ForEach(inspect, func(e ast.Expr) {
+		exprsA = append(exprsA, e)
+	})
+	var exprsB []ast.Node
+	inspect.Preorder(nil, func(n ast.Node) {
+		if e, ok := n.(ast.Expr); ok {
+			exprsB = append(exprsB, e)
+		}
+	})
+	compare(t, exprsA, exprsB)
+}
+
+// TestReplaceFile checks that an Inspector updated by ReplaceFile is
+// equivalent to one built from scratch over the new set of files.
+func TestReplaceFile(t *testing.T) {
+	const src1 = `package a
+func f() { print("hi") }
+`
+	const src2 = `package a
+var v int
+func g(x int) int { return x + 1 }
+`
+	fset := token.NewFileSet()
+	parse := func(name, src string) *ast.File {
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f1 := parse("a1.go", src1)
+	f2 := parse("a2.go", `package a
+func old() {}
+`)
+	inspect := inspector.New([]*ast.File{f1, f2})
+
+	// Populate the memoized indexes before the update,
+	// to check that they are discarded.
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(ast.Node) {})
+	if _, ok := inspect.Parent(f2); !ok {
+		t.Fatalf("Parent(f2) not found before ReplaceFile")
+	}
+
+	// Replace the second file with a new version.
+	f2new := parse("a2.go", src2)
+	inspect.ReplaceFile(f2, f2new)
+
+	// All traversals must now match a freshly built Inspector.
+	fresh := inspector.New([]*ast.File{f1, f2new})
+	for _, types := range [][]ast.Node{
+		nil,
+		{(*ast.FuncDecl)(nil)},
+		{(*ast.Ident)(nil), (*ast.BasicLit)(nil)},
+	} {
+		var nodesA, nodesB []ast.Node
+		inspect.Preorder(types, func(n ast.Node) {
+			nodesA = append(nodesA, n)
+		})
+		fresh.Preorder(types, func(n ast.Node) {
+			nodesB = append(nodesB, n)
+		})
+		compare(t, nodesA, nodesB)
+	}
+
+	// The parent index covers the new file and has
+	// forgotten the old one.
+	inspect.Preorder([]ast.Node{(*ast.ReturnStmt)(nil)}, func(n ast.Node) {
+		if path := inspect.Path(n); len(path) == 0 || path[0] != f2new {
+			t.Errorf("Path(%v) = %v, want path rooted at new file", n, path)
+		}
+	})
+	if _, ok := inspect.Parent(f2); ok {
+		t.Errorf("Parent(old file) succeeded after ReplaceFile")
+	}
+
+	// Replacing an unknown file panics.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("ReplaceFile(unknown) did not panic")
+			}
+		}()
+		inspect.ReplaceFile(f2, f2new) // f2 no longer present
+	}()
+}
+
+// TestComments checks the package-level comment and directive index.
+func TestComments(t *testing.T) {
+	const src = `// Package a does things.
+package a
+
+//go:generate stringer -type=T
+type T int
+
+func f() int {
+	x := 1 //nolint:shadow
+	return x
+}
+
+//line gen.go:10
+var v int
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inspect := inspector.New([]*ast.File{f})
+
+	// The doc comment of T is associated with its declaration.
+	var typeDecl ast.Node
+	inspect.Preorder([]ast.Node{(*ast.GenDecl)(nil)}, func(n ast.Node) {
+		if n.(*ast.GenDecl).Tok == token.TYPE {
+			typeDecl = n
+		}
+	})
+	groups := inspect.Comments(fset, typeDecl)
+	if len(groups) != 1 || groups[0].List[0].Text != "//go:generate stringer -type=T" {
+		t.Errorf("Comments(type decl) = %v, want the //go:generate group", groups)
+	}
+
+	// The directives appear in order, parsed into their parts.
+	type parts struct{ tool, name, args string }
+	var got []parts
+	for _, d := range inspect.Directives(fset) {
+		got = append(got, parts{d.Tool, d.Name, d.Args})
+		if d.Node == nil {
+			t.Errorf("directive %s has no associated node", d.C.Text)
+		}
+	}
+	want := []parts{
+		{"go", "generate", "stringer -type=T"},
+		{"nolint", "shadow", ""},
+		{"line", "", "gen.go:10"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Directives = %+v, want %+v", got, want)
+	}
+}
+
 func compare(t *testing.T, nodesA, nodesB []ast.Node) {
 	if len(nodesA) != len(nodesB) {
 		t.Errorf("inconsistent node lists: %d vs %d", len(nodesA), len(nodesB))
@@ -176,6 +460,25 @@ func typeOf(n ast.Node) string {
 // BenchmarkNewInspect	   0.33ms
 // BenchmarkASTInspect    1.2  ms
 
+// BenchmarkPreorderRepeated measures repeated filtered traversals of
+// one Inspector, as in a multichecker whose analyzers request the
+// same node types: after the first traversal, the memoized event
+// index makes each traversal proportional to the number of matching
+// nodes, not the total number of events.
+func BenchmarkPreorderRepeated(b *testing.B) {
+	b.StopTimer()
+	inspect := inspector.New(netFiles)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil)}
+	b.StartTimer()
+
+	var ndecls int
+	for i := 0; i < b.N; i++ {
+		inspect.Preorder(nodeFilter, func(n ast.Node) {
+			ndecls++
+		})
+	}
+}
+
 func BenchmarkNewInspector(b *testing.B) {
 	// Measure one-time construction overhead.
 	for i := 0; i < b.N; i++ {
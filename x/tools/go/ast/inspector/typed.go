@@ -0,0 +1,30 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inspector
+
+import "go/ast"
+
+// ForEach calls f for every node of type N in the files supplied to
+// New, in depth-first preorder. It expresses the common Preorder
+// pattern without the []ast.Node filter and type-assertion
+// boilerplate:
+//
+//	inspector.ForEach(in, func(call *ast.CallExpr) { ... })
+//
+// N is typically a pointer-to-struct node type such as *ast.CallExpr.
+// If N is an interface type, every node implementing it is visited;
+// this is slower, as the event-list type filter cannot be used.
+func ForEach[N ast.Node](in *Inspector, f func(N)) {
+	var typ N
+	types := []ast.Node{typ}
+	if ast.Node(typ) == nil {
+		types = nil // N is an interface type; scan all nodes
+	}
+	in.Preorder(types, func(n ast.Node) {
+		if n, ok := n.(N); ok {
+			f(n)
+		}
+	})
+}
@@ -418,6 +418,7 @@ type jsonPackage struct {
 	XTestImports    []string
 	ForTest         string // q in a "p [q.test]" package, else ""
 	DepOnly         bool
+	Module          *Module
 
 	Error *jsonPackageError
 }
@@ -481,6 +482,7 @@ func golistDriverCurrent(cfg *Config, words ...string) (*driverResponse, error)
 			GoFiles:         absJoin(p.Dir, p.GoFiles, p.CgoFiles),
 			CompiledGoFiles: absJoin(p.Dir, p.CompiledGoFiles),
 			OtherFiles:      absJoin(p.Dir, otherFiles(p)...),
+			Module:          p.Module,
 		}
 
 		// Extract the PkgPath from the package's ID.
@@ -222,6 +222,10 @@ type Package struct {
 	// information for the package as provided by the build system.
 	ExportFile string
 
+	// Module is the module information for the package if it exists,
+	// or nil otherwise (for instance in GOPATH mode).
+	Module *Module
+
 	// Imports maps import paths appearing in the package's Go source files
 	// to corresponding loaded Packages.
 	Imports map[string]*Package
@@ -240,6 +244,13 @@ type Package struct {
 	// It is set only when Types is set.
 	IllTyped bool
 
+	// TypeErrors contains the subset of errors produced by the
+	// type checker, in the order in which they were encountered,
+	// retaining their full position and detail. (The same errors
+	// appear in Errors, flattened to strings.)
+	// It is set only when the package is loaded from source.
+	TypeErrors []types.Error
+
 	// Syntax is the package's syntax trees, for the files listed in CompiledGoFiles.
 	//
 	// Mode LoadSyntax sets this field for packages matching the patterns.
@@ -249,6 +260,16 @@ type Package struct {
 	// TypesInfo provides type information about the package's syntax trees.
 	// It is set only when Syntax is set.
 	TypesInfo *types.Info
+
+	// TypesSizes provides the effective size function for types in TypesInfo.
+	TypesSizes types.Sizes
+}
+
+// Module provides module information for a package.
+type Module struct {
+	Path    string // module path
+	Version string // module version, or "" for the main module
+	Main    bool   // is this the main module?
 }
 
 // An Error describes a problem with a package's metadata, syntax, or types.
@@ -621,6 +642,7 @@ func (ld *loader) loadPackage(lpkg *loaderPackage) {
 
 		case types.Error:
 			// from type checker
+			lpkg.TypeErrors = append(lpkg.TypeErrors, err)
 			errs = append(errs, Error{
 				Pos:  err.Fset.Position(err.Pos).String(),
 				Msg:  err.Msg,
@@ -693,6 +715,7 @@ func (ld *loader) loadPackage(lpkg *loaderPackage) {
 		}
 	}
 	sizes := types.SizesFor("gc", goarch)
+	lpkg.TypesSizes = sizes
 
 	// type-check
 	tc := &types.Config{